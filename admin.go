@@ -0,0 +1,202 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Number of errors kept for the admin /errors endpoint.
+const recentErrorsSize = 50
+
+// RecentError is an internal error reported by the client, as shown by the admin /errors endpoint.
+type RecentError struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+	Error   string    `json:"error"`
+}
+
+// errorLog is a fixed size ring buffer of recent errors.
+type errorLog struct {
+	mu     sync.Mutex
+	errors []RecentError
+	next   int
+}
+
+func (l *errorLog) add(e RecentError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.errors) < recentErrorsSize {
+		l.errors = append(l.errors, e)
+		return
+	}
+	l.errors[l.next] = e
+	l.next = (l.next + 1) % recentErrorsSize
+}
+
+// list returns the errors, oldest first.
+func (l *errorLog) list() []RecentError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	errors := make([]RecentError, 0, len(l.errors))
+	errors = append(errors, l.errors[l.next:]...)
+	errors = append(errors, l.errors[:l.next]...)
+	return errors
+}
+
+// pendingSet tracks subscriptions that AddSubscription is waiting to be verified.
+type pendingSet struct {
+	mu      sync.Mutex
+	pending map[string]PendingVerification
+}
+
+func (p *pendingSet) add(sub Subscription) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending == nil {
+		p.pending = make(map[string]PendingVerification)
+	}
+	p.pending[sub.ID] = PendingVerification{
+		SubscriptionID: sub.ID,
+		Type:           sub.Type,
+		Callback:       sub.Transport.Callback,
+		Since:          time.Now(),
+	}
+}
+
+func (p *pendingSet) remove(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, id)
+}
+
+func (p *pendingSet) snapshot() map[string]time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	snapshot := make(map[string]time.Time, len(p.pending))
+	for id, pending := range p.pending {
+		snapshot[id] = pending.Since
+	}
+	return snapshot
+}
+
+// PendingVerification is a subscription AddSubscription is waiting for Twitch to verify.
+type PendingVerification struct {
+	SubscriptionID string `json:"subscription_id"`
+	Type           string `json:"type"`
+	// Callback URL Twitch sends the challenge to.
+	Callback string    `json:"callback"`
+	Since    time.Time `json:"since"`
+	// Time since the subscription was created.
+	Pending time.Duration `json:"pending"`
+}
+
+// PendingVerifications returns the subscriptions AddSubscription is currently waiting to be verified, longest pending
+// first. A subscription stays in the list until its challenge arrives or AddSubscription times out, so a subscription
+// pending for several seconds means Twitch could not reach the callback.
+func (c *Client) PendingVerifications() []PendingVerification {
+	c.pending.mu.Lock()
+	pending := make([]PendingVerification, 0, len(c.pending.pending))
+	for _, p := range c.pending.pending {
+		p.Pending = time.Since(p.Since)
+		pending = append(pending, p)
+	}
+	c.pending.mu.Unlock()
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Since.Before(pending[j].Since)
+	})
+	return pending
+}
+
+// dedupStats counts deduplication hits for the admin /dedup endpoint.
+type dedupStats struct {
+	duplicates atomic.Int64
+}
+
+// reportError logs an internal error, keeps it for the admin /errors endpoint, and passes it to the ErrorHandler.
+func (c *Client) reportError(message string, err error, attrs ...any) {
+	c.logger.Error(message, append(attrs, "error", err)...)
+	c.recentErrors.add(RecentError{
+		Time:    time.Now(),
+		Message: message,
+		Error:   err.Error(),
+	})
+	if c.errorHandler != nil {
+		c.errorHandler(&InternalError{message, err})
+	}
+}
+
+// AdminHandler returns an HTTP handler exposing JSON views of the client's state, useful when debugging missing events:
+//
+//   - /subscriptions: all subscriptions, fetched from Helix
+//   - /pending: subscription IDs AddSubscription is waiting to be verified, and since when
+//   - /handlers: event types with a handler registered with On, OnBatch, or by a Tenant
+//   - /errors: the most recent internal errors
+//   - /dedup: deduplication statistics
+//
+// The handler is not authenticated. Serve it on a separate, private port or behind your own authentication.
+//
+//	http.Handle("/debug/twitchwh/", http.StripPrefix("/debug/twitchwh", client.AdminHandler()))
+func (c *Client) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		subscriptions, err := c.GetSubscriptions()
+		if err != nil {
+			writeAdminJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, subscriptions)
+	})
+	mux.HandleFunc("GET /pending", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, c.pending.snapshot())
+	})
+	mux.HandleFunc("GET /handlers", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, c.handledEventTypes())
+	})
+	mux.HandleFunc("GET /errors", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, c.recentErrors.list())
+	})
+	mux.HandleFunc("GET /dedup", func(w http.ResponseWriter, r *http.Request) {
+		stats := map[string]any{
+			"duplicates": c.dedup.duplicates.Load(),
+		}
+		if counter, ok := c.handledEventsChecker.(interface{ Len() int }); ok {
+			stats["handled_events"] = counter.Len()
+		}
+		writeAdminJSON(w, http.StatusOK, stats)
+	})
+	return mux
+}
+
+// handledEventTypes returns the sorted event types with a handler, batch handler (see OnBatch), or tenant handler.
+func (c *Client) handledEventTypes() []string {
+	c.handlersMu.RLock()
+	seen := make(map[string]bool, len(c.handlers)+len(c.batchers))
+	for eventType := range c.handlers {
+		seen[eventType] = true
+	}
+	for eventType := range c.batchers {
+		seen[eventType] = true
+	}
+	for _, handlers := range c.tenantHandlers {
+		for eventType := range handlers {
+			seen[eventType] = true
+		}
+	}
+	c.handlersMu.RUnlock()
+	types := make([]string, 0, len(seen))
+	for eventType := range seen {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
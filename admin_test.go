@@ -0,0 +1,109 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// getAdmin requests an admin endpoint and decodes its JSON response into v.
+func getAdmin(t *testing.T, c *Client, path string, v any) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c.AdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Expected a JSON response from %s, got %q", path, w.Header().Get("Content-Type"))
+	}
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code
+}
+
+func TestAdminSubscriptions(t *testing.T) {
+	c := newClient(ClientConfig{})
+	helixDown := false
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if helixDown {
+			return jsonResponse(500, ""), nil
+		}
+		return jsonResponse(200, `{"data":[{"id":"1","type":"stream.online","version":"1","status":"enabled"}],"pagination":{}}`), nil
+	})}
+
+	var subscriptions []Subscription
+	if status := getAdmin(t, c, "/subscriptions", &subscriptions); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if len(subscriptions) != 1 || subscriptions[0].ID != "1" {
+		t.Fatalf("Expected subscription 1, got %+v", subscriptions)
+	}
+
+	helixDown = true
+	var body map[string]string
+	if status := getAdmin(t, c, "/subscriptions", &body); status != http.StatusBadGateway || body["error"] == "" {
+		t.Fatalf("Expected status 502 with an error, got %d %v", status, body)
+	}
+}
+
+func TestAdminPending(t *testing.T) {
+	c := newClient(ClientConfig{})
+	c.pending.add(Subscription{ID: "1", Type: "stream.online"})
+
+	var pending map[string]time.Time
+	if status := getAdmin(t, c, "/pending", &pending); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if _, ok := pending["1"]; !ok || len(pending) != 1 {
+		t.Fatalf("Expected subscription 1 to be pending, got %v", pending)
+	}
+}
+
+func TestAdminHandlers(t *testing.T) {
+	c := newClient(ClientConfig{})
+	c.On("stream.online", func(event json.RawMessage) {})
+	c.OnBatch("channel.chat.message", 10, time.Second, func(batch []Notification) {})
+	c.Tenant("1971641").On("stream.offline", func(event json.RawMessage) {})
+	c.Tenant("2914196").On("stream.online", func(event json.RawMessage) {})
+
+	var types []string
+	if status := getAdmin(t, c, "/handlers", &types); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if strings.Join(types, ",") != "channel.chat.message,stream.offline,stream.online" {
+		t.Fatalf("Expected the client, batch, and tenant handlers, got %v", types)
+	}
+}
+
+func TestAdminErrors(t *testing.T) {
+	c := newClient(ClientConfig{})
+	for i := 0; i < recentErrorsSize+1; i++ {
+		c.reportError("Could not do something", errors.New("failed"))
+	}
+
+	var recent []RecentError
+	if status := getAdmin(t, c, "/errors", &recent); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if len(recent) != recentErrorsSize || recent[0].Message != "Could not do something" || recent[0].Error != "failed" {
+		t.Fatalf("Expected the %d most recent errors, got %d: %+v", recentErrorsSize, len(recent), recent[0])
+	}
+}
+
+func TestAdminDedup(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	for _, id := range []string{"a", "a", "b"} {
+		c.Handler(httptest.NewRecorder(), signedRequest(id, messageTypeNotification, chatMessageBody))
+	}
+
+	var stats map[string]int
+	if status := getAdmin(t, c, "/dedup", &stats); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if stats["duplicates"] != 1 || stats["handled_events"] != 2 {
+		t.Fatalf("Expected 1 duplicate and 2 handled events, got %v", stats)
+	}
+}
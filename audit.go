@@ -0,0 +1,192 @@
+package twitchwh
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditAction describes a change in a subscription's lifecycle.
+type AuditAction string
+
+const (
+	// Helix accepted the subscription request.
+	AuditActionCreated AuditAction = "created"
+	// Twitch sent the verification challenge and it was answered.
+	AuditActionVerified AuditAction = "verified"
+	// The verification challenge was not received in time.
+	AuditActionVerificationFailed AuditAction = "verification_failed"
+	// Twitch revoked the subscription. The reason is the subscription status.
+	AuditActionRevoked AuditAction = "revoked"
+	// The subscription was deleted by this client.
+	AuditActionDeleted AuditAction = "deleted"
+)
+
+// AuditEntry is a single record in a subscription's history.
+type AuditEntry struct {
+	SubscriptionID string
+	// Subscription type, eg: "stream.online". May be empty if it was not known at the time.
+	Type   string
+	Action AuditAction
+	// Human readable reason, for revocations this is the status sent by Twitch (eg: "user_removed").
+	Reason string
+	Time   time.Time
+	// Address of the request that caused the change, for verifications and revocations. Empty for changes made by
+	// this client. See ClientConfig.TrustedProxies.
+	Source string
+	// Client-ID of the client that made the change. Empty unless the change was made by this client (created or
+	// deleted), like Caller and RequestID.
+	ClientID string
+	// The first function outside this package that led to the change, eg: "main.syncSubscriptions (/app/main.go:42)".
+	Caller string
+	// The request ID Helix returned in the X-Request-Id header, if any.
+	RequestID string
+}
+
+// AuditStore records subscription lifecycle changes.
+// Implement this to persist history to a database, the default MemoryAuditStore is lost on restart.
+type AuditStore interface {
+	Record(entry AuditEntry) error
+	// History returns all entries for a subscription, oldest first.
+	History(subscriptionID string) ([]AuditEntry, error)
+}
+
+// Number of entries kept by a MemoryAuditStore.
+const memoryAuditStoreSize = 10000
+
+// MemoryAuditStore is an in-memory AuditStore. It is the default if ClientConfig.AuditStore is nil.
+// It is a fixed size ring buffer, only the most recent 10,000 entries across all subscriptions are kept.
+type MemoryAuditStore struct {
+	mu      sync.RWMutex
+	entries []AuditEntry
+	next    int
+}
+
+func NewMemoryAuditStore() *MemoryAuditStore {
+	return &MemoryAuditStore{}
+}
+
+func (m *MemoryAuditStore) Record(entry AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.entries) < memoryAuditStoreSize {
+		m.entries = append(m.entries, entry)
+		return nil
+	}
+	m.entries[m.next] = entry
+	m.next = (m.next + 1) % memoryAuditStoreSize
+	return nil
+}
+
+func (m *MemoryAuditStore) History(subscriptionID string) ([]AuditEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	history := []AuditEntry{}
+	for _, entries := range [][]AuditEntry{m.entries[m.next:], m.entries[:m.next]} {
+		for _, entry := range entries {
+			if entry.SubscriptionID == subscriptionID {
+				history = append(history, entry)
+			}
+		}
+	}
+	return history, nil
+}
+
+// History returns the recorded lifecycle of a subscription (created, verified, revoked, deleted), oldest first.
+// Only changes observed by this client, or stored in a shared AuditStore, are included.
+func (c *Client) History(subscriptionID string) ([]AuditEntry, error) {
+	return c.auditStore.History(subscriptionID)
+}
+
+// audit records a lifecycle change, errors from the store are logged and otherwise ignored.
+func (c *Client) audit(action AuditAction, sub Subscription, reason string) {
+	c.auditRequest(action, sub, reason, "")
+}
+
+// auditRequest records a lifecycle change caused by a request from source.
+func (c *Client) auditRequest(action AuditAction, sub Subscription, reason string, source string) {
+	c.recordAudit(AuditEntry{
+		SubscriptionID: sub.ID,
+		Type:           sub.Type,
+		Action:         action,
+		Reason:         reason,
+		Time:           time.Now(),
+		Source:         source,
+	})
+}
+
+// auditMutation records a change made by this client through Helix, and logs it, so changes to EventSub state can be
+// traced to the code that made them. ctx is the context of the Helix request, see withHelixResponse.
+func (c *Client) auditMutation(ctx context.Context, action AuditAction, sub Subscription, reason string) {
+	entry := AuditEntry{
+		SubscriptionID: sub.ID,
+		Type:           sub.Type,
+		Action:         action,
+		Reason:         reason,
+		Time:           time.Now(),
+		ClientID:       c.clientID,
+		Caller:         externalCaller(),
+	}
+	if response := helixResponseFromContext(ctx); response != nil {
+		entry.RequestID = response.requestID
+	}
+	c.logger.Info("Subscription changed", "subscription_id", entry.SubscriptionID, "type", entry.Type, "action", entry.Action,
+		"caller", entry.Caller, "request_id", entry.RequestID)
+	c.recordAudit(entry)
+}
+
+func (c *Client) recordAudit(entry AuditEntry) {
+	if err := c.auditStore.Record(entry); err != nil {
+		c.reportError("Could not record audit entry", err, "subscription_id", entry.SubscriptionID, "action", entry.Action)
+	}
+}
+
+// externalCaller returns the first function on the stack outside this package (or in its tests), eg: the application
+// code calling AddSubscription. Empty if there is none, eg: for changes made by a background job of the client.
+func externalCaller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, packagePath+".") && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal && !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// packagePath is the import path of this package, functions of sub-packages like twitchwhtest count as callers.
+const packagePath = "github.com/macluxHD/twitchwh"
+
+type helixResponseKey struct{}
+
+// helixResponse collects details of the Helix responses to requests made with a context from withHelixResponse.
+type helixResponse struct {
+	mu        sync.Mutex
+	requestID string
+}
+
+func withHelixResponse(ctx context.Context) context.Context {
+	return context.WithValue(ctx, helixResponseKey{}, &helixResponse{})
+}
+
+func helixResponseFromContext(ctx context.Context) *helixResponse {
+	response, _ := ctx.Value(helixResponseKey{}).(*helixResponse)
+	return response
+}
+
+// record stores the details of a response.
+func (r *helixResponse) record(header http.Header) {
+	if id := header.Get("X-Request-Id"); id != "" {
+		r.mu.Lock()
+		r.requestID = id
+		r.mu.Unlock()
+	}
+}
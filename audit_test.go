@@ -0,0 +1,62 @@
+package twitchwh
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAuditMutation(t *testing.T) {
+	c := newClient(ClientConfig{ClientID: "client", Mode: ModeAPI})
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodDelete {
+			return jsonResponse(204, ""), nil
+		}
+		res := jsonResponse(202, `{"data":[{"id":"1","type":"stream.online","version":"1","status":"webhook_callback_verification_pending"}]}`)
+		res.Header.Set("X-Request-Id", "req-1")
+		return res, nil
+	})}
+
+	if _, err := c.AddSubscription("stream.online", "1", Condition{BroadcasterUserID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemoveSubscription("1"); err != nil {
+		t.Fatal(err)
+	}
+	history, err := c.History("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Action != AuditActionCreated || history[1].Action != AuditActionDeleted {
+		t.Fatalf("Unexpected history %+v", history)
+	}
+	created := history[0]
+	if created.ClientID != "client" || created.RequestID != "req-1" || !strings.Contains(created.Caller, "TestAuditMutation") {
+		t.Fatalf("Expected client ID, request ID, and caller, got %+v", created)
+	}
+	if history[1].RequestID != "" || !strings.Contains(history[1].Caller, "audit_test.go") {
+		t.Fatalf("Unexpected deletion entry %+v", history[1])
+	}
+}
+
+func TestMemoryAuditStoreSize(t *testing.T) {
+	store := NewMemoryAuditStore()
+	store.Record(AuditEntry{SubscriptionID: "old", Action: AuditActionCreated})
+	store.Record(AuditEntry{SubscriptionID: "kept", Action: AuditActionCreated})
+	for i := 0; i < memoryAuditStoreSize-1; i++ {
+		store.Record(AuditEntry{SubscriptionID: strconv.Itoa(i), Action: AuditActionCreated})
+	}
+	store.Record(AuditEntry{SubscriptionID: "kept", Action: AuditActionDeleted})
+
+	if len(store.entries) != memoryAuditStoreSize {
+		t.Fatalf("Expected %d entries, got %d", memoryAuditStoreSize, len(store.entries))
+	}
+	if history, _ := store.History("old"); len(history) != 0 {
+		t.Fatalf("Expected the oldest entry to be dropped, got %+v", history)
+	}
+	history, _ := store.History("kept")
+	if len(history) != 1 || history[0].Action != AuditActionDeleted {
+		t.Fatalf("Expected only the newest entry of kept, got %+v", history)
+	}
+}
@@ -0,0 +1,132 @@
+package twitchwh
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchHandler handles a batch of notifications of the same event type, see Client.OnBatch.
+type BatchHandler func(batch []Notification)
+
+// batcher collects notifications until a batch is full or its window elapsed.
+type batcher struct {
+	client    *Client
+	eventType string
+	size      int
+	window    time.Duration
+	handler   BatchHandler
+
+	mu      sync.Mutex
+	pending []Notification
+	// Called with the result of the handler once the batch of pending was handled, nil for notifications that
+	// don't wait for it
+	done  []func(error)
+	timer *time.Timer
+}
+
+// OnBatch registers a handler that receives notifications of an event type in batches, eg: for bulk inserts into a
+// database. A batch is handed over once it has size notifications, or window after its first notification arrived,
+// whichever comes first. It takes precedence over handlers registered with On or OnContext for the same type.
+//
+//	client.OnBatch("channel.chat.message", 500, time.Second, func(batch []twitchwh.Notification) {
+//		db.InsertMessages(batch)
+//	})
+//
+// Notifications are acknowledged before their batch is handled, so a batch that is pending when the process crashes
+// is lost, unless ClientConfig.Outbox is set: outbox entries are only completed once their batch was handled, and
+// delivered again if the handler panicked. Shutdown hands over pending batches immediately.
+func (c *Client) OnBatch(eventType string, size int, window time.Duration, handler BatchHandler) {
+	b := &batcher{client: c, eventType: eventType, size: max(size, 1), window: window, handler: handler}
+	c.handlersMu.Lock()
+	if c.batchers == nil {
+		c.batchers = make(map[string]*batcher)
+	}
+	previous := c.batchers[eventType]
+	c.batchers[eventType] = b
+	c.handlersMu.Unlock()
+	if previous != nil {
+		previous.flush()
+	}
+}
+
+func (c *Client) batcher(eventType string) (*batcher, bool) {
+	c.handlersMu.RLock()
+	defer c.handlersMu.RUnlock()
+	b, ok := c.batchers[eventType]
+	return b, ok
+}
+
+// flushBatches hands over all pending batches, eg: when shutting down.
+func (c *Client) flushBatches() {
+	c.handlersMu.RLock()
+	batchers := make([]*batcher, 0, len(c.batchers))
+	for _, b := range c.batchers {
+		batchers = append(batchers, b)
+	}
+	c.handlersMu.RUnlock()
+	for _, b := range batchers {
+		b.flush()
+	}
+}
+
+// add adds a notification to the pending batch. done, if not nil, is called with the result of the handler once the
+// batch was handled.
+func (b *batcher) add(n Notification, done func(error)) {
+	b.mu.Lock()
+	b.pending = append(b.pending, n)
+	b.done = append(b.done, done)
+	if len(b.pending) < b.size {
+		if len(b.pending) == 1 {
+			b.timer = time.AfterFunc(b.window, b.flush)
+		}
+		b.mu.Unlock()
+		return
+	}
+	batch, callbacks := b.take()
+	b.mu.Unlock()
+	b.run(batch, callbacks)
+}
+
+func (b *batcher) flush() {
+	b.mu.Lock()
+	batch, done := b.take()
+	b.mu.Unlock()
+	if len(batch) > 0 {
+		b.run(batch, done)
+	}
+}
+
+// take removes the pending batch and its callbacks. b.mu must be held.
+func (b *batcher) take() ([]Notification, []func(error)) {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	batch, done := b.pending, b.done
+	b.pending, b.done = nil, nil
+	return batch, done
+}
+
+// run hands a batch to the handler in the background, then calls the callbacks of its notifications.
+func (b *batcher) run(batch []Notification, done []func(error)) {
+	c := b.client
+	c.runningHandlers.Add(1)
+	go func() {
+		defer c.runningHandlers.Add(-1)
+		start := time.Now()
+		var err error
+		defer func() {
+			if v := recover(); v != nil {
+				err = &HandlerPanicError{Type: b.eventType, Value: v}
+				c.reportError("Batch handler panicked", err, "type", b.eventType, "size", len(batch))
+			}
+			c.metrics.HandlerFinished(b.eventType, time.Since(start), err)
+			for _, done := range done {
+				if done != nil {
+					done(err)
+				}
+			}
+		}()
+		b.handler(batch)
+	}()
+}
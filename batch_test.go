@@ -0,0 +1,136 @@
+package twitchwh
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestOnBatch(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	batches := make(chan []Notification, 10)
+	c.OnBatch("channel.chat.message", 3, 50*time.Millisecond, func(batch []Notification) {
+		batches <- batch
+	})
+
+	for i := 0; i < 4; i++ {
+		c.Handler(httptest.NewRecorder(), signedRequest(strconv.Itoa(i), messageTypeNotification, chatMessageBody))
+	}
+	select {
+	case batch := <-batches:
+		if len(batch) != 3 || batch[0].MessageID != "0" {
+			t.Fatalf("Expected a full batch of 3, got %d", len(batch))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a full batch to be handed over")
+	}
+	// The last notification is handed over once the window elapsed
+	select {
+	case batch := <-batches:
+		if len(batch) != 1 || batch[0].MessageID != "3" {
+			t.Fatalf("Expected a batch of 1, got %d", len(batch))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the window to hand over the partial batch")
+	}
+
+	c.Handler(httptest.NewRecorder(), signedRequest("4", messageTypeNotification, chatMessageBody))
+	c.OnBatch("channel.chat.message", 3, time.Hour, func(batch []Notification) {
+		batches <- batch
+	})
+	c.Handler(httptest.NewRecorder(), signedRequest("5", messageTypeNotification, chatMessageBody))
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Replacing the handler hands over the old batch, Shutdown the new one
+	if len(batches) != 2 {
+		t.Fatalf("Expected pending batches to be handed over, got %d", len(batches))
+	}
+}
+
+func TestOnBatchSubscription(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	batches := make(chan []Notification, 1)
+	c.OnBatch("channel.chat.message", 1, time.Hour, func(batch []Notification) {
+		batches <- batch
+	})
+
+	c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+	n := (<-batches)[0]
+	if n.Subscription.Version != "1" || n.Subscription.Status != "enabled" || n.BroadcasterUserID() != "1971641" {
+		t.Fatalf("Expected the full subscription in the batch, got %+v", n.Subscription)
+	}
+}
+
+func TestOnBatchHandleBeforeResponse(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	c.SetResponsePolicy("channel.chat.message", ResponsePolicy{HandleBeforeResponse: true})
+	batches := make(chan []Notification, 1)
+	c.OnBatch("channel.chat.message", 1, time.Minute, func(batch []Notification) {
+		batches <- batch
+	})
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != 204 {
+		t.Fatalf("Expected 204, got %d", w.Code)
+	}
+	select {
+	case batch := <-batches:
+		if len(batch) != 1 || batch[0].MessageID != "a" {
+			t.Fatalf("Unexpected batch %+v", batch)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Batch handler was not called")
+	}
+}
+
+func TestOnBatchOutbox(t *testing.T) {
+	outbox := &memoryOutbox{}
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, Outbox: outbox})
+	fail := true
+	handled := make(chan struct{}, 1)
+	c.OnBatch("channel.chat.message", 10, time.Minute, func(batch []Notification) {
+		defer func() { handled <- struct{}{} }()
+		if fail {
+			panic("database down")
+		}
+	})
+	c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+
+	finished := func() (int, int) {
+		outbox.mu.Lock()
+		defer outbox.mu.Unlock()
+		return len(outbox.completed), len(outbox.retryAt)
+	}
+	c.dispatchOutbox()
+	if completed, failed := finished(); completed != 0 || failed != 0 {
+		t.Fatalf("Expected the entry to wait for its batch, got %d completed and %d failed", completed, failed)
+	}
+	c.flushBatches()
+	<-handled
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if _, failed := finished(); failed == 1 {
+			break
+		}
+	}
+	if completed, failed := finished(); completed != 0 || failed != 1 {
+		t.Fatalf("Expected the entry to fail with its batch, got %d completed and %d failed", completed, failed)
+	}
+
+	fail = false
+	outbox.mu.Lock()
+	outbox.entries[0].availableAt = time.Now()
+	outbox.mu.Unlock()
+	c.dispatchOutbox()
+	c.flushBatches()
+	<-handled
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if completed, _ := finished(); completed == 1 {
+			return
+		}
+	}
+	t.Fatal("Expected the entry to be completed once its batch was handled")
+}
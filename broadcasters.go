@@ -0,0 +1,81 @@
+package twitchwh
+
+import "sync"
+
+// Reasons passed to MetricsHook.NotificationDropped.
+const (
+	// The broadcaster is on the deny list, see Client.DenyBroadcasters.
+	DropReasonBroadcasterDenied = "broadcaster_denied"
+	// An allow list is set and the broadcaster is not on it, see Client.AllowBroadcasters.
+	DropReasonBroadcasterNotAllowed = "broadcaster_not_allowed"
+	// The notification was dropped by the sample rate of its type, see Client.SetSampleRate.
+	DropReasonSampled = "sampled"
+)
+
+// broadcasterFilter holds the broadcaster allow and deny lists.
+type broadcasterFilter struct {
+	mu      sync.RWMutex
+	allowed map[string]struct{}
+	denied  map[string]struct{}
+}
+
+// AllowBroadcasters restricts handlers and sinks to notifications of the given broadcasters, see
+// Notification.BroadcasterUserID. Other notifications are acknowledged and dropped. Calling it without IDs removes
+// the allow list. Replaces the previous allow list.
+func (c *Client) AllowBroadcasters(ids ...string) {
+	c.broadcasters.mu.Lock()
+	defer c.broadcasters.mu.Unlock()
+	c.broadcasters.allowed = nil
+	if len(ids) > 0 {
+		c.broadcasters.allowed = make(map[string]struct{}, len(ids))
+		for _, id := range ids {
+			c.broadcasters.allowed[id] = struct{}{}
+		}
+	}
+	c.broadcasterFilterSet.Store(len(c.broadcasters.allowed) > 0 || len(c.broadcasters.denied) > 0)
+}
+
+// DenyBroadcasters drops notifications of the given broadcasters before they reach handlers and sinks, eg: for
+// channels under migration or abuse, without deleting their subscriptions. See AllowBroadcasters.
+func (c *Client) DenyBroadcasters(ids ...string) {
+	c.broadcasters.mu.Lock()
+	defer c.broadcasters.mu.Unlock()
+	if c.broadcasters.denied == nil {
+		c.broadcasters.denied = make(map[string]struct{})
+	}
+	for _, id := range ids {
+		c.broadcasters.denied[id] = struct{}{}
+	}
+	c.broadcasterFilterSet.Store(len(c.broadcasters.allowed) > 0 || len(c.broadcasters.denied) > 0)
+}
+
+// UndenyBroadcasters removes broadcasters from the deny list.
+func (c *Client) UndenyBroadcasters(ids ...string) {
+	c.broadcasters.mu.Lock()
+	defer c.broadcasters.mu.Unlock()
+	for _, id := range ids {
+		delete(c.broadcasters.denied, id)
+	}
+	c.broadcasterFilterSet.Store(len(c.broadcasters.allowed) > 0 || len(c.broadcasters.denied) > 0)
+}
+
+// broadcasterDropReason returns why a notification is dropped by the broadcaster lists, or an empty string if it
+// isn't. Notifications without a broadcaster are never dropped.
+func (c *Client) broadcasterDropReason(n Notification) string {
+	if !c.broadcasterFilterSet.Load() {
+		return ""
+	}
+	id := n.BroadcasterUserID()
+	if id == "" {
+		return ""
+	}
+	c.broadcasters.mu.RLock()
+	defer c.broadcasters.mu.RUnlock()
+	if _, denied := c.broadcasters.denied[id]; denied {
+		return DropReasonBroadcasterDenied
+	}
+	if _, allowed := c.broadcasters.allowed[id]; c.broadcasters.allowed != nil && !allowed {
+		return DropReasonBroadcasterNotAllowed
+	}
+	return ""
+}
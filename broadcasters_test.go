@@ -0,0 +1,70 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBroadcasterLists(t *testing.T) {
+	c := newClient(ClientConfig{})
+	n := func(id string) Notification {
+		return Notification{Subscription: Subscription{Condition: Condition{BroadcasterUserID: id}}}
+	}
+
+	if reason := c.broadcasterDropReason(n("1")); reason != "" {
+		t.Fatalf("Expected no filtering by default, got %q", reason)
+	}
+	c.DenyBroadcasters("1")
+	if reason := c.broadcasterDropReason(n("1")); reason != DropReasonBroadcasterDenied {
+		t.Fatalf("Expected denied broadcaster to be dropped, got %q", reason)
+	}
+	c.AllowBroadcasters("1", "2")
+	if reason := c.broadcasterDropReason(n("1")); reason != DropReasonBroadcasterDenied {
+		t.Fatalf("Expected the deny list to take precedence, got %q", reason)
+	}
+	if reason := c.broadcasterDropReason(n("3")); reason != DropReasonBroadcasterNotAllowed {
+		t.Fatalf("Expected broadcaster not on the allow list to be dropped, got %q", reason)
+	}
+	if reason := c.broadcasterDropReason(n("")); reason != "" {
+		t.Fatalf("Expected notifications without a broadcaster to be kept, got %q", reason)
+	}
+	c.UndenyBroadcasters("1")
+	c.AllowBroadcasters()
+	if reason := c.broadcasterDropReason(n("3")); reason != "" {
+		t.Fatalf("Expected lists to be removed, got %q", reason)
+	}
+}
+
+func TestHandlerDropsDeniedBroadcaster(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	var calls atomic.Int32
+	c.On("channel.chat.message", func(event json.RawMessage) {
+		calls.Add(1)
+	})
+	c.DenyBroadcasters("1971641")
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	if !c.handledEventsChecker.IsHandled("a") {
+		t.Fatal("Expected dropped notification to be marked as handled")
+	}
+	if dropped := c.Stats().Types["channel.chat.message"].Dropped; dropped != 1 {
+		t.Fatalf("Expected 1 dropped notification, got %d", dropped)
+	}
+
+	c.UndenyBroadcasters("1971641")
+	c.Handler(httptest.NewRecorder(), signedRequest("b", messageTypeNotification, chatMessageBody))
+	for deadline := time.Now().Add(time.Second); calls.Load() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected handler to be called after undenying the broadcaster")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,86 @@
+package twitchwh
+
+import (
+	"sort"
+	"sync"
+)
+
+// failureBudgets counts consecutive handler failures per event type and disables handlers that exceed
+// ClientConfig.HandlerFailureBudget.
+type failureBudgets struct {
+	mu       sync.Mutex
+	failures map[string]int
+	disabled map[string]struct{}
+}
+
+func (b *failureBudgets) isDisabled(eventType string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, disabled := b.disabled[eventType]
+	return disabled
+}
+
+// record counts the result of a handler. Returns true if the handler was disabled by this failure.
+func (b *failureBudgets) record(eventType string, err error, budget int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		delete(b.failures, eventType)
+		return false
+	}
+	if b.failures == nil {
+		b.failures = make(map[string]int)
+		b.disabled = make(map[string]struct{})
+	}
+	b.failures[eventType]++
+	if _, disabled := b.disabled[eventType]; disabled || b.failures[eventType] < budget {
+		return false
+	}
+	b.disabled[eventType] = struct{}{}
+	return true
+}
+
+func (b *failureBudgets) enable(eventType string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, eventType)
+	delete(b.disabled, eventType)
+}
+
+// EnableHandler re-enables the handler for an event type that was disabled after exceeding
+// ClientConfig.HandlerFailureBudget. Registering a new handler for the type with On or OnContext also re-enables it.
+func (c *Client) EnableHandler(eventType string) {
+	c.budgets.enable(eventType)
+}
+
+// DisabledHandlers returns the event types whose handler was disabled after exceeding
+// ClientConfig.HandlerFailureBudget, sorted.
+func (c *Client) DisabledHandlers() []string {
+	c.budgets.mu.Lock()
+	defer c.budgets.mu.Unlock()
+	types := make([]string, 0, len(c.budgets.disabled))
+	for eventType := range c.budgets.disabled {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// recordHandlerResult counts a handler result against the failure budget of its event type,
+// and disables the handler once the budget is used up.
+func (c *Client) recordHandlerResult(eventType string, err error) {
+	if c.failureBudget <= 0 || !c.budgets.record(eventType, err, c.failureBudget) {
+		return
+	}
+	c.logger.Error("Handler failed too often, disabling it", "type", eventType, "failures", c.failureBudget, "error", err)
+	if c.OnHandlerDisabled != nil {
+		c.OnHandlerDisabled(eventType, err)
+	}
+}
+
+// deadLetterHandler passes a notification that no handler will process to ClientConfig.HandlerDeadLetter.
+func (c *Client) deadLetterHandler(n Notification, err error) {
+	if c.handlerDeadLetter != nil {
+		c.handlerDeadLetter(n, err)
+	}
+}
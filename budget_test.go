@@ -0,0 +1,82 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandlerFailureBudget(t *testing.T) {
+	var deadLettered []Notification
+	c := newClient(ClientConfig{
+		HandlerFailureBudget: 3,
+		HandlerDeadLetter: func(n Notification, err error) {
+			var disabledErr *HandlerDisabledError
+			if !errors.As(err, &disabledErr) {
+				t.Errorf("Expected HandlerDisabledError, got %v", err)
+			}
+			deadLettered = append(deadLettered, n)
+		},
+	})
+	var disabled []string
+	c.OnHandlerDisabled = func(eventType string, err error) {
+		disabled = append(disabled, eventType)
+	}
+	calls := 0
+	handler := func(ctx context.Context, event json.RawMessage) error {
+		calls++
+		return errors.New("broken")
+	}
+	c.OnContext("channel.chat.message", handler)
+
+	n := Notification{Subscription: Subscription{Type: "channel.chat.message"}}
+	for i := 0; i < 5; i++ {
+		c.dispatch(n, handler)
+	}
+	if calls != 3 || len(deadLettered) != 2 {
+		t.Fatalf("Expected 3 calls and 2 dead-lettered notifications, got %d and %d", calls, len(deadLettered))
+	}
+	if len(disabled) != 1 || len(c.DisabledHandlers()) != 1 {
+		t.Fatalf("Expected the handler to be disabled once, got %v", disabled)
+	}
+
+	c.EnableHandler("channel.chat.message")
+	c.dispatch(n, handler)
+	if calls != 4 || len(c.DisabledHandlers()) != 0 {
+		t.Fatalf("Expected the handler to run after being enabled, got %d calls", calls)
+	}
+}
+
+func TestHandlerDeadLetterSubscription(t *testing.T) {
+	deadLettered := make(chan Notification, 1)
+	c := newClient(ClientConfig{
+		WebhookSecret:        testWebhookSecret,
+		HandlerFailureBudget: 1,
+		HandlerDeadLetter: func(n Notification, err error) {
+			deadLettered <- n
+		},
+	})
+	failed := make(chan struct{}, 1)
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		failed <- struct{}{}
+		return errors.New("broken")
+	})
+
+	c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+	<-failed
+	for len(c.DisabledHandlers()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Handler(httptest.NewRecorder(), signedRequest("b", messageTypeNotification, chatMessageBody))
+	select {
+	case n := <-deadLettered:
+		if n.Subscription.Condition.BroadcasterUserID != "1971641" || n.Subscription.Version != "1" {
+			t.Fatalf("Expected the full subscription in the dead letter, got %+v", n.Subscription)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the notification to be dead-lettered")
+	}
+}
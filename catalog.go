@@ -0,0 +1,295 @@
+package twitchwh
+
+import (
+	"slices"
+	"sort"
+)
+
+// EventSub subscription types, see https://dev.twitch.tv/docs/eventsub/eventsub-subscription-types/.
+const (
+	TypeAutomodMessageHold                               = "automod.message.hold"
+	TypeAutomodMessageUpdate                             = "automod.message.update"
+	TypeAutomodSettingsUpdate                            = "automod.settings.update"
+	TypeAutomodTermsUpdate                               = "automod.terms.update"
+	TypeChannelAdBreakBegin                              = "channel.ad_break.begin"
+	TypeChannelBan                                       = "channel.ban"
+	TypeChannelBitsUse                                   = "channel.bits.use"
+	TypeChannelChannelPointsAutomaticRewardRedemptionAdd = "channel.channel_points_automatic_reward_redemption.add"
+	TypeChannelChannelPointsCustomRewardAdd              = "channel.channel_points_custom_reward.add"
+	TypeChannelChannelPointsCustomRewardUpdate           = "channel.channel_points_custom_reward.update"
+	TypeChannelChannelPointsCustomRewardRemove           = "channel.channel_points_custom_reward.remove"
+	TypeChannelChannelPointsCustomRewardRedemptionAdd    = "channel.channel_points_custom_reward_redemption.add"
+	TypeChannelChannelPointsCustomRewardRedemptionUpdate = "channel.channel_points_custom_reward_redemption.update"
+	TypeChannelCharityCampaignDonate                     = "channel.charity_campaign.donate"
+	TypeChannelCharityCampaignStart                      = "channel.charity_campaign.start"
+	TypeChannelCharityCampaignProgress                   = "channel.charity_campaign.progress"
+	TypeChannelCharityCampaignStop                       = "channel.charity_campaign.stop"
+	TypeChannelChatClear                                 = "channel.chat.clear"
+	TypeChannelChatClearUserMessages                     = "channel.chat.clear_user_messages"
+	TypeChannelChatMessage                               = "channel.chat.message"
+	TypeChannelChatMessageDelete                         = "channel.chat.message_delete"
+	TypeChannelChatNotification                          = "channel.chat.notification"
+	TypeChannelChatSettingsUpdate                        = "channel.chat_settings.update"
+	TypeChannelChatUserMessageHold                       = "channel.chat.user_message_hold"
+	TypeChannelChatUserMessageUpdate                     = "channel.chat.user_message_update"
+	TypeChannelCheer                                     = "channel.cheer"
+	TypeChannelFollow                                    = "channel.follow"
+	TypeChannelGoalBegin                                 = "channel.goal.begin"
+	TypeChannelGoalProgress                              = "channel.goal.progress"
+	TypeChannelGoalEnd                                   = "channel.goal.end"
+	TypeChannelHypeTrainBegin                            = "channel.hype_train.begin"
+	TypeChannelHypeTrainProgress                         = "channel.hype_train.progress"
+	TypeChannelHypeTrainEnd                              = "channel.hype_train.end"
+	TypeChannelModerate                                  = "channel.moderate"
+	TypeChannelModeratorAdd                              = "channel.moderator.add"
+	TypeChannelModeratorRemove                           = "channel.moderator.remove"
+	TypeChannelPollBegin                                 = "channel.poll.begin"
+	TypeChannelPollProgress                              = "channel.poll.progress"
+	TypeChannelPollEnd                                   = "channel.poll.end"
+	TypeChannelPredictionBegin                           = "channel.prediction.begin"
+	TypeChannelPredictionProgress                        = "channel.prediction.progress"
+	TypeChannelPredictionLock                            = "channel.prediction.lock"
+	TypeChannelPredictionEnd                             = "channel.prediction.end"
+	TypeChannelRaid                                      = "channel.raid"
+	TypeChannelSharedChatBegin                           = "channel.shared_chat.begin"
+	TypeChannelSharedChatUpdate                          = "channel.shared_chat.update"
+	TypeChannelSharedChatEnd                             = "channel.shared_chat.end"
+	TypeChannelShieldModeBegin                           = "channel.shield_mode.begin"
+	TypeChannelShieldModeEnd                             = "channel.shield_mode.end"
+	TypeChannelShoutoutCreate                            = "channel.shoutout.create"
+	TypeChannelShoutoutReceive                           = "channel.shoutout.receive"
+	TypeChannelSubscribe                                 = "channel.subscribe"
+	TypeChannelSubscriptionEnd                           = "channel.subscription.end"
+	TypeChannelSubscriptionGift                          = "channel.subscription.gift"
+	TypeChannelSubscriptionMessage                       = "channel.subscription.message"
+	TypeChannelSuspiciousUserMessage                     = "channel.suspicious_user.message"
+	TypeChannelSuspiciousUserUpdate                      = "channel.suspicious_user.update"
+	TypeChannelUnban                                     = "channel.unban"
+	TypeChannelUnbanRequestCreate                        = "channel.unban_request.create"
+	TypeChannelUnbanRequestResolve                       = "channel.unban_request.resolve"
+	TypeChannelUpdate                                    = "channel.update"
+	TypeChannelVIPAdd                                    = "channel.vip.add"
+	TypeChannelVIPRemove                                 = "channel.vip.remove"
+	TypeChannelWarningAcknowledge                        = "channel.warning.acknowledge"
+	TypeChannelWarningSend                               = "channel.warning.send"
+	TypeConduitShardDisabled                             = "conduit.shard.disabled"
+	TypeDropEntitlementGrant                             = "drop.entitlement.grant"
+	TypeExtensionBitsTransactionCreate                   = "extension.bits_transaction.create"
+	TypeStreamOnline                                     = "stream.online"
+	TypeStreamOffline                                    = "stream.offline"
+	TypeUserAuthorizationGrant                           = "user.authorization.grant"
+	TypeUserAuthorizationRevoke                          = "user.authorization.revoke"
+	TypeUserUpdate                                       = "user.update"
+	TypeUserWhisperMessage                               = "user.whisper.message"
+)
+
+// subscriptionVersions holds the versions Twitch supports for each subscription type, oldest first.
+var subscriptionVersions = map[string][]string{
+	TypeAutomodMessageHold:                               {"1", "2"},
+	TypeAutomodMessageUpdate:                             {"1", "2"},
+	TypeAutomodSettingsUpdate:                            {"1"},
+	TypeAutomodTermsUpdate:                               {"1"},
+	TypeChannelAdBreakBegin:                              {"1"},
+	TypeChannelBan:                                       {"1"},
+	TypeChannelBitsUse:                                   {"1"},
+	TypeChannelChannelPointsAutomaticRewardRedemptionAdd: {"1", "2"},
+	TypeChannelChannelPointsCustomRewardAdd:              {"1"},
+	TypeChannelChannelPointsCustomRewardUpdate:           {"1"},
+	TypeChannelChannelPointsCustomRewardRemove:           {"1"},
+	TypeChannelChannelPointsCustomRewardRedemptionAdd:    {"1"},
+	TypeChannelChannelPointsCustomRewardRedemptionUpdate: {"1"},
+	TypeChannelCharityCampaignDonate:                     {"1"},
+	TypeChannelCharityCampaignStart:                      {"1"},
+	TypeChannelCharityCampaignProgress:                   {"1"},
+	TypeChannelCharityCampaignStop:                       {"1"},
+	TypeChannelChatClear:                                 {"1"},
+	TypeChannelChatClearUserMessages:                     {"1"},
+	TypeChannelChatMessage:                               {"1"},
+	TypeChannelChatMessageDelete:                         {"1"},
+	TypeChannelChatNotification:                          {"1"},
+	TypeChannelChatSettingsUpdate:                        {"1"},
+	TypeChannelChatUserMessageHold:                       {"1"},
+	TypeChannelChatUserMessageUpdate:                     {"1"},
+	TypeChannelCheer:                                     {"1"},
+	TypeChannelFollow:                                    {"2"},
+	TypeChannelGoalBegin:                                 {"1"},
+	TypeChannelGoalProgress:                              {"1"},
+	TypeChannelGoalEnd:                                   {"1"},
+	TypeChannelHypeTrainBegin:                            {"1", "2"},
+	TypeChannelHypeTrainProgress:                         {"1", "2"},
+	TypeChannelHypeTrainEnd:                              {"1", "2"},
+	TypeChannelModerate:                                  {"1", "2"},
+	TypeChannelModeratorAdd:                              {"1"},
+	TypeChannelModeratorRemove:                           {"1"},
+	TypeChannelPollBegin:                                 {"1"},
+	TypeChannelPollProgress:                              {"1"},
+	TypeChannelPollEnd:                                   {"1"},
+	TypeChannelPredictionBegin:                           {"1"},
+	TypeChannelPredictionProgress:                        {"1"},
+	TypeChannelPredictionLock:                            {"1"},
+	TypeChannelPredictionEnd:                             {"1"},
+	TypeChannelRaid:                                      {"1"},
+	TypeChannelSharedChatBegin:                           {"1"},
+	TypeChannelSharedChatUpdate:                          {"1"},
+	TypeChannelSharedChatEnd:                             {"1"},
+	TypeChannelShieldModeBegin:                           {"1"},
+	TypeChannelShieldModeEnd:                             {"1"},
+	TypeChannelShoutoutCreate:                            {"1"},
+	TypeChannelShoutoutReceive:                           {"1"},
+	TypeChannelSubscribe:                                 {"1"},
+	TypeChannelSubscriptionEnd:                           {"1"},
+	TypeChannelSubscriptionGift:                          {"1"},
+	TypeChannelSubscriptionMessage:                       {"1"},
+	TypeChannelSuspiciousUserMessage:                     {"1"},
+	TypeChannelSuspiciousUserUpdate:                      {"1"},
+	TypeChannelUnban:                                     {"1"},
+	TypeChannelUnbanRequestCreate:                        {"1"},
+	TypeChannelUnbanRequestResolve:                       {"1"},
+	TypeChannelUpdate:                                    {"2"},
+	TypeChannelVIPAdd:                                    {"1"},
+	TypeChannelVIPRemove:                                 {"1"},
+	TypeChannelWarningAcknowledge:                        {"1"},
+	TypeChannelWarningSend:                               {"1"},
+	TypeConduitShardDisabled:                             {"1"},
+	TypeDropEntitlementGrant:                             {"1"},
+	TypeExtensionBitsTransactionCreate:                   {"1"},
+	TypeStreamOnline:                                     {"1"},
+	TypeStreamOffline:                                    {"1"},
+	TypeUserAuthorizationGrant:                           {"1"},
+	TypeUserAuthorizationRevoke:                          {"1"},
+	TypeUserUpdate:                                       {"1"},
+	TypeUserWhisperMessage:                               {"1"},
+}
+
+// conditionFields holds the condition fields, by JSON name, that select the events of each subscription type. Other
+// fields don't change which events are sent.
+var conditionFields = map[string][]string{
+	TypeAutomodMessageHold:                               {"broadcaster_user_id", "moderator_user_id"},
+	TypeAutomodMessageUpdate:                             {"broadcaster_user_id", "moderator_user_id"},
+	TypeAutomodSettingsUpdate:                            {"broadcaster_user_id", "moderator_user_id"},
+	TypeAutomodTermsUpdate:                               {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelAdBreakBegin:                              {"broadcaster_user_id"},
+	TypeChannelBan:                                       {"broadcaster_user_id"},
+	TypeChannelBitsUse:                                   {"broadcaster_user_id"},
+	TypeChannelChannelPointsAutomaticRewardRedemptionAdd: {"broadcaster_user_id"},
+	TypeChannelChannelPointsCustomRewardAdd:              {"broadcaster_user_id"},
+	TypeChannelChannelPointsCustomRewardUpdate:           {"broadcaster_user_id", "reward_id"},
+	TypeChannelChannelPointsCustomRewardRemove:           {"broadcaster_user_id", "reward_id"},
+	TypeChannelChannelPointsCustomRewardRedemptionAdd:    {"broadcaster_user_id", "reward_id"},
+	TypeChannelChannelPointsCustomRewardRedemptionUpdate: {"broadcaster_user_id", "reward_id"},
+	TypeChannelCharityCampaignDonate:                     {"broadcaster_user_id"},
+	TypeChannelCharityCampaignStart:                      {"broadcaster_user_id"},
+	TypeChannelCharityCampaignProgress:                   {"broadcaster_user_id"},
+	TypeChannelCharityCampaignStop:                       {"broadcaster_user_id"},
+	TypeChannelChatClear:                                 {"broadcaster_user_id", "user_id"},
+	TypeChannelChatClearUserMessages:                     {"broadcaster_user_id", "user_id"},
+	TypeChannelChatMessage:                               {"broadcaster_user_id", "user_id"},
+	TypeChannelChatMessageDelete:                         {"broadcaster_user_id", "user_id"},
+	TypeChannelChatNotification:                          {"broadcaster_user_id", "user_id"},
+	TypeChannelChatSettingsUpdate:                        {"broadcaster_user_id", "user_id"},
+	TypeChannelChatUserMessageHold:                       {"broadcaster_user_id", "user_id"},
+	TypeChannelChatUserMessageUpdate:                     {"broadcaster_user_id", "user_id"},
+	TypeChannelCheer:                                     {"broadcaster_user_id"},
+	TypeChannelFollow:                                    {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelGoalBegin:                                 {"broadcaster_user_id"},
+	TypeChannelGoalProgress:                              {"broadcaster_user_id"},
+	TypeChannelGoalEnd:                                   {"broadcaster_user_id"},
+	TypeChannelHypeTrainBegin:                            {"broadcaster_user_id"},
+	TypeChannelHypeTrainProgress:                         {"broadcaster_user_id"},
+	TypeChannelHypeTrainEnd:                              {"broadcaster_user_id"},
+	TypeChannelModerate:                                  {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelModeratorAdd:                              {"broadcaster_user_id"},
+	TypeChannelModeratorRemove:                           {"broadcaster_user_id"},
+	TypeChannelPollBegin:                                 {"broadcaster_user_id"},
+	TypeChannelPollProgress:                              {"broadcaster_user_id"},
+	TypeChannelPollEnd:                                   {"broadcaster_user_id"},
+	TypeChannelPredictionBegin:                           {"broadcaster_user_id"},
+	TypeChannelPredictionProgress:                        {"broadcaster_user_id"},
+	TypeChannelPredictionLock:                            {"broadcaster_user_id"},
+	TypeChannelPredictionEnd:                             {"broadcaster_user_id"},
+	TypeChannelRaid:                                      {"from_broadcaster_user_id", "to_broadcaster_user_id"},
+	TypeChannelSharedChatBegin:                           {"broadcaster_user_id"},
+	TypeChannelSharedChatUpdate:                          {"broadcaster_user_id"},
+	TypeChannelSharedChatEnd:                             {"broadcaster_user_id"},
+	TypeChannelShieldModeBegin:                           {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelShieldModeEnd:                             {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelShoutoutCreate:                            {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelShoutoutReceive:                           {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelSubscribe:                                 {"broadcaster_user_id"},
+	TypeChannelSubscriptionEnd:                           {"broadcaster_user_id"},
+	TypeChannelSubscriptionGift:                          {"broadcaster_user_id"},
+	TypeChannelSubscriptionMessage:                       {"broadcaster_user_id"},
+	TypeChannelSuspiciousUserMessage:                     {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelSuspiciousUserUpdate:                      {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelUnban:                                     {"broadcaster_user_id"},
+	TypeChannelUnbanRequestCreate:                        {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelUnbanRequestResolve:                       {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelUpdate:                                    {"broadcaster_user_id"},
+	TypeChannelVIPAdd:                                    {"broadcaster_user_id"},
+	TypeChannelVIPRemove:                                 {"broadcaster_user_id"},
+	TypeChannelWarningAcknowledge:                        {"broadcaster_user_id", "moderator_user_id"},
+	TypeChannelWarningSend:                               {"broadcaster_user_id", "moderator_user_id"},
+	TypeConduitShardDisabled:                             {"client_id", "conduit_id"},
+	TypeDropEntitlementGrant:                             {"organization_id", "category_id", "campaign_id"},
+	TypeExtensionBitsTransactionCreate:                   {"extension_client_id"},
+	TypeStreamOnline:                                     {"broadcaster_user_id"},
+	TypeStreamOffline:                                    {"broadcaster_user_id"},
+	TypeUserAuthorizationGrant:                           {"client_id"},
+	TypeUserAuthorizationRevoke:                          {"client_id"},
+	TypeUserUpdate:                                       {"user_id"},
+	TypeUserWhisperMessage:                               {"user_id"},
+}
+
+// rewardTypes are the subscription types whose condition may have a reward_id.
+var rewardTypes = map[string]bool{
+	TypeChannelChannelPointsCustomRewardUpdate:           true,
+	TypeChannelChannelPointsCustomRewardRemove:           true,
+	TypeChannelChannelPointsCustomRewardRedemptionAdd:    true,
+	TypeChannelChannelPointsCustomRewardRedemptionUpdate: true,
+}
+
+// SubscriptionVersions returns the versions Twitch supports for a subscription type, oldest first, as known to this
+// release of the package. Returns nil for unknown types.
+func SubscriptionVersions(Type string) []string {
+	return slices.Clone(subscriptionVersions[Type])
+}
+
+// closestTypes returns the known subscription types most similar to Type, eg: to suggest a fix for a typo.
+// Types more than a few edits away are left out.
+func closestTypes(Type string) []string {
+	best := 4
+	var closest []string
+	for known := range subscriptionVersions {
+		distance := editDistance(Type, known)
+		if distance < best {
+			best = distance
+			closest = closest[:0]
+		}
+		if distance == best {
+			closest = append(closest, known)
+		}
+	}
+	sort.Strings(closest)
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
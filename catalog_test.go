@@ -0,0 +1,18 @@
+package twitchwh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The twitchwhtest package imports this one, so its samples are checked on disk.
+func TestSampleForEveryVersion(t *testing.T) {
+	for Type, versions := range subscriptionVersions {
+		for _, version := range versions {
+			if _, err := os.Stat(filepath.Join("twitchwhtest", "samples", Type, version+".json")); err != nil {
+				t.Errorf("No sample for %s version %s", Type, version)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package twitchwh
+
+import "fmt"
+
+// WithTwitchCLI makes Client.Handler accept events sent by the Twitch CLI
+// (https://dev.twitch.tv/docs/cli/event-command/), so handlers can be tested locally without a public endpoint:
+//
+//	client, err := twitchwh.NewReceiver("local-test-secret", twitchwh.WithTwitchCLI())
+//	twitchwh.OnEvent(client, twitchwh.TypeChannelCheer, handleCheer)
+//	http.HandleFunc("/eventsub", client.Handler)
+//	http.ListenAndServe(":8080", nil)
+//
+// Then trigger events with the same secret:
+//
+//	twitch event trigger channel.cheer -F http://localhost:8080/eventsub -s local-test-secret
+//	twitch event verify-subscription channel.cheer -F http://localhost:8080/eventsub -s local-test-secret
+//
+// The CLI signs events like Twitch does, so they are verified and dispatched as usual. The only difference is that
+// message timestamps are not checked, since `--timestamp` and `twitch event retrigger` send timestamps older than
+// Twitch would. Events are still deduplicated by message ID, so a retriggered event is only handled the first
+// time. The CLI uses random broadcaster IDs unless `--to-user` is set, which broadcaster filters drop.
+//
+// NewClient returns an error if the webhook secret isn't 10 to 100 characters long, the CLI refuses other secrets.
+// Don't use it in production.
+func WithTwitchCLI() Option {
+	return func(o *clientOptions) {
+		o.config.TwitchCLI = true
+	}
+}
+
+// checkTwitchCLISecret returns an error if the Twitch CLI doesn't accept secret with its --secret flag.
+func checkTwitchCLISecret(secret string) error {
+	if len(secret) < 10 || len(secret) > 100 {
+		return fmt.Errorf("the Twitch CLI requires a webhook secret of 10 to 100 characters, got %d", len(secret))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package twitchwh
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTwitchCLIOldTimestamp(t *testing.T) {
+	// Sent by `twitch event trigger --timestamp` or `twitch event retrigger`
+	timestamp := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+	request := func(messageID string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/eventsub", bytes.NewBufferString(chatMessageBody))
+		r.Header.Set(twitchMessageID, messageID)
+		r.Header.Set(twitchMessageTimestamp, timestamp)
+		r.Header.Set(twitchMessageSignature, Signature(testWebhookSecret, messageID, timestamp, []byte(chatMessageBody)))
+		r.Header.Set(twitchMessageType, messageTypeNotification)
+		return r
+	}
+
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	called := make(chan struct{}, 2)
+	c.On("channel.chat.message", func(json.RawMessage) { called <- struct{}{} })
+	c.Handler(httptest.NewRecorder(), request("a"))
+
+	cli := newClient(ClientConfig{WebhookSecret: testWebhookSecret, TwitchCLI: true})
+	cli.On("channel.chat.message", func(json.RawMessage) { called <- struct{}{} })
+	w := httptest.NewRecorder()
+	cli.Handler(w, request("b"))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", w.Code)
+	}
+	select {
+	case <-called:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handler was not called")
+	}
+	select {
+	case <-called:
+		t.Fatal("Expected the old message to be ignored without TwitchCLI")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestTwitchCLISecret(t *testing.T) {
+	if _, err := NewReceiver("short", WithTwitchCLI()); err == nil {
+		t.Fatal("Expected error for a secret the Twitch CLI refuses")
+	}
+	if _, err := NewReceiver("local-test-secret", WithTwitchCLI()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,18 +1,20 @@
 // Package twitchwh is a library for interacting with Twitch EventSub over the Webhook transport.
 // It allows you to assign event handlers to specific events.
 //
-// To get started, create a new client using the New function. Then, assign an event handler using the On<EventType> fields.
+// To get started, create a new client using the NewClient (or New) function. Then, assign an event handler using the On<EventType> fields.
 // Finally, setup the HTTP handler for your application using the Handler function.
 package twitchwh
 
 import (
+	"context"
 	"encoding/json"
-	"io"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
-	"os"
+	"net/netip"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,27 +23,71 @@ type HandledEventsChecker interface {
 	MarkHandled(messageID string)
 }
 
+// SinkDeliveryChecker can optionally be implemented by a HandledEventsChecker to record deliveries to named sinks
+// (see SinkName). A notification is then published to each named sink at most once, even if it is processed again,
+// eg: when an outbox entry is retried because another sink failed. Implement it on a persistent store to keep the
+// guarantee across restarts.
+type SinkDeliveryChecker interface {
+	IsDelivered(messageID, sink string) bool
+	MarkDelivered(messageID, sink string)
+}
+
+// DefaultHandledEventsChecker is an in-memory HandledEventsChecker. Lookups and inserts take constant time,
+// regardless of how many events were handled.
 type DefaultHandledEventsChecker struct {
 	mu            sync.RWMutex
-	handledEvents []string
+	handledEvents map[string]struct{}
+	deliveries    map[sinkDelivery]struct{}
+}
+
+type sinkDelivery struct {
+	messageID string
+	sink      string
 }
 
 func NewDefaultHandledEventsChecker() *DefaultHandledEventsChecker {
 	return &DefaultHandledEventsChecker{
-		handledEvents: make([]string, 0),
+		handledEvents: make(map[string]struct{}),
+		deliveries:    make(map[sinkDelivery]struct{}),
 	}
 }
 
 func (d *DefaultHandledEventsChecker) IsHandled(messageID string) bool {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	return slices.Contains(d.handledEvents, messageID)
+	_, ok := d.handledEvents[messageID]
+	return ok
 }
 
 func (d *DefaultHandledEventsChecker) MarkHandled(messageID string) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	d.handledEvents = append(d.handledEvents, messageID)
+	d.handledEvents[messageID] = struct{}{}
+}
+
+func (d *DefaultHandledEventsChecker) IsDelivered(messageID, sink string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.deliveries[sinkDelivery{messageID, sink}]
+	return ok
+}
+
+func (d *DefaultHandledEventsChecker) MarkDelivered(messageID, sink string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deliveries[sinkDelivery{messageID, sink}] = struct{}{}
+}
+
+// Len returns the number of handled message IDs.
+func (d *DefaultHandledEventsChecker) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.handledEvents)
+}
+
+// Ping always succeeds, the default checker is in-memory.
+func (d *DefaultHandledEventsChecker) Ping() error {
+	return nil
 }
 
 // ClientConfig is used to configure a new Client
@@ -54,42 +100,247 @@ type ClientConfig struct {
 	WebhookSecret string
 	// Full EventSub URL path, eg: https://mydomain.com/eventsub
 	WebhookURL string
-	// Log output
-	Debug                bool
+	// Log output. Equivalent to LogLevel: LogLevelDebug. Ignored if Logger is set.
+	Debug bool
+	// Minimum level written by the default logger, eg: LogLevelWarn to only see warnings and errors in production.
+	// Ignored if Logger is set.
+	LogLevel LogLevel
+	// Repetitive log lines (invalid signatures, duplicate events, events without a handler) are logged at most once
+	// per interval, with a summary of how many were suppressed. Defaults to 10 seconds, set to a negative value to
+	// log every line.
+	LogSampleInterval time.Duration
+	// Structured logger used for all log output. If nil, a text logger writing to stdout is used according to
+	// LogLevel and Debug.
+	Logger               *slog.Logger
 	HandledEventsChecker HandledEventsChecker
+	// Publish basic counters (events handled, duplicates, signature failures, token refreshes)
+	// via expvar under the "twitchwh" map. The map is shared by all clients in the process.
+	ExpvarMetrics bool
+	// Receives telemetry about events, handlers, and signature failures. Can be combined with ExpvarMetrics.
+	MetricsHook MetricsHook
+	// Log a warning and call MetricsHook.SlowHandler whenever a handler runs longer than this. Disabled if zero.
+	SlowHandlerThreshold time.Duration
+	// Log a warning whenever a webhook response is written later than this after the request was received. Twitch
+	// expects a response within a few seconds and revokes subscriptions whose notifications time out repeatedly, so
+	// creeping latency is caught before that happens. Defaults to 2 seconds, disabled if negative. See
+	// Stats.Responses.
+	SlowResponseThreshold time.Duration
+	// Store for subscription lifecycle history, see Client.History. Defaults to a MemoryAuditStore.
+	AuditStore AuditStore
+	// Called with every internal error that is otherwise only logged, like token refresh failures, unreadable
+	// request bodies, or handler panics. Errors are wrapped in an InternalError, use errors.As to inspect the cause.
+	// Handler errors and panics carry the notification in a HandlerError.
+	// Called synchronously, so it should not block.
+	ErrorHandler func(error)
+	// Persist every notification before acknowledging it, and deliver it to handlers and sinks from a background
+	// dispatcher with retries. Handlers then run one at a time, in the order notifications were received. Entries are
+	// leased to the dispatcher for 1 minute, a notification whose handlers and sinks take longer than 30 seconds may be
+	// delivered again by another replica.
+	Outbox Outbox
+	// How often the outbox is checked for entries that are due for a retry. Defaults to 5 seconds.
+	OutboxPollInterval time.Duration
+	// Number of delivery attempts before an outbox entry is given up on. Defaults to 10.
+	OutboxMaxAttempts int
+	// Respond 503 Service Unavailable to notifications while this many handlers are running, so Twitch redelivers
+	// them later instead of the process accepting unbounded work during event storms. Unlimited if zero.
+	MaxConcurrentHandlers int
+	// How notifications are handed to handlers. Defaults to DispatchGoroutine.
+	DispatchMode DispatchMode
+	// Number of notifications queued per event type with DispatchWorkers. Notifications are rejected with 503 while
+	// the queue of their type is full. Defaults to 1000.
+	DispatchQueueSize int
+	// Cache the subscription list returned by GetSubscriptions, GetSubscriptionsByType, and GetSubscriptionsByStatus
+	// for this long, instead of paging through Helix on every call. Disabled if zero.
+	// See Client.InvalidateSubscriptionCache.
+	SubscriptionCacheTTL time.Duration
+	// Maximum number of Helix requests in flight. Further requests are queued by priority, see Client.Background.
+	// Unlimited if zero. Requests are also queued while the Helix rate limit is used up.
+	MaxConcurrentHelixRequests int
+	// Records which tenant owns each subscription, see Client.Tenant. Defaults to a MemoryRegistry.
+	Registry Registry
+	// Decides which replica may create and delete subscriptions. Other replicas get a NotLeaderError.
+	// Every instance may change subscriptions if nil.
+	Elector Elector
+	// Held around AddSubscription, keyed by type and condition, so replicas don't create the same subscription
+	// concurrently. A replica that acquires the lock after another one created the subscription gets a
+	// DuplicateSubscriptionError without sending a request. Disabled if nil.
+	Locker Locker
+	// Delete every subscription created by this client on Close. Meant for development against a tunnel URL that
+	// changes every run, where subscriptions of previous runs can never be verified again.
+	Ephemeral bool
+	// Acknowledge notifications at most this long after they were received, even if the outbox or
+	// HandledEventsChecker hasn't finished with them yet. They are still accepted in the background, but are lost
+	// if that fails, since Twitch won't redeliver them. Twitch expects a response within a few seconds, so a slow
+	// store would otherwise get the subscription revoked. Disabled if zero. See MetricsHook.ResponseWritten.
+	ResponseDeadline time.Duration
+	// Delete a subscription if AddSubscription times out waiting for its verification, so failed attempts don't
+	// linger as webhook_callback_verification_pending and count against the subscription limits.
+	DeleteUnverifiedSubscriptions bool
+	// Status code Handler responds to requests that aren't POST, like load balancer health checks and uptime probes.
+	// A 2xx status is responded with the body "ok", any other status with an Allow: POST header.
+	// Defaults to 405 Method Not Allowed.
+	ProbeStatus int
+	// Status codes Handler responds to duplicates, too old messages, unknown message types, and invalid signatures.
+	// Defaults to DefaultStatusPolicy, see StrictStatusPolicy.
+	StatusPolicy StatusPolicy
+	// IP addresses or CIDR ranges (eg: "10.0.0.0/8") of reverse proxies in front of the handler. X-Forwarded-For is
+	// honored for requests from them, so logs and audit entries show the real source of a request.
+	TrustedProxies []string
+	// Disable the handler of an event type after it failed (returned an error or panicked) this many times in a row,
+	// so a buggy handler doesn't keep burning CPU on every notification. Notifications of disabled handlers are passed
+	// to HandlerDeadLetter. See Client.OnHandlerDisabled and Client.EnableHandler. Disabled if zero.
+	HandlerFailureBudget int
+	// Receives notifications that no handler processed, see HandlerFailureBudget and ResponsePolicy.Retry.
+	HandlerDeadLetter DeadLetter
+	// Sends the client's Helix requests, eg: a fake in tests. Defaults to an implementation calling Twitch with an
+	// app access token. No token is generated if set, the implementation is responsible for authorization.
+	Helix HelixAPI
+	// Receive notifications, manage subscriptions, or both (the default). See NewReceiver.
+	Mode Mode
+	// Shares verified subscriptions between instances, so AddSubscription returns once any instance answered the
+	// challenge. Set it on the receivers and the managers.
+	VerificationRelay VerificationRelay
+	// Supplies the webhook secret and client secret at runtime, overriding WebhookSecret and ClientSecret. They are
+	// pulled when the client is created, every SecretRefreshInterval, and when Client.RefreshSecrets is called.
+	SecretProvider SecretProvider
+	// How often the secrets are pulled from SecretProvider. Only when the client is created and on
+	// Client.RefreshSecrets if zero.
+	SecretRefreshInterval time.Duration
+	// How long requests signed with the previous webhook secret are accepted after SecretProvider returned a new one.
+	// Defaults to 1 hour.
+	SecretRotationWindow time.Duration
+	// Accept events sent with `twitch event trigger` of the Twitch CLI, see WithTwitchCLI. For local testing only.
+	TwitchCLI bool
 }
 
 type Client struct {
 	clientID      string
 	clientSecret  string
 	token         string
+	tokenMu       sync.RWMutex
 	webhookSecret string
 	webhookURL    string
+	mode          Mode
+	twitchCLI     bool
 	debug         bool
+	helixURL      string
+	oauthURL      string
 
-	webhookSecretMu      sync.RWMutex
-	logger               *log.Logger
-	httpClient           *http.Client
-	handledEventsChecker HandledEventsChecker
-	// Client.Handler sends verified IDs to this channel to be read in Client.AddSubscription
+	// Guards webhookSecret, webhookURL, and the previous secret and URLs
+	webhookSecretMu       sync.RWMutex
+	previousWebhookSecret string
+	previousSecretUntil   time.Time
+	previousWebhookURLs   []string
+	hmacs                 atomic.Pointer[hmacPool]
+	previousHmacs         atomic.Pointer[hmacPool]
+	logger                *slog.Logger
+	sampledLogger         *logSampler
+	httpClient            *http.Client
+	handledEventsChecker  HandledEventsChecker
+	metrics               MetricsHook
+	stats                 *statsCollector
+	slowHandlerThreshold  time.Duration
+	slowResponseThreshold time.Duration
+	health                healthState
+	auditStore            AuditStore
+	errorHandler          func(error)
+	outbox                Outbox
+	outboxWake            chan struct{}
+	outboxPollInterval    time.Duration
+	outboxMaxAttempts     int
+	outboxLease           time.Duration
+	stopOutbox            context.CancelFunc
+	outboxDone            chan struct{}
+	maxHandlers           int64
+	runningHandlers       atomic.Int64
+	draining              atomic.Bool
+	dispatchMode          DispatchMode
+	dispatchQueueSize     int
+	typeQueues            typeQueues
+	subscriptionCache     subscriptionCache
+	helixQueue            helixQueue
+	helix                 HelixAPI
+	registry              Registry
+	elector               Elector
+	locker                Locker
+	verificationRelay     VerificationRelay
+	secretProvider        SecretProvider
+	secretRefreshInterval time.Duration
+	secretRotationWindow  time.Duration
+	verifications         verifications
+	ephemeral             bool
+	responseDeadline      time.Duration
+	deleteUnverified      bool
+	probeStatus           int
+	statusPolicy          StatusPolicy
+	trustedProxies        []netip.Prefix
+	failureBudget         int
+	budgets               failureBudgets
+	handlerDeadLetter     DeadLetter
+	idempotencyLocks      keyLocks
+	schemas               schemas
+	broadcasters          broadcasterFilter
+	broadcasterFilterSet  atomic.Bool
+	created               ephemeralSubscriptions
+	background            background
+	// Client.Handler sends verified IDs to this channel if a receiver is waiting.
+	//
+	// Deprecated: AddSubscription no longer reads from this channel, and IDs are dropped if nobody is receiving.
 	VerifiedSubscriptions chan string
 
 	// Fired whenever a subscription is revoked.
-	// Check Subscription.Status for the reason.
+	// Check Subscription.RevocationReason for the reason, or use Client.OnRevocationReason.
 	OnRevocation func(Subscription)
-	handlers     map[string]func(json.RawMessage)
+	// Fired for every verification challenge before it is answered, eg: to log which subscriptions were verified.
+	OnChallenge func(sub Subscription, challenge string)
+	// Fired when the handler of an event type is disabled after exceeding ClientConfig.HandlerFailureBudget.
+	// err is the last error it returned.
+	OnHandlerDisabled func(eventType string, err error)
+	// Fired when a notification differs from the schema registered for its type with Client.SetSchema.
+	// Each difference is only reported once.
+	OnSchemaDrift func(drift SchemaDrift)
+	// Fired when a handler registered with OnEvent or OnEventContext receives an event it can't decode. The
+	// handler isn't called, and the error is reported like a handler error.
+	OnDecodeError func(eventType string, event json.RawMessage, err error)
+	// Fired when a subscription or type registered with Client.Watch or Client.WatchType has not received
+	// a notification for longer than its threshold. since is the time since the last notification (or since Watch was called).
+	OnSilence          func(sub Subscription, since time.Duration)
+	handlers           map[string]ContextHandler
+	tenantHandlers     map[string]map[string]ContextHandler
+	revocationHandlers map[RevocationReason]func(Subscription)
+	responsePolicies   map[string]ResponsePolicy
+	responseWrappers   []ResponseWrapper
+	batchers           map[string]*batcher
+	sampleRates        map[string]float64
+	eventMappers       map[[2]string]Transform
+	handlersMu         sync.RWMutex
+
+	// IDs of the subscriptions whose revocation was handled, so a Reconciler doesn't report them again
+	revokedIDs sync.Map
+
+	pending      pendingSet
+	recentErrors errorLog
+	dedup        dedupStats
+	watchdog     watchdog
+	sinks        sinks
 }
 
 // Assign a handler to a particular event type. The handler takes a json.RawMessage that contains the event body.
 // For a list of event types, see [https://dev.twitch.tv/docs/eventsub/eventsub-subscription-types/].
 func (c *Client) On(event string, handler func(json.RawMessage)) {
-	c.handlers[event] = handler
+	c.OnContext(event, func(_ context.Context, event json.RawMessage) error {
+		handler(event)
+		return nil
+	})
 }
 
+// SetWebhookSecret replaces the webhook secret used to verify requests and create subscriptions. Requests signed with
+// the old secret are rejected immediately, use RotateWebhookSecret to keep accepting them for a while.
 func (c *Client) SetWebhookSecret(secret string) {
 	c.webhookSecretMu.Lock()
 	defer c.webhookSecretMu.Unlock()
 	c.webhookSecret = secret
+	c.previousWebhookSecret = ""
 }
 
 func (c *Client) GetWebhookSecret() string {
@@ -98,57 +349,226 @@ func (c *Client) GetWebhookSecret() string {
 	return c.webhookSecret
 }
 
-// Creates a new client
+// RotateWebhookSecret replaces the webhook secret, but keeps accepting requests signed with the old secret for window.
+// Twitch signs notifications with the secret a subscription was created with, so call MigrateSubscriptions within
+// the window to recreate existing subscriptions with the new secret.
+//
+//	client.RotateWebhookSecret(newSecret, 10*time.Minute)
+//	_, err := client.MigrateSubscriptions(func(twitchwh.Subscription) bool { return true })
+func (c *Client) RotateWebhookSecret(secret string, window time.Duration) {
+	c.webhookSecretMu.Lock()
+	defer c.webhookSecretMu.Unlock()
+	c.previousWebhookSecret = c.webhookSecret
+	c.previousSecretUntil = time.Now().Add(window)
+	c.webhookSecret = secret
+}
+
+// previousSecret returns the secret replaced by RotateWebhookSecret, or false if its window has passed.
+func (c *Client) previousSecret() (string, bool) {
+	c.webhookSecretMu.RLock()
+	defer c.webhookSecretMu.RUnlock()
+	if c.previousWebhookSecret == "" || time.Now().After(c.previousSecretUntil) {
+		return "", false
+	}
+	return c.previousWebhookSecret, true
+}
+
+// SetWebhookURL replaces the callback URL used for new subscriptions. Existing subscriptions keep their callback,
+// use MigrateSubscriptions to move them.
+func (c *Client) SetWebhookURL(url string) {
+	c.webhookSecretMu.Lock()
+	defer c.webhookSecretMu.Unlock()
+	if c.webhookURL != "" && c.webhookURL != url && !slices.Contains(c.previousWebhookURLs, c.webhookURL) {
+		c.previousWebhookURLs = append(c.previousWebhookURLs, c.webhookURL)
+	}
+	c.webhookURL = url
+}
+
+// previousWebhookURL reports whether url is a webhook URL replaced by SetWebhookURL.
+func (c *Client) previousWebhookURL(url string) bool {
+	c.webhookSecretMu.RLock()
+	defer c.webhookSecretMu.RUnlock()
+	return slices.Contains(c.previousWebhookURLs, url)
+}
+
+func (c *Client) GetWebhookURL() string {
+	c.webhookSecretMu.RLock()
+	defer c.webhookSecretMu.RUnlock()
+	return c.webhookURL
+}
+
+// Creates a new client. Equivalent to NewClient with WithConfig.
 func New(config ClientConfig) (*Client, error) {
+	return NewClient(config.ClientID, config.ClientSecret, WithConfig(config))
+}
+
+// NewClient creates a new client for a Twitch application. It generates an app access token, so it fails if the
+// credentials are invalid.
+//
+//	client, err := twitchwh.NewClient(clientID, clientSecret,
+//		twitchwh.WithWebhook("https://mydomain.com/eventsub", webhookSecret),
+//		twitchwh.WithLogger(slog.Default()),
+//	)
+func NewClient(clientID string, clientSecret string, opts ...Option) (*Client, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.config.ClientID = clientID
+	o.config.ClientSecret = clientSecret
+	c := newClient(o.config)
+	if err := c.statusPolicy.validate(); err != nil {
+		return nil, err
+	}
+	if o.httpClient != nil {
+		c.httpClient = o.httpClient
+	}
+	if o.helixURL != "" {
+		c.helixURL = o.helixURL
+	}
+	if o.oauthURL != "" {
+		c.oauthURL = o.oauthURL
+	}
+
+	if err := c.RefreshSecrets(context.Background()); err != nil {
+		return nil, err
+	}
+	if o.config.Mode == ModeReceiver && c.GetWebhookSecret() == "" {
+		return nil, errors.New("webhook secret required in receiver mode")
+	}
+	if c.twitchCLI {
+		if err := checkTwitchCLISecret(c.GetWebhookSecret()); err != nil {
+			return nil, err
+		}
+		c.logger.Warn("Twitch CLI mode enabled, message timestamps are not checked")
+	}
+	if o.config.Helix == nil && o.config.Mode != ModeReceiver {
+		if err := c.startToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.outbox != nil {
+		c.startOutbox()
+	}
+	if c.verificationRelay != nil && c.mode != ModeReceiver {
+		c.goBackground(c.runVerificationRelay)
+	}
+	if c.secretProvider != nil && c.secretRefreshInterval > 0 {
+		c.goBackground(c.runSecretRefresh)
+	}
+
+	return c, nil
+}
+
+// newClient creates a client from the config without making any requests or starting background goroutines.
+func newClient(config ClientConfig) *Client {
 	handledEventsChecker := config.HandledEventsChecker
 	if handledEventsChecker == nil {
 		handledEventsChecker = NewDefaultHandledEventsChecker()
 	}
+	auditStore := config.AuditStore
+	if auditStore == nil {
+		auditStore = NewMemoryAuditStore()
+	}
+	registry := config.Registry
+	if registry == nil {
+		registry = NewMemoryRegistry()
+	}
 
 	c := &Client{
 		clientID:              config.ClientID,
 		clientSecret:          config.ClientSecret,
 		webhookSecret:         config.WebhookSecret,
 		webhookURL:            config.WebhookURL,
-		logger:                log.New(os.Stdout, "TwitchWH: ", log.Ltime|log.Lmicroseconds),
+		logger:                config.Logger,
 		debug:                 config.Debug,
+		helixURL:              defaultHelixURL,
+		oauthURL:              defaultOAuthURL,
 		httpClient:            &http.Client{},
 		handledEventsChecker:  handledEventsChecker,
+		auditStore:            auditStore,
+		registry:              registry,
+		elector:               config.Elector,
+		locker:                config.Locker,
+		ephemeral:             config.Ephemeral,
+		responseDeadline:      config.ResponseDeadline,
+		deleteUnverified:      config.DeleteUnverifiedSubscriptions,
+		probeStatus:           config.ProbeStatus,
+		failureBudget:         config.HandlerFailureBudget,
+		handlerDeadLetter:     config.HandlerDeadLetter,
+		errorHandler:          config.ErrorHandler,
+		outbox:                config.Outbox,
+		outboxWake:            make(chan struct{}, 1),
+		outboxPollInterval:    config.OutboxPollInterval,
+		outboxMaxAttempts:     config.OutboxMaxAttempts,
+		outboxLease:           defaultOutboxLease,
+		slowHandlerThreshold:  config.SlowHandlerThreshold,
+		slowResponseThreshold: config.SlowResponseThreshold,
+		maxHandlers:           int64(config.MaxConcurrentHandlers),
+		dispatchMode:          config.DispatchMode,
+		dispatchQueueSize:     config.DispatchQueueSize,
 		VerifiedSubscriptions: make(chan string),
-		handlers:              make(map[string]func(json.RawMessage)),
+		handlers:              make(map[string]ContextHandler),
+		revocationHandlers:    make(map[RevocationReason]func(Subscription)),
 	}
 
-	// Disable logging if debug is false
-	if !c.debug {
-		c.logger.SetOutput(io.Discard)
+	c.background.ctx, c.background.stop = context.WithCancel(context.Background())
+	c.subscriptionCache.ttl = config.SubscriptionCacheTTL
+	c.helixQueue.limit = config.MaxConcurrentHelixRequests
+	c.mode = config.Mode
+	c.twitchCLI = config.TwitchCLI
+	c.verificationRelay = config.VerificationRelay
+	c.secretProvider = config.SecretProvider
+	c.secretRefreshInterval = config.SecretRefreshInterval
+	c.secretRotationWindow = config.SecretRotationWindow
+	if c.secretRotationWindow == 0 {
+		c.secretRotationWindow = defaultSecretRotationWindow
+	}
+	c.helix = config.Helix
+	if c.mode == ModeReceiver {
+		c.helix = receiverHelix{}
+	} else if c.helix == nil {
+		c.helix = helixClient{c}
 	}
 
-	c.logger.Println("Generating token")
-	token, err := c.generateToken(c.clientID, c.clientSecret)
-	if err != nil {
-		return nil, err
+	if c.slowResponseThreshold == 0 {
+		c.slowResponseThreshold = defaultSlowResponseThreshold
 	}
-	c.logger.Println("Token generated")
-	c.token = token
-	go func() {
-		for {
-			time.Sleep(1 * time.Hour)
-			valid, err := c.validateToken(c.token)
-			if err != nil {
-				c.logger.Printf("Could not validate token: %s", err)
-				continue
-			}
-			if !valid {
-				c.logger.Println("Token invalid, generating a new one")
-				token, err := c.generateToken(c.clientID, c.clientSecret)
-				if err != nil {
-					c.logger.Printf("Could not generate token: %s", err)
-					continue
-				}
-				c.token = token
-			}
-		}
-	}()
+	c.stats = newStatsCollector()
+	c.stats.slowResponseThreshold = c.slowResponseThreshold
+	hooks := multiMetricsHook{c.stats}
+	if config.ExpvarMetrics {
+		hooks = append(hooks, newExpvarMetricsHook())
+	}
+	if config.MetricsHook != nil {
+		hooks = append(hooks, config.MetricsHook)
+	}
+	c.metrics = hooks
 
-	return c, nil
+	if c.logger == nil {
+		c.logger = defaultLogger(config.LogLevel, c.debug)
+	}
+	logSampleInterval := config.LogSampleInterval
+	if logSampleInterval == 0 {
+		logSampleInterval = defaultLogSampleInterval
+	}
+	c.sampledLogger = newLogSampler(c.logger, logSampleInterval)
+
+	if c.dispatchQueueSize <= 0 {
+		c.dispatchQueueSize = defaultDispatchQueueSize
+	}
+	if c.outboxPollInterval <= 0 {
+		c.outboxPollInterval = defaultOutboxPollInterval
+	}
+	if c.outboxMaxAttempts <= 0 {
+		c.outboxMaxAttempts = defaultOutboxMaxAttempts
+	}
+	c.trustedProxies = c.parseTrustedProxies(config.TrustedProxies)
+	if c.probeStatus == 0 {
+		c.probeStatus = http.StatusMethodNotAllowed
+	}
+	c.statusPolicy = config.StatusPolicy.withDefaults()
+
+	return c
 }
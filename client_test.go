@@ -0,0 +1,41 @@
+package twitchwh
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDefaultHandledEventsChecker(t *testing.T) {
+	checker := NewDefaultHandledEventsChecker()
+	if checker.IsHandled("a") {
+		t.Fatal("Expected a to not be handled")
+	}
+	checker.MarkHandled("a")
+	checker.MarkHandled("a")
+	if !checker.IsHandled("a") {
+		t.Fatal("Expected a to be handled")
+	}
+	if checker.Len() != 1 {
+		t.Fatalf("Expected 1 handled event, got %d", checker.Len())
+	}
+}
+
+// BenchmarkDefaultHandledEventsChecker measures a lookup and insert with 100,000 events already handled,
+// roughly a day of a busy channel's chat.
+func BenchmarkDefaultHandledEventsChecker(b *testing.B) {
+	checker := NewDefaultHandledEventsChecker()
+	for i := 0; i < 100_000; i++ {
+		checker.MarkHandled(strconv.Itoa(i))
+	}
+	ids := make([]string, b.N)
+	for i := range ids {
+		ids[i] = "new-" + strconv.Itoa(i)
+	}
+
+	b.ResetTimer()
+	for _, id := range ids {
+		if !checker.IsHandled(id) {
+			checker.MarkHandled(id)
+		}
+	}
+}
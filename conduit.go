@@ -0,0 +1,202 @@
+package twitchwh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Conduit is an EventSub conduit. Subscriptions with the conduit transport are load balanced over the conduit's shards.
+// See [https://dev.twitch.tv/docs/eventsub/handling-conduit-events/].
+type Conduit struct {
+	ID         string `json:"id"`
+	ShardCount int    `json:"shard_count"`
+}
+
+// ConduitShard assigns a webhook callback to a shard of a conduit.
+type ConduitShard struct {
+	ID        string `json:"id"`
+	Transport struct {
+		Method   string `json:"method"`
+		Callback string `json:"callback"`
+		Secret   string `json:"secret,omitempty"`
+	} `json:"transport"`
+}
+
+// ConduitShardError is returned by UpdateConduitShards for shards Twitch did not update.
+type ConduitShardError struct {
+	ShardID string `json:"id"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+func (e *ConduitShardError) Error() string {
+	return fmt.Sprintf("Could not update shard %s: %s", e.ShardID, e.Message)
+}
+
+// GetConduits returns the conduits of the application.
+func (c *Client) GetConduits() ([]Conduit, error) {
+	return c.helix.GetConduits(context.Background())
+}
+
+// CreateConduit creates a conduit with the given number of shards.
+func (c *Client) CreateConduit(shardCount int) (Conduit, error) {
+	if err := c.checkLeader(); err != nil {
+		return Conduit{}, err
+	}
+	return c.helix.CreateConduit(context.Background(), shardCount)
+}
+
+// SetConduitShardCount changes the number of shards of a conduit. Shards above the new count are removed.
+func (c *Client) SetConduitShardCount(conduitID string, shardCount int) error {
+	return c.setConduitShardCount(context.Background(), conduitID, shardCount)
+}
+
+func (c *Client) setConduitShardCount(ctx context.Context, conduitID string, shardCount int) error {
+	if err := c.checkLeader(); err != nil {
+		return err
+	}
+	return c.helix.UpdateConduit(ctx, conduitID, shardCount)
+}
+
+// UpdateConduitShards assigns webhook callbacks to shards of a conduit. Shards Twitch could not update are returned
+// as joined ConduitShardErrors.
+func (c *Client) UpdateConduitShards(conduitID string, shards []ConduitShard) error {
+	return c.updateConduitShards(context.Background(), conduitID, shards)
+}
+
+func (c *Client) updateConduitShards(ctx context.Context, conduitID string, shards []ConduitShard) error {
+	if err := c.checkLeader(); err != nil {
+		return err
+	}
+	shardErrors, err := c.helix.UpdateConduitShards(ctx, conduitID, shards)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(shardErrors))
+	for i := range shardErrors {
+		errs[i] = &shardErrors[i]
+	}
+	return errors.Join(errs...)
+}
+
+// ConduitScaler keeps the shard count of a conduit equal to the number of healthy workers, with one shard per worker,
+// so consumers scale horizontally by starting and stopping instances.
+//
+//	scaler := &twitchwh.ConduitScaler{
+//		Client:    client,
+//		ConduitID: conduitID,
+//		Workers: func(ctx context.Context) ([]string, error) {
+//			// Callback URLs of the healthy instances, eg: from service discovery
+//			return discovery.HealthyURLs(ctx, "eventsub-worker")
+//		},
+//	}
+//	go scaler.Run(ctx, 30*time.Second)
+//
+// Every instance can run the scaler, with ClientConfig.Elector set only the leader changes the conduit.
+type ConduitScaler struct {
+	Client    *Client
+	ConduitID string
+	// Returns the webhook callback URLs of the healthy workers. Shards are assigned to them in sorted order,
+	// so a stable set of workers keeps its shards.
+	Workers func(ctx context.Context) ([]string, error)
+
+	last []string
+}
+
+// Reconcile resizes the conduit and assigns its shards to the current workers. Does nothing if the workers didn't
+// change since the last successful call. Returns NotLeaderError on replicas that are not the leader.
+// Its Helix requests have PriorityBackground.
+func (s *ConduitScaler) Reconcile(ctx context.Context) error {
+	if err := s.Client.checkLeader(); err != nil {
+		return err
+	}
+	workers, err := s.Workers(ctx)
+	if err != nil {
+		return err
+	}
+	if len(workers) == 0 {
+		// A conduit needs at least one shard, keep the current ones until workers come back
+		return nil
+	}
+	workers = slices.Clone(workers)
+	slices.Sort(workers)
+	if slices.Equal(workers, s.last) {
+		return nil
+	}
+
+	shards := make([]ConduitShard, len(workers))
+	for i, worker := range workers {
+		shards[i].ID = strconv.Itoa(i)
+		shards[i].Transport.Method = "webhook"
+		shards[i].Transport.Callback = worker
+		shards[i].Transport.Secret = s.Client.GetWebhookSecret()
+	}
+	ctx = withPriority(ctx, PriorityBackground)
+	current, err := s.shardCount(ctx)
+	if err != nil {
+		return err
+	}
+	if len(workers) > current {
+		// New shards must exist before they can be assigned
+		if err := s.Client.setConduitShardCount(ctx, s.ConduitID, len(workers)); err != nil {
+			return err
+		}
+	}
+	if err := s.Client.updateConduitShards(ctx, s.ConduitID, shards); err != nil {
+		return err
+	}
+	if len(workers) < current {
+		// Shrink after reassigning, so remaining events are routed to live workers
+		if err := s.Client.setConduitShardCount(ctx, s.ConduitID, len(workers)); err != nil {
+			return err
+		}
+	}
+	s.Client.logger.Info("Scaled conduit", "conduit_id", s.ConduitID, "shards", len(workers))
+	s.last = workers
+	return nil
+}
+
+// shardCount returns the current shard count of the conduit. Fetched from Helix until the first successful
+// Reconcile, since the conduit may have been resized by another instance or a previous run.
+func (s *ConduitScaler) shardCount(ctx context.Context) (int, error) {
+	if s.last != nil {
+		return len(s.last), nil
+	}
+	conduits, err := s.Client.helix.GetConduits(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, conduit := range conduits {
+		if conduit.ID == s.ConduitID {
+			return conduit.ShardCount, nil
+		}
+	}
+	return 0, fmt.Errorf("conduit %s not found", s.ConduitID)
+}
+
+// Run calls Reconcile every interval until ctx is done. Errors are reported to the ErrorHandler, NotLeaderErrors
+// are ignored.
+func (s *ConduitScaler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := s.Reconcile(ctx)
+		var notLeader *NotLeaderError
+		if err != nil && !errors.As(err, &notLeader) {
+			s.Client.reportError("Could not scale conduit", err, "conduit_id", s.ConduitID)
+		}
+		if errors.As(err, &notLeader) {
+			// Leadership may move to this instance later, start from scratch then
+			s.last = nil
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
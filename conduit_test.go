@@ -0,0 +1,64 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestConduitScaler(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	var requests []string
+	shardCount := 1
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var body struct {
+			ShardCount int            `json:"shard_count"`
+			Shards     []ConduitShard `json:"shards"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path == "/helix/eventsub/conduits/shards" {
+			for _, shard := range body.Shards {
+				requests = append(requests, "shard "+shard.ID+" "+shard.Transport.Callback)
+			}
+			return jsonResponse(202, `{"data":[],"errors":[]}`), nil
+		}
+		if r.Method == http.MethodGet {
+			return jsonResponse(200, `{"data":[{"id":"conduit","shard_count":`+strconv.Itoa(shardCount)+`}]}`), nil
+		}
+		requests = append(requests, "count "+strconv.Itoa(body.ShardCount))
+		return jsonResponse(200, `{"data":[]}`), nil
+	})}
+
+	workers := []string{"https://b.example.com", "https://a.example.com"}
+	scaler := &ConduitScaler{Client: c, ConduitID: "conduit", Workers: func(ctx context.Context) ([]string, error) {
+		return workers, nil
+	}}
+	check := func(expected ...string) {
+		t.Helper()
+		if err := scaler.Reconcile(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if len(requests) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, requests)
+		}
+		for i := range expected {
+			if requests[i] != expected[i] {
+				t.Fatalf("Expected %v, got %v", expected, requests)
+			}
+		}
+		requests = nil
+	}
+
+	check("count 2", "shard 0 https://a.example.com", "shard 1 https://b.example.com")
+	check()
+	workers = []string{"https://b.example.com"}
+	check("shard 0 https://b.example.com", "count 1")
+
+	// A new scaler shrinks a larger conduit only after reassigning its shards
+	shardCount = 3
+	workers = []string{"https://b.example.com", "https://a.example.com"}
+	scaler = &ConduitScaler{Client: c, ConduitID: "conduit", Workers: scaler.Workers}
+	check("shard 0 https://a.example.com", "shard 1 https://b.example.com", "count 2")
+}
@@ -0,0 +1,47 @@
+package twitchwh
+
+import "context"
+
+type contextKey int
+
+const messageMetadataKey contextKey = iota
+
+// messageMetadata identifies the EventSub message a handler is processing.
+type messageMetadata struct {
+	messageID      string
+	subscriptionID string
+	eventType      string
+	replay         bool
+}
+
+func withMessageMetadata(ctx context.Context, metadata messageMetadata) context.Context {
+	return context.WithValue(ctx, messageMetadataKey, metadata)
+}
+
+func messageMetadataFromContext(ctx context.Context) messageMetadata {
+	metadata, _ := ctx.Value(messageMetadataKey).(messageMetadata)
+	return metadata
+}
+
+// MessageIDFromContext returns the Twitch-Eventsub-Message-Id of the notification being handled,
+// or an empty string if ctx was not passed to a handler by the client.
+func MessageIDFromContext(ctx context.Context) string {
+	return messageMetadataFromContext(ctx).messageID
+}
+
+// SubscriptionIDFromContext returns the ID of the subscription the notification being handled belongs to,
+// or an empty string if ctx was not passed to a handler by the client.
+func SubscriptionIDFromContext(ctx context.Context) string {
+	return messageMetadataFromContext(ctx).subscriptionID
+}
+
+// EventTypeFromContext returns the type of the notification being handled (eg: "stream.online"),
+// or an empty string if ctx was not passed to a handler by the client.
+func EventTypeFromContext(ctx context.Context) string {
+	return messageMetadataFromContext(ctx).eventType
+}
+
+// IsReplayFromContext reports whether the notification being handled is re-dispatched by Client.Replay.
+func IsReplayFromContext(ctx context.Context) bool {
+	return messageMetadataFromContext(ctx).replay
+}
@@ -0,0 +1,53 @@
+package twitchwh
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// defaultSlowResponseThreshold is the default of ClientConfig.SlowResponseThreshold, well below the few seconds
+// Twitch waits for a response.
+const defaultSlowResponseThreshold = 2 * time.Second
+
+// respond writes the status code of a webhook response and reports the time since the request was received.
+func (c *Client) respond(w http.ResponseWriter, start time.Time, messageType string, status int) {
+	w.WriteHeader(status)
+	latency := time.Since(start)
+	c.metrics.ResponseWritten(messageType, status, latency)
+	if c.slowResponseThreshold > 0 && latency > c.slowResponseThreshold && isMessageType(messageType) {
+		c.sampledLogger.log("slow-response:"+messageType, slog.LevelWarn, "Slow response, Twitch revokes subscriptions whose notifications time out",
+			"message_type", messageType, "status", status, "latency", latency, "threshold", c.slowResponseThreshold)
+	}
+}
+
+// acceptNotificationBefore runs acceptNotification, but returns 204 at deadline if it hasn't finished yet, eg:
+// because the outbox or HandledEventsChecker is slow. The notification is still accepted in the background.
+func (c *Client) acceptNotificationBefore(ctx context.Context, notification Notification, body []byte, deadline time.Time) int {
+	// The request context is cancelled once the response is written, accepting must outlive it
+	ctx = context.WithoutCancel(ctx)
+	result := make(chan int, 1)
+	go func() {
+		result <- c.acceptNotification(ctx, notification, body)
+	}()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case status := <-result:
+		return status
+	case <-timer.C:
+		c.sampledLogger.log("deadline", slog.LevelWarn, "Response deadline reached, acknowledging notification before it was accepted",
+			"type", notification.Subscription.Type, "message_id", notification.MessageID)
+		go func() {
+			// Twitch won't redeliver the notification, so a failure at this point loses it
+			if status := <-result; status >= 300 {
+				c.reportError("Notification acknowledged at the response deadline could not be accepted", fmt.Errorf("status %d", status),
+					"type", notification.Subscription.Type, "message_id", notification.MessageID)
+			}
+		}()
+		return 204
+	}
+}
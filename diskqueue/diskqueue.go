@@ -0,0 +1,373 @@
+// Package diskqueue provides a twitchwh.Outbox backed by an append-only log on local disk,
+// for single-node deployments that want durable buffering between the webhook handler and handler execution
+// without running a database.
+//
+//	queue, err := diskqueue.Open("/var/lib/myapp/eventsub", diskqueue.Options{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer queue.Close()
+//	client, _ := twitchwh.New(twitchwh.ClientConfig{
+//		// ...
+//		Outbox: queue,
+//	})
+//
+// Every change (store, complete, fail) is appended to the log as a checksummed record, and the pending entries are
+// rebuilt from the log when it is opened. Entry IDs are the offsets of their store records in the log sequence.
+// The log is compacted on open, and whenever enough entries were completed.
+package diskqueue
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+const logName = "queue.log"
+
+// Options configures a Queue.
+type Options struct {
+	// Skip fsync after appending records. Faster, but records may be lost if the machine (not just the process) crashes.
+	NoSync bool
+	// Compact the log once this many completed entries are in it. Defaults to 10000.
+	CompactAfter int
+}
+
+// ErrClosed is returned by operations on a closed queue.
+var ErrClosed = errors.New("diskqueue: queue is closed")
+
+type op byte
+
+const (
+	opStore op = iota + 1
+	opComplete
+	opFail
+)
+
+type record struct {
+	Op           op                     `json:"op"`
+	ID           int64                  `json:"id"`
+	Attempts     int                    `json:"attempts,omitempty"`
+	AvailableAt  int64                  `json:"available_at,omitempty"`
+	Dead         bool                   `json:"dead,omitempty"`
+	Notification *twitchwh.Notification `json:"notification,omitempty"`
+}
+
+type entry struct {
+	id           int64
+	notification twitchwh.Notification
+	attempts     int
+	availableAt  time.Time
+	leasedUntil  time.Time
+	dead         bool
+}
+
+// Queue is a disk-backed twitchwh.Outbox.
+type Queue struct {
+	dir     string
+	options Options
+
+	mu         sync.Mutex
+	file       *os.File
+	writer     *bufio.Writer
+	nextID     int64
+	entries    map[int64]*entry
+	messageIDs map[string]int64
+	completed  int
+	closed     bool
+}
+
+// Open opens (or creates) the queue in dir.
+func Open(dir string, options Options) (*Queue, error) {
+	if options.CompactAfter <= 0 {
+		options.CompactAfter = 10000
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	q := &Queue{
+		dir:        dir,
+		options:    options,
+		nextID:     1,
+		entries:    make(map[int64]*entry),
+		messageIDs: make(map[string]int64),
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	if err := q.compact(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// load replays the log. A truncated or corrupt record at the end (from a crash mid-write) ends the replay.
+func (q *Queue) load() error {
+	file, err := os.Open(filepath.Join(q.dir, logName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		rec, err := readRecord(reader)
+		if err != nil {
+			return nil
+		}
+		q.apply(rec)
+	}
+}
+
+func (q *Queue) apply(rec record) {
+	switch rec.Op {
+	case opStore:
+		if rec.Notification == nil {
+			return
+		}
+		e := &entry{
+			id:           rec.ID,
+			notification: *rec.Notification,
+			attempts:     rec.Attempts,
+			availableAt:  time.UnixMilli(rec.AvailableAt),
+			dead:         rec.Dead,
+		}
+		q.entries[rec.ID] = e
+		q.messageIDs[e.notification.MessageID] = rec.ID
+		q.nextID = max(q.nextID, rec.ID+1)
+	case opComplete:
+		if e, ok := q.entries[rec.ID]; ok {
+			delete(q.messageIDs, e.notification.MessageID)
+			delete(q.entries, rec.ID)
+			q.completed++
+		}
+	case opFail:
+		if e, ok := q.entries[rec.ID]; ok {
+			e.attempts = rec.Attempts
+			e.availableAt = time.UnixMilli(rec.AvailableAt)
+			e.dead = rec.Dead
+		}
+	}
+}
+
+func readRecord(r io.Reader) (record, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return record{}, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	checksum := binary.BigEndian.Uint32(header[4:])
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return record{}, err
+	}
+	if crc32.ChecksumIEEE(data) != checksum {
+		return record{}, errors.New("checksum mismatch")
+	}
+	var rec record
+	err := json.Unmarshal(data, &rec)
+	return rec, err
+}
+
+func appendRecord(w io.Writer, rec record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(header[4:], crc32.ChecksumIEEE(data))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// compact rewrites the log with only the pending entries and reopens it for appending.
+func (q *Queue) compact() error {
+	if q.file != nil {
+		if err := q.writer.Flush(); err != nil {
+			return err
+		}
+		q.file.Close()
+	}
+
+	tmpPath := filepath.Join(q.dir, logName+".tmp")
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, e := range q.sortedEntries() {
+		n := e.notification
+		err := appendRecord(writer, record{Op: opStore, ID: e.id, Attempts: e.attempts, AvailableAt: e.availableAt.UnixMilli(), Dead: e.dead, Notification: &n})
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+	if err := os.Rename(tmpPath, filepath.Join(q.dir, logName)); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Join(q.dir, logName), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	q.file = file
+	q.writer = bufio.NewWriter(file)
+	q.completed = 0
+	return nil
+}
+
+func (q *Queue) sortedEntries() []*entry {
+	entries := make([]*entry, 0, len(q.entries))
+	for _, e := range q.entries {
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b *entry) int {
+		return cmp.Compare(a.id, b.id)
+	})
+	return entries
+}
+
+// write appends a record and flushes it to disk.
+func (q *Queue) write(rec record) error {
+	if err := appendRecord(q.writer, rec); err != nil {
+		return err
+	}
+	if err := q.writer.Flush(); err != nil {
+		return err
+	}
+	if q.options.NoSync {
+		return nil
+	}
+	return q.file.Sync()
+}
+
+// Store implements twitchwh.Outbox.
+func (q *Queue) Store(ctx context.Context, n twitchwh.Notification) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	if _, ok := q.messageIDs[n.MessageID]; ok {
+		return nil
+	}
+	rec := record{Op: opStore, ID: q.nextID, AvailableAt: time.Now().UnixMilli(), Notification: &n}
+	if err := q.write(rec); err != nil {
+		return err
+	}
+	q.nextID++
+	q.apply(rec)
+	return nil
+}
+
+// Claim implements twitchwh.Outbox.
+func (q *Queue) Claim(ctx context.Context, limit int, lease time.Duration) ([]twitchwh.OutboxEntry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, ErrClosed
+	}
+	now := time.Now()
+	var claimed []twitchwh.OutboxEntry
+	for _, e := range q.sortedEntries() {
+		if len(claimed) >= limit {
+			break
+		}
+		if e.dead || e.availableAt.After(now) || e.leasedUntil.After(now) {
+			continue
+		}
+		e.attempts++
+		e.leasedUntil = now.Add(lease)
+		claimed = append(claimed, twitchwh.OutboxEntry{ID: e.id, Attempts: e.attempts, Notification: e.notification})
+	}
+	return claimed, nil
+}
+
+// Complete implements twitchwh.Outbox.
+func (q *Queue) Complete(ctx context.Context, id int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	rec := record{Op: opComplete, ID: id}
+	if err := q.write(rec); err != nil {
+		return err
+	}
+	q.apply(rec)
+	if q.completed >= q.options.CompactAfter {
+		return q.compact()
+	}
+	return nil
+}
+
+// Fail implements twitchwh.Outbox.
+func (q *Queue) Fail(ctx context.Context, id int64, cause error, retryAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	e, ok := q.entries[id]
+	if !ok {
+		return nil
+	}
+	rec := record{Op: opFail, ID: id, Attempts: e.attempts, AvailableAt: retryAt.UnixMilli(), Dead: retryAt.IsZero()}
+	if err := q.write(rec); err != nil {
+		return err
+	}
+	q.apply(rec)
+	e.leasedUntil = time.Time{}
+	return nil
+}
+
+// Len returns the number of pending entries, including entries that were given up on.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// Close flushes and closes the log.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	if err := q.writer.Flush(); err != nil {
+		q.file.Close()
+		return err
+	}
+	return q.file.Close()
+}
@@ -0,0 +1,51 @@
+package diskqueue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestQueueSurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	queue, err := Open(dir, Options{NoSync: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c", "a"} {
+		if err := queue.Store(ctx, twitchwh.Notification{MessageID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, _ := queue.Claim(ctx, 10, time.Minute)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	queue.Complete(ctx, entries[0].ID)
+	queue.Fail(ctx, entries[1].ID, errors.New("handler failed"), time.Now().Add(time.Hour))
+	queue.Close()
+
+	// Simulate a crash in the middle of appending a record
+	file, _ := os.OpenFile(filepath.Join(dir, logName), os.O_WRONLY|os.O_APPEND, 0o644)
+	file.Write([]byte{0, 0, 1})
+	file.Close()
+
+	queue, err = Open(dir, Options{NoSync: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queue.Close()
+	if queue.Len() != 2 {
+		t.Fatalf("Expected 2 pending entries, got %d", queue.Len())
+	}
+	entries, _ = queue.Claim(ctx, 10, time.Minute)
+	if len(entries) != 1 || entries[0].Notification.MessageID != "c" {
+		t.Fatalf("Expected only c to be due, got %+v", entries)
+	}
+}
@@ -0,0 +1,155 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"runtime/pprof"
+	"time"
+)
+
+// ContextHandler handles the event body of a notification.
+// ctx carries the message metadata, see MessageIDFromContext, SubscriptionIDFromContext, and EventTypeFromContext.
+// A returned error is reported to the ErrorHandler and MetricsHook.HandlerFinished.
+type ContextHandler func(ctx context.Context, event json.RawMessage) error
+
+// OnContext is like On, but the handler receives a context with the message metadata and can return an error.
+//
+//	client.OnContext("stream.online", func(ctx context.Context, event json.RawMessage) error {
+//		return db.SaveEvent(ctx, twitchwh.MessageIDFromContext(ctx), event)
+//	})
+func (c *Client) OnContext(event string, handler ContextHandler) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.handlers[event] = handler
+	c.budgets.enable(event)
+}
+
+func (c *Client) handler(eventType string) (ContextHandler, bool) {
+	c.handlersMu.RLock()
+	defer c.handlersMu.RUnlock()
+	handler, ok := c.handlers[eventType]
+	return handler, ok
+}
+
+// handleNotification runs the handler and sinks for a notification in the background.
+// Returns false if the notification was not accepted because the queue of its event type is full, see DispatchWorkers.
+func (c *Client) handleNotification(n Notification) bool {
+	if b, ok := c.batcher(n.Subscription.Type); ok {
+		c.publish(n)
+		b.add(n, nil)
+		return true
+	}
+	handler, ok := c.notificationHandler(n)
+	if !ok {
+		c.publish(n)
+		c.sampledLogger.log("no-handler:"+n.Subscription.Type, slog.LevelDebug, "No handler for event", "type", n.Subscription.Type)
+		return true
+	}
+	if c.dispatchMode == DispatchWorkers {
+		// Never blocks, so a slow handler can't delay the responses to Twitch
+		if !c.enqueue(n) {
+			return false
+		}
+		c.publish(n)
+		return true
+	}
+	c.publish(n)
+	c.runningHandlers.Add(1)
+	go func() {
+		defer c.runningHandlers.Add(-1)
+		c.dispatch(n, handler)
+	}()
+	return true
+}
+
+// saturated reports whether MaxConcurrentHandlers handlers are running, or the queue of the event type is full.
+func (c *Client) saturated(eventType string) bool {
+	return (c.maxHandlers > 0 && c.runningHandlers.Load() >= c.maxHandlers) || c.queueFull(eventType)
+}
+
+// processNotification runs the handler and sinks for a notification synchronously, and returns their errors.
+// Notifications of types registered with OnBatch are added to the batch without waiting for it.
+func (c *Client) processNotification(n Notification) error {
+	var errs []error
+	if b, ok := c.batcher(n.Subscription.Type); ok {
+		b.add(n, nil)
+	} else if handler, ok := c.notificationHandler(n); ok {
+		errs = append(errs, c.dispatch(n, handler))
+	}
+	c.sinks.mu.RLock()
+	entries := c.sinks.entries
+	c.sinks.mu.RUnlock()
+	errs = append(errs, c.deliver(entries, n))
+	return errors.Join(errs...)
+}
+
+// dispatch runs an event handler, recovering panics and reporting the result to the metrics hook.
+// The handler runs with pprof labels for the event type and subscription ID, so profiles can be attributed to them.
+func (c *Client) dispatch(n Notification, handler ContextHandler) error {
+	return c.dispatchWithMetadata(n, handler, false)
+}
+
+func (c *Client) dispatchWithMetadata(n Notification, handler ContextHandler, replay bool) (err error) {
+	if c.failureBudget > 0 && c.budgets.isDisabled(n.Subscription.Type) {
+		c.deadLetterHandler(n, &HandlerDisabledError{Type: n.Subscription.Type})
+		return nil
+	}
+	retry := c.responsePolicy(n.Subscription.Type).Retry
+	labels := pprof.Labels("twitchwh_event_type", n.Subscription.Type, "twitchwh_subscription_id", n.Subscription.ID)
+	pprof.Do(context.Background(), labels, func(ctx context.Context) {
+		ctx = withMessageMetadata(ctx, messageMetadata{
+			messageID:      n.MessageID,
+			subscriptionID: n.Subscription.ID,
+			eventType:      n.Subscription.Type,
+			replay:         replay,
+		})
+		for attempt := 1; ; attempt++ {
+			err = c.runHandler(ctx, n, handler)
+			if err == nil || attempt >= retry.MaxAttempts {
+				break
+			}
+			// Retries are given up once the client is closed
+			if sleepContext(c.background.ctx, retry.backoff(attempt)) != nil {
+				break
+			}
+		}
+	})
+	if err != nil {
+		c.reportHandlerError(n, err)
+	}
+	c.recordHandlerResult(n.Subscription.Type, err)
+	if err != nil && retry.MaxAttempts > 1 {
+		c.deadLetterHandler(n, err)
+	}
+	return err
+}
+
+// reportHandlerError reports the error of a handler after its last attempt.
+func (c *Client) reportHandlerError(n Notification, err error) {
+	var panicErr *HandlerPanicError
+	message := "Handler failed"
+	if errors.As(err, &panicErr) {
+		message = "Handler panicked"
+	}
+	c.reportError(message, &HandlerError{n, err}, "type", n.Subscription.Type, "message_id", n.MessageID)
+}
+
+// runHandler runs a handler once, converting a panic into a HandlerPanicError.
+func (c *Client) runHandler(ctx context.Context, n Notification, handler ContextHandler) (err error) {
+	eventType := n.Subscription.Type
+	start := time.Now()
+	defer func() {
+		if v := recover(); v != nil {
+			err = &HandlerPanicError{Type: eventType, Value: v}
+		}
+		duration := time.Since(start)
+		c.metrics.HandlerFinished(eventType, duration, err)
+		if c.slowHandlerThreshold > 0 && duration > c.slowHandlerThreshold {
+			c.logger.Warn("Slow handler", "type", eventType, "duration", duration, "threshold", c.slowHandlerThreshold)
+			c.metrics.SlowHandler(eventType, duration)
+		}
+	}()
+	return handler(ctx, n.Event)
+}
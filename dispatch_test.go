@@ -0,0 +1,65 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestErrorHandler(t *testing.T) {
+	var reported []error
+	c := newClient(ClientConfig{ErrorHandler: func(err error) {
+		reported = append(reported, err)
+	}})
+	failure := errors.New("database is down")
+	c.OnContext("stream.online", func(ctx context.Context, event json.RawMessage) error {
+		return failure
+	})
+	c.On("stream.offline", func(event json.RawMessage) {
+		panic("nil map")
+	})
+
+	online := Notification{MessageID: "a", Subscription: Subscription{Type: "stream.online"}}
+	offline := Notification{MessageID: "b", Subscription: Subscription{Type: "stream.offline"}}
+	for _, n := range []Notification{online, offline} {
+		if err := c.processNotification(n); err == nil {
+			t.Fatalf("Expected %s to fail", n.Subscription.Type)
+		}
+	}
+
+	if len(reported) != 2 {
+		t.Fatalf("Expected 2 reported errors, got %v", reported)
+	}
+	var internalErr *InternalError
+	var handlerErr *HandlerError
+	if !errors.As(reported[0], &internalErr) || !errors.As(reported[0], &handlerErr) {
+		t.Fatalf("Expected an InternalError with a HandlerError, got %T: %v", reported[0], reported[0])
+	}
+	if handlerErr.Notification.MessageID != "a" || !errors.Is(reported[0], failure) {
+		t.Fatalf("Expected the failed notification and the handler's error, got %+v", handlerErr)
+	}
+	var panicErr *HandlerPanicError
+	if !errors.As(reported[1], &handlerErr) || handlerErr.Notification.MessageID != "b" || !errors.As(reported[1], &panicErr) {
+		t.Fatalf("Expected the panicked notification and a HandlerPanicError, got %v", reported[1])
+	}
+}
+
+func TestDispatchProfilerLabels(t *testing.T) {
+	c := newClient(ClientConfig{})
+	var eventType, subscriptionID string
+	c.OnContext("stream.online", func(ctx context.Context, event json.RawMessage) error {
+		eventType, _ = pprof.Label(ctx, "twitchwh_event_type")
+		subscriptionID, _ = pprof.Label(ctx, "twitchwh_subscription_id")
+		return nil
+	})
+
+	n := Notification{MessageID: "a", Subscription: Subscription{ID: "f1c2a387", Type: "stream.online"}}
+	if err := c.processNotification(n); err != nil {
+		t.Fatal(err)
+	}
+	if eventType != "stream.online" || subscriptionID != "f1c2a387" {
+		t.Fatalf("Expected labels for stream.online and f1c2a387, got %q and %q", eventType, subscriptionID)
+	}
+}
@@ -0,0 +1,89 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// notificationEnvelope is the JSON form of a Notification. Its layout is stable across versions, so sinks, outboxes,
+// and audit logs written by one version can be read by another. Fields are only ever added.
+type notificationEnvelope struct {
+	Metadata     envelopeMetadata `json:"metadata"`
+	Subscription Subscription     `json:"subscription"`
+	Event        json.RawMessage  `json:"event"`
+}
+
+type envelopeMetadata struct {
+	MessageID           string    `json:"message_id"`
+	MessageType         string    `json:"message_type"`
+	MessageTimestamp    time.Time `json:"message_timestamp"`
+	SubscriptionType    string    `json:"subscription_type"`
+	SubscriptionVersion string    `json:"subscription_version"`
+}
+
+// legacyNotification is the JSON form of a Notification before the envelope, kept so older records can be read.
+type legacyNotification struct {
+	MessageID    string          `json:"message_id"`
+	Timestamp    time.Time       `json:"timestamp"`
+	Subscription Subscription    `json:"subscription"`
+	Event        json.RawMessage `json:"event"`
+}
+
+// MarshalJSON encodes the notification as a stable envelope of its metadata, subscription, and event:
+//
+//	{
+//		"metadata": {"message_id": "...", "message_type": "notification", "message_timestamp": "...",
+//			"subscription_type": "channel.follow", "subscription_version": "2"},
+//		"subscription": {...},
+//		"event": {...}
+//	}
+func (n Notification) MarshalJSON() ([]byte, error) {
+	return json.Marshal(notificationEnvelope{
+		Metadata: envelopeMetadata{
+			MessageID:           n.MessageID,
+			MessageType:         messageTypeNotification,
+			MessageTimestamp:    n.Timestamp,
+			SubscriptionType:    n.Subscription.Type,
+			SubscriptionVersion: n.Subscription.Version,
+		},
+		Subscription: n.Subscription,
+		Event:        n.Event,
+	})
+}
+
+// UnmarshalJSON decodes a notification encoded by MarshalJSON. Notifications encoded by earlier versions, with the
+// message ID and timestamp at the top level, are decoded as well.
+func (n *Notification) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["metadata"]; !ok {
+		var legacy legacyNotification
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return err
+		}
+		*n = Notification(legacy)
+		return nil
+	}
+
+	var envelope notificationEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+	*n = Notification{
+		MessageID:    envelope.Metadata.MessageID,
+		Timestamp:    envelope.Metadata.MessageTimestamp,
+		Subscription: envelope.Subscription,
+		Event:        envelope.Event,
+	}
+	return nil
+}
+
+// UnmarshalNotification decodes a notification exported with json.Marshal, eg: by a sink or audit log, for replay
+// tooling. See Notification.MarshalJSON.
+func UnmarshalNotification(data []byte) (Notification, error) {
+	var n Notification
+	err := json.Unmarshal(data, &n)
+	return n, err
+}
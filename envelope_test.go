@@ -0,0 +1,60 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNotificationJSON(t *testing.T) {
+	n := Notification{
+		MessageID:    "a",
+		Timestamp:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Subscription: Subscription{ID: "1", Type: "channel.follow", Version: "2", Condition: Condition{BroadcasterUserID: "1234"}},
+		Event:        json.RawMessage(`{"user_id":"5678"}`),
+	}
+	data, err := json.Marshal(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope struct {
+		Metadata map[string]string `json:"metadata"`
+		Event    json.RawMessage   `json:"event"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"message_id":           "a",
+		"message_type":         "notification",
+		"message_timestamp":    "2024-05-01T12:00:00Z",
+		"subscription_type":    "channel.follow",
+		"subscription_version": "2",
+	}
+	if !reflect.DeepEqual(envelope.Metadata, expected) {
+		t.Fatalf("Unexpected metadata %v", envelope.Metadata)
+	}
+
+	decoded, err := UnmarshalNotification(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, n) {
+		t.Fatalf("Expected %+v, got %+v", n, decoded)
+	}
+}
+
+func TestUnmarshalLegacyNotification(t *testing.T) {
+	n, err := UnmarshalNotification([]byte(`{"message_id":"a","timestamp":"2024-05-01T12:00:00Z",` +
+		`"subscription":{"type":"stream.online"},"event":{}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.MessageID != "a" || n.Subscription.Type != "stream.online" || !n.Timestamp.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Unexpected notification %+v", n)
+	}
+	if _, err := UnmarshalNotification([]byte(`[]`)); err == nil {
+		t.Fatal("Expected an error for a non-object")
+	}
+}
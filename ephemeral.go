@@ -0,0 +1,73 @@
+package twitchwh
+
+import (
+	"errors"
+	"sync"
+)
+
+// ephemeralSubscriptions holds the IDs of the subscriptions created by this client.
+// They are deleted by Close in ephemeral mode, and count as owned by OwnsSubscription.
+type ephemeralSubscriptions struct {
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+func (e *ephemeralSubscriptions) add(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ids == nil {
+		e.ids = make(map[string]struct{})
+	}
+	e.ids[id] = struct{}{}
+}
+
+func (e *ephemeralSubscriptions) remove(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.ids, id)
+}
+
+func (e *ephemeralSubscriptions) contains(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.ids[id]
+	return ok
+}
+
+func (e *ephemeralSubscriptions) list() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ids := make([]string, 0, len(e.ids))
+	for id := range e.ids {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Close deletes every subscription created by this client if ClientConfig.Ephemeral is set, then stops the background
+// goroutines of the client (token validation, outbox dispatch, the verification relay, secret refresh, the watchdog,
+// and sink workers) and waits for them to return. Subscriptions that were already deleted or revoked are skipped.
+// Notifications still queued for sinks are dropped, call FlushSinks first.
+//
+//	client, _ := twitchwh.New(twitchwh.ClientConfig{..., Ephemeral: true})
+//	defer client.Close()
+//
+// Returns the errors of the subscriptions that could not be deleted, they are deleted on the next call.
+func (c *Client) Close() error {
+	defer c.stopBackground()
+	if !c.ephemeral {
+		return nil
+	}
+	var errs []error
+	for _, id := range c.created.list() {
+		err := c.RemoveSubscription(id)
+		var nfErr *SubscriptionNotFoundError
+		if err != nil && !errors.As(err, &nfErr) {
+			c.reportError("Could not delete ephemeral subscription", err, "subscription_id", id)
+			errs = append(errs, err)
+			continue
+		}
+		c.created.remove(id)
+	}
+	return errors.Join(errs...)
+}
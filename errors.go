@@ -1,6 +1,9 @@
 package twitchwh
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Helix returned an authorization error. This usually means the token, Client-ID, or client secret are invalid.
 type UnauthorizedError struct{}
@@ -31,6 +34,50 @@ func (e *DuplicateSubscriptionError) Error() string {
 	return "Duplicate subscription"
 }
 
+// Helix rejected a subscription because its type or version is not supported, usually because of a typo.
+// The known types and versions come from the catalog of this package, see SubscriptionVersions.
+type UnsupportedTypeError struct {
+	Type    string
+	Version string
+	// Message returned by Helix.
+	Message string
+	// Versions of Type, if the type is known.
+	KnownVersions []string
+	// Known types closest to Type, if the type is unknown.
+	ClosestTypes []string
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	message := fmt.Sprintf("Unsupported subscription type %s version %s", e.Type, e.Version)
+	if len(e.KnownVersions) > 0 {
+		return message + ", supported versions: " + strings.Join(e.KnownVersions, ", ")
+	}
+	if len(e.ClosestTypes) > 0 {
+		return message + ", did you mean " + strings.Join(e.ClosestTypes, " or ") + "?"
+	}
+	return message
+}
+
+// Attempted to add a subscription with a condition field its type doesn't take, eg: a reward_id on channel.follow.
+type InvalidConditionError struct {
+	Type string
+	// JSON name of the condition field.
+	Field string
+}
+
+func (e *InvalidConditionError) Error() string {
+	return e.Field + " is not a condition of " + e.Type
+}
+
+// Attempted an operation the client's Mode does not support, eg: creating a subscription with a ModeReceiver client.
+type ModeError struct {
+	Mode Mode
+}
+
+func (e *ModeError) Error() string {
+	return "Not supported by a client in " + e.Mode.String() + " mode"
+}
+
 // Could not find a subscription with the specified parameters.
 type SubscriptionNotFoundError struct{}
 
@@ -39,12 +86,105 @@ func (e *SubscriptionNotFoundError) Error() string {
 }
 
 // Returned whenever AddSubscription times out waiting for verification confirmation.
+// The fields describe what the client saw while waiting, to tell an unreachable endpoint from a misrouted one.
 type VerificationTimeoutError struct {
 	Subscription Subscription
+	// Status of the subscription fetched from Helix after the timeout, eg: webhook_callback_verification_failed.
+	// Empty if it could not be fetched, or the subscription no longer exists.
+	RemoteStatus string
+	// A challenge for a different subscription was answered while waiting, so the endpoint is reachable.
+	// The challenge may have been answered by another instance sharing the webhook URL.
+	OtherChallengeReceived bool
+	// This client has not answered a single challenge since it was created. Usually means Twitch can't reach the
+	// webhook URL, or the handler isn't mounted at it. Always false in ModeAPI, where Client.Handler doesn't serve the
+	// webhook URL.
+	NoChallengesAnswered bool
+	// The subscription was deleted after the timeout, see ClientConfig.DeleteUnverifiedSubscriptions.
+	Deleted bool
 }
 
 func (e *VerificationTimeoutError) Error() string {
-	return "Subscription was not verified within timeout duration"
+	message := "Subscription was not verified within timeout duration"
+	if e.RemoteStatus != "" {
+		message += " (status " + e.RemoteStatus + ")"
+	}
+	if e.NoChallengesAnswered {
+		message += ": no challenges were answered, check that the webhook URL reaches Client.Handler"
+	} else if e.OtherChallengeReceived {
+		message += ": challenges for other subscriptions were answered, the challenge may have reached another instance"
+	}
+	return message
+}
+
+// A handler panicked while processing an event. Reported to MetricsHook.HandlerFinished.
+type HandlerPanicError struct {
+	// Event type the handler was registered for.
+	Type string
+	// Value passed to panic.
+	Value any
+}
+
+func (e *HandlerPanicError) Error() string {
+	return fmt.Sprintf("handler for %s panicked: %v", e.Type, e.Value)
+}
+
+// A handler returned an error or panicked. Passed to ClientConfig.ErrorHandler wrapped in an InternalError, use
+// errors.As to get the notification.
+type HandlerError struct {
+	// Notification the handler failed on.
+	Notification Notification
+	// Error returned by the handler, or a HandlerPanicError.
+	Err error
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler for %s failed on message %s: %v", e.Notification.Subscription.Type, e.Notification.MessageID, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// A handler registered with OnEvent or OnEventContext received an event that could not be decoded into its type.
+// See Client.OnDecodeError.
+type EventDecodeError struct {
+	// Event type the handler was registered for.
+	Type string
+	// Error returned by json.Unmarshal.
+	Err error
+}
+
+func (e *EventDecodeError) Error() string {
+	return fmt.Sprintf("could not decode %s event: %v", e.Type, e.Err)
+}
+
+func (e *EventDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// The handler for an event type was disabled after exceeding ClientConfig.HandlerFailureBudget.
+// Passed to ClientConfig.HandlerDeadLetter with the notifications it didn't process.
+type HandlerDisabledError struct {
+	// Event type of the disabled handler.
+	Type string
+}
+
+func (e *HandlerDisabledError) Error() string {
+	return fmt.Sprintf("handler for %s is disabled", e.Type)
+}
+
+// A notification was dropped because the sink's queue was full. Passed to the sink's DeadLetter.
+type SinkQueueFullError struct{}
+
+func (e *SinkQueueFullError) Error() string {
+	return "Sink queue is full"
+}
+
+// Returned when changing subscriptions on an instance that is not the leader, see ClientConfig.Elector.
+type NotLeaderError struct{}
+
+func (e *NotLeaderError) Error() string {
+	return "This instance is not the leader"
 }
 
 // Returned for misc errors, like network or serialization errors for example.
@@ -57,3 +197,7 @@ type InternalError struct {
 func (e *InternalError) Error() string {
 	return fmt.Sprintf("%s: %s", e.message, e.OriginalError)
 }
+
+func (e *InternalError) Unwrap() error {
+	return e.OriginalError
+}
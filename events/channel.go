@@ -0,0 +1,16 @@
+package events
+
+// ChannelUpdateEvent is the event of a channel.update notification, version 2.
+type ChannelUpdateEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The channel's stream title.
+	Title string `json:"title"`
+	// The channel's broadcast language, an ISO 639-1 code.
+	Language     string `json:"language"`
+	CategoryID   string `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	// The content classification labels of the channel, eg: "Gambling".
+	ContentClassificationLabels []string `json:"content_classification_labels"`
+}
@@ -0,0 +1,139 @@
+package events
+
+import "time"
+
+// ChannelPointsCustomRewardEvent is the event of channel.channel_points_custom_reward.add, .update, and .remove
+// notifications, version 1. It is the reward after the change.
+type ChannelPointsCustomRewardEvent struct {
+	// The ID of the reward.
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	IsEnabled            bool   `json:"is_enabled"`
+	IsPaused             bool   `json:"is_paused"`
+	IsInStock            bool   `json:"is_in_stock"`
+	Title                string `json:"title"`
+	// The cost of the reward in channel points.
+	Cost int `json:"cost"`
+	// The description shown to viewers.
+	Prompt string `json:"prompt"`
+	// Whether viewers have to enter text to redeem the reward.
+	IsUserInputRequired bool `json:"is_user_input_required"`
+	// Whether redemptions are set to fulfilled immediately.
+	ShouldRedemptionsSkipRequestQueue bool        `json:"should_redemptions_skip_request_queue"`
+	MaxPerStream                      RewardLimit `json:"max_per_stream"`
+	MaxPerUserPerStream               RewardLimit `json:"max_per_user_per_stream"`
+	BackgroundColor                   string      `json:"background_color"`
+	// The custom image of the reward, nil if the broadcaster didn't upload one.
+	Image *RewardImage `json:"image"`
+	// The image used if Image is nil.
+	DefaultImage   RewardImage    `json:"default_image"`
+	GlobalCooldown RewardCooldown `json:"global_cooldown"`
+	// When the cooldown ends, nil if the reward isn't on cooldown.
+	CooldownExpiresAt *time.Time `json:"cooldown_expires_at"`
+	// The number of redemptions in the current stream, nil if the stream isn't live or MaxPerStream isn't enabled.
+	RedemptionsRedeemedCurrentStream *int `json:"redemptions_redeemed_current_stream"`
+}
+
+// RewardLimit is the maximum number of redemptions of a reward.
+type RewardLimit struct {
+	IsEnabled bool `json:"is_enabled"`
+	Value     int  `json:"value"`
+}
+
+// RewardCooldown is the cooldown between redemptions of a reward.
+type RewardCooldown struct {
+	IsEnabled bool `json:"is_enabled"`
+	Seconds   int  `json:"seconds"`
+}
+
+// RewardImage is the image of a reward in three sizes.
+type RewardImage struct {
+	URL1x string `json:"url_1x"`
+	URL2x string `json:"url_2x"`
+	URL4x string `json:"url_4x"`
+}
+
+// ChannelPointsCustomRewardRedemptionEvent is the event of channel.channel_points_custom_reward_redemption.add and
+// .update notifications, version 1.
+type ChannelPointsCustomRewardRedemptionEvent struct {
+	// The ID of the redemption.
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The user who redeemed the reward.
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+	// The text the user entered, if the reward requires it.
+	UserInput string `json:"user_input"`
+	// One of "unknown", "unfulfilled", "fulfilled", or "canceled".
+	Status     string           `json:"status"`
+	Reward     RedemptionReward `json:"reward"`
+	RedeemedAt time.Time        `json:"redeemed_at"`
+}
+
+// RedemptionReward is the reward of a redemption.
+type RedemptionReward struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Cost   int    `json:"cost"`
+	Prompt string `json:"prompt"`
+}
+
+// ChannelPointsAutomaticRewardRedemptionEvent is the event of a channel.channel_points_automatic_reward_redemption.add
+// notification, version 2.
+type ChannelPointsAutomaticRewardRedemptionEvent struct {
+	// The ID of the redemption.
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The user who redeemed the reward.
+	UserID    string          `json:"user_id"`
+	UserLogin string          `json:"user_login"`
+	UserName  string          `json:"user_name"`
+	Reward    AutomaticReward `json:"reward"`
+	// The message sent with the reward, eg: for "send_highlighted_message". Empty for other rewards.
+	Message    AutomaticRewardMessage `json:"message"`
+	RedeemedAt time.Time              `json:"redeemed_at"`
+}
+
+// AutomaticReward is the reward of an automatic reward redemption.
+type AutomaticReward struct {
+	// One of "single_message_bypass_sub_mode", "send_highlighted_message", "random_sub_emote_unlock",
+	// "chosen_sub_emote_unlock", or "chosen_modified_sub_emote_unlock".
+	Type string `json:"type"`
+	// The cost of the reward in channel points.
+	ChannelPoints int `json:"channel_points"`
+	// The unlocked emote, nil unless the reward unlocks one.
+	Emote *RewardEmote `json:"emote"`
+}
+
+// RewardEmote is an emote unlocked by an automatic reward.
+type RewardEmote struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// AutomaticRewardMessage is the message sent with an automatic reward.
+type AutomaticRewardMessage struct {
+	Text      string                    `json:"text"`
+	Fragments []AutomaticRewardFragment `json:"fragments"`
+}
+
+// AutomaticRewardFragment is a part of an AutomaticRewardMessage.
+type AutomaticRewardFragment struct {
+	// One of "text" or "emote".
+	Type string `json:"type"`
+	Text string `json:"text"`
+	// The emote, nil unless Type is "emote".
+	Emote *FragmentEmote `json:"emote"`
+}
+
+// FragmentEmote is the emote of a message fragment.
+type FragmentEmote struct {
+	ID string `json:"id"`
+}
@@ -0,0 +1,352 @@
+package events
+
+// ChannelChatMessageEvent is the event of a channel.chat.message notification, version 1.
+type ChannelChatMessageEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The user who sent the message.
+	ChatterUserID    string      `json:"chatter_user_id"`
+	ChatterUserLogin string      `json:"chatter_user_login"`
+	ChatterUserName  string      `json:"chatter_user_name"`
+	MessageID        string      `json:"message_id"`
+	Message          ChatMessage `json:"message"`
+	// One of "text", "channel_points_highlighted", "channel_points_sub_only", "user_intro",
+	// "power_ups_message_effect", or "power_ups_gigantified_emote".
+	MessageType string      `json:"message_type"`
+	Badges      []ChatBadge `json:"badges"`
+	// The color of the chatter's name, eg: "#FF0000". Empty if the chatter didn't choose one.
+	Color string `json:"color"`
+	// The bits cheered with the message, nil if none.
+	Cheer *ChatCheer `json:"cheer"`
+	// The message replied to, nil if the message isn't a reply.
+	Reply *ChatReply `json:"reply"`
+	// The ID of the channel points reward the message was sent with, empty if none.
+	ChannelPointsCustomRewardID string `json:"channel_points_custom_reward_id"`
+	// The ID of the power-up animation of the message, empty if none.
+	ChannelPointsAnimationID string `json:"channel_points_animation_id"`
+	// The channel the message was sent in during a shared chat session, empty if it was sent in this channel.
+	SourceBroadcasterUserID    string `json:"source_broadcaster_user_id"`
+	SourceBroadcasterUserLogin string `json:"source_broadcaster_user_login"`
+	SourceBroadcasterUserName  string `json:"source_broadcaster_user_name"`
+	SourceMessageID            string `json:"source_message_id"`
+	// The badges of the chatter in the source channel, nil if the message was sent in this channel.
+	SourceBadges []ChatBadge `json:"source_badges"`
+	// Whether the message is only sent to the source channel in a shared chat session, nil outside of one.
+	IsSourceOnly *bool `json:"is_source_only"`
+}
+
+// ChatMessage is the text of a chat message, split into fragments of plain text, emotes, cheermotes, and mentions.
+type ChatMessage struct {
+	Text      string         `json:"text"`
+	Fragments []ChatFragment `json:"fragments"`
+}
+
+// Emotes returns the emotes of the message, in order.
+func (m ChatMessage) Emotes() []ChatEmote {
+	var emotes []ChatEmote
+	for _, fragment := range m.Fragments {
+		if fragment.Emote != nil {
+			emotes = append(emotes, *fragment.Emote)
+		}
+	}
+	return emotes
+}
+
+// Cheermotes returns the cheermotes of the message, in order.
+func (m ChatMessage) Cheermotes() []ChatCheermote {
+	var cheermotes []ChatCheermote
+	for _, fragment := range m.Fragments {
+		if fragment.Cheermote != nil {
+			cheermotes = append(cheermotes, *fragment.Cheermote)
+		}
+	}
+	return cheermotes
+}
+
+// Bits returns the number of bits cheered with the cheermotes of the message.
+func (m ChatMessage) Bits() int {
+	bits := 0
+	for _, cheermote := range m.Cheermotes() {
+		bits += cheermote.Bits
+	}
+	return bits
+}
+
+// Mentions returns the users mentioned in the message, in order.
+func (m ChatMessage) Mentions() []ChatMention {
+	var mentions []ChatMention
+	for _, fragment := range m.Fragments {
+		if fragment.Mention != nil {
+			mentions = append(mentions, *fragment.Mention)
+		}
+	}
+	return mentions
+}
+
+// ChatFragment is a part of a chat message. Only the field matching Type is set.
+type ChatFragment struct {
+	// One of "text", "cheermote", "emote", or "mention".
+	Type      string         `json:"type"`
+	Text      string         `json:"text"`
+	Cheermote *ChatCheermote `json:"cheermote"`
+	Emote     *ChatEmote     `json:"emote"`
+	Mention   *ChatMention   `json:"mention"`
+}
+
+// ChatCheermote is a cheermote in a chat message, eg: "Cheer100".
+type ChatCheermote struct {
+	// The name of the cheermote without the amount, eg: "Cheer".
+	Prefix string `json:"prefix"`
+	// The bits cheered.
+	Bits int `json:"bits"`
+	// The tier of the cheermote, which determines its image.
+	Tier int `json:"tier"`
+}
+
+// ChatEmote is an emote in a chat message.
+type ChatEmote struct {
+	ID         string `json:"id"`
+	EmoteSetID string `json:"emote_set_id"`
+	// The ID of the broadcaster who owns the emote.
+	OwnerID string `json:"owner_id"`
+	// The formats the emote is available in, "static" and "animated".
+	Format []string `json:"format"`
+}
+
+// ChatMention is a mention of a user in a chat message.
+type ChatMention struct {
+	UserID    string `json:"user_id"`
+	UserName  string `json:"user_name"`
+	UserLogin string `json:"user_login"`
+}
+
+// ChatBadge is a chat badge of a user, eg: a subscriber badge.
+type ChatBadge struct {
+	// The badge set, eg: "subscriber".
+	SetID string `json:"set_id"`
+	// The badge in the set, eg: "12" for 12 months.
+	ID string `json:"id"`
+	// Additional information, eg: the exact number of months for subscriber badges.
+	Info string `json:"info"`
+}
+
+// ChatCheer is the bits cheered with a chat message.
+type ChatCheer struct {
+	Bits int `json:"bits"`
+}
+
+// ChatReply is the message a chat message replied to.
+type ChatReply struct {
+	ParentMessageID   string `json:"parent_message_id"`
+	ParentMessageBody string `json:"parent_message_body"`
+	ParentUserID      string `json:"parent_user_id"`
+	ParentUserName    string `json:"parent_user_name"`
+	ParentUserLogin   string `json:"parent_user_login"`
+	// The top level message of the thread.
+	ThreadMessageID string `json:"thread_message_id"`
+	ThreadUserID    string `json:"thread_user_id"`
+	ThreadUserName  string `json:"thread_user_name"`
+	ThreadUserLogin string `json:"thread_user_login"`
+}
+
+// ChannelChatNotificationEvent is the event of a channel.chat.notification notification, version 1, sent for chat
+// announcements like subscriptions, raids, and announcements. Only the field matching NoticeType is set.
+type ChannelChatNotificationEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The user who caused the notification, empty if ChatterIsAnonymous.
+	ChatterUserID      string `json:"chatter_user_id"`
+	ChatterUserLogin   string `json:"chatter_user_login"`
+	ChatterUserName    string `json:"chatter_user_name"`
+	ChatterIsAnonymous bool   `json:"chatter_is_anonymous"`
+	// The color of the chatter's name, eg: "#FF0000".
+	Color  string      `json:"color"`
+	Badges []ChatBadge `json:"badges"`
+	// The message Twitch shows for the notification, eg: "Cool_User subscribed at Tier 1."
+	SystemMessage string `json:"system_message"`
+	MessageID     string `json:"message_id"`
+	// The message the user sent with the notification, eg: with a resubscription.
+	Message ChatMessage `json:"message"`
+	// One of "sub", "resub", "sub_gift", "community_sub_gift", "gift_paid_upgrade", "prime_paid_upgrade", "raid",
+	// "unraid", "pay_it_forward", "announcement", "bits_badge_tier", "charity_donation", or the "shared_chat_"
+	// variants of sub, resub, sub_gift, community_sub_gift, gift_paid_upgrade, prime_paid_upgrade, raid,
+	// pay_it_forward, and announcement.
+	NoticeType       string                `json:"notice_type"`
+	Sub              *ChatSub              `json:"sub"`
+	Resub            *ChatResub            `json:"resub"`
+	SubGift          *ChatSubGift          `json:"sub_gift"`
+	CommunitySubGift *ChatCommunitySubGift `json:"community_sub_gift"`
+	GiftPaidUpgrade  *ChatGiftPaidUpgrade  `json:"gift_paid_upgrade"`
+	PrimePaidUpgrade *ChatPrimePaidUpgrade `json:"prime_paid_upgrade"`
+	Raid             *ChatRaid             `json:"raid"`
+	Unraid           *struct{}             `json:"unraid"`
+	PayItForward     *ChatPayItForward     `json:"pay_it_forward"`
+	Announcement     *ChatAnnouncement     `json:"announcement"`
+	BitsBadgeTier    *ChatBitsBadgeTier    `json:"bits_badge_tier"`
+	CharityDonation  *ChatCharityDonation  `json:"charity_donation"`
+	// Set instead of the fields above for notifications from another channel in a shared chat session.
+	SharedChatSub              *ChatSub              `json:"shared_chat_sub"`
+	SharedChatResub            *ChatResub            `json:"shared_chat_resub"`
+	SharedChatSubGift          *ChatSubGift          `json:"shared_chat_sub_gift"`
+	SharedChatCommunitySubGift *ChatCommunitySubGift `json:"shared_chat_community_sub_gift"`
+	SharedChatGiftPaidUpgrade  *ChatGiftPaidUpgrade  `json:"shared_chat_gift_paid_upgrade"`
+	SharedChatPrimePaidUpgrade *ChatPrimePaidUpgrade `json:"shared_chat_prime_paid_upgrade"`
+	SharedChatRaid             *ChatRaid             `json:"shared_chat_raid"`
+	SharedChatPayItForward     *ChatPayItForward     `json:"shared_chat_pay_it_forward"`
+	SharedChatAnnouncement     *ChatAnnouncement     `json:"shared_chat_announcement"`
+	// The channel the notification happened in during a shared chat session, empty if it happened in this channel.
+	SourceBroadcasterUserID    string      `json:"source_broadcaster_user_id"`
+	SourceBroadcasterUserLogin string      `json:"source_broadcaster_user_login"`
+	SourceBroadcasterUserName  string      `json:"source_broadcaster_user_name"`
+	SourceMessageID            string      `json:"source_message_id"`
+	SourceBadges               []ChatBadge `json:"source_badges"`
+}
+
+// ChatSub is a new subscription.
+type ChatSub struct {
+	// One of "1000", "2000", or "3000".
+	SubTier        string `json:"sub_tier"`
+	IsPrime        bool   `json:"is_prime"`
+	DurationMonths int    `json:"duration_months"`
+}
+
+// ChatResub is a resubscription.
+type ChatResub struct {
+	CumulativeMonths int `json:"cumulative_months"`
+	DurationMonths   int `json:"duration_months"`
+	// Nil if the user chose not to share it.
+	StreakMonths *int `json:"streak_months"`
+	// One of "1000", "2000", or "3000".
+	SubTier string `json:"sub_tier"`
+	IsPrime bool   `json:"is_prime"`
+	IsGift  bool   `json:"is_gift"`
+	// The user who gifted the subscription, empty unless IsGift.
+	GifterIsAnonymous bool   `json:"gifter_is_anonymous"`
+	GifterUserID      string `json:"gifter_user_id"`
+	GifterUserName    string `json:"gifter_user_name"`
+	GifterUserLogin   string `json:"gifter_user_login"`
+}
+
+// ChatSubGift is a subscription gifted to a user.
+type ChatSubGift struct {
+	DurationMonths int `json:"duration_months"`
+	// The number of subscriptions the gifter gifted in the channel so far, nil if anonymous or not shared.
+	CumulativeTotal *int   `json:"cumulative_total"`
+	RecipientUserID string `json:"recipient_user_id"`
+	// One of "1000", "2000", or "3000".
+	SubTier            string `json:"sub_tier"`
+	RecipientUserName  string `json:"recipient_user_name"`
+	RecipientUserLogin string `json:"recipient_user_login"`
+	// The ID of the ChatCommunitySubGift the gift is part of, empty if none.
+	CommunityGiftID string `json:"community_gift_id"`
+}
+
+// ChatCommunitySubGift is a number of subscriptions gifted to the community.
+type ChatCommunitySubGift struct {
+	ID    string `json:"id"`
+	Total int    `json:"total"`
+	// One of "1000", "2000", or "3000".
+	SubTier string `json:"sub_tier"`
+	// The number of subscriptions the gifter gifted in the channel so far, nil if anonymous or not shared.
+	CumulativeTotal *int `json:"cumulative_total"`
+}
+
+// ChatGiftPaidUpgrade is a gifted subscription converted to a paid one.
+type ChatGiftPaidUpgrade struct {
+	GifterIsAnonymous bool `json:"gifter_is_anonymous"`
+	// The user who gifted the subscription, empty if GifterIsAnonymous.
+	GifterUserID    string `json:"gifter_user_id"`
+	GifterUserName  string `json:"gifter_user_name"`
+	GifterUserLogin string `json:"gifter_user_login"`
+}
+
+// ChatPrimePaidUpgrade is a Prime subscription converted to a paid one.
+type ChatPrimePaidUpgrade struct {
+	// One of "1000", "2000", or "3000".
+	SubTier string `json:"sub_tier"`
+}
+
+// ChatRaid is an incoming raid.
+type ChatRaid struct {
+	// The raiding broadcaster.
+	UserID          string `json:"user_id"`
+	UserName        string `json:"user_name"`
+	UserLogin       string `json:"user_login"`
+	ViewerCount     int    `json:"viewer_count"`
+	ProfileImageURL string `json:"profile_image_url"`
+}
+
+// ChatPayItForward is a user paying forward a subscription that was gifted to them.
+type ChatPayItForward struct {
+	GifterIsAnonymous bool `json:"gifter_is_anonymous"`
+	// The user who gifted the original subscription, empty if GifterIsAnonymous.
+	GifterUserID    string `json:"gifter_user_id"`
+	GifterUserName  string `json:"gifter_user_name"`
+	GifterUserLogin string `json:"gifter_user_login"`
+}
+
+// ChatAnnouncement is an announcement by a moderator.
+type ChatAnnouncement struct {
+	// One of "BLUE", "GREEN", "ORANGE", "PURPLE", or "PRIMARY".
+	Color string `json:"color"`
+}
+
+// ChatBitsBadgeTier is a user unlocking a new bits badge.
+type ChatBitsBadgeTier struct {
+	Tier int `json:"tier"`
+}
+
+// ChatCharityDonation is a donation to a charity campaign of the channel.
+type ChatCharityDonation struct {
+	CharityName string     `json:"charity_name"`
+	Amount      ChatAmount `json:"amount"`
+}
+
+// ChatAmount is an amount of money in the minor unit of its currency, eg: Value 1050 with DecimalPlace 2 is 10.50.
+type ChatAmount struct {
+	Value        int `json:"value"`
+	DecimalPlace int `json:"decimal_place"`
+	// An ISO 4217 currency code, eg: "USD".
+	Currency string `json:"currency"`
+}
+
+// ChannelChatMessageDeleteEvent is the event of a channel.chat.message_delete notification, version 1.
+type ChannelChatMessageDeleteEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The user whose message was deleted.
+	TargetUserID    string `json:"target_user_id"`
+	TargetUserName  string `json:"target_user_name"`
+	TargetUserLogin string `json:"target_user_login"`
+	MessageID       string `json:"message_id"`
+}
+
+// ChannelChatClearEvent is the event of a channel.chat.clear notification, version 1.
+type ChannelChatClearEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+}
+
+// ChannelChatSettingsUpdateEvent is the event of a channel.chat_settings.update notification, version 1.
+type ChannelChatSettingsUpdateEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// Whether chat messages may only contain emotes.
+	EmoteMode bool `json:"emote_mode"`
+	// Whether only followers may chat.
+	FollowerMode bool `json:"follower_mode"`
+	// How long users must follow before they may chat, nil unless FollowerMode.
+	FollowerModeDurationMinutes *int `json:"follower_mode_duration_minutes"`
+	SlowMode                    bool `json:"slow_mode"`
+	// How long users must wait between messages, nil unless SlowMode.
+	SlowModeWaitTimeSeconds *int `json:"slow_mode_wait_time_seconds"`
+	// Whether only subscribers may chat.
+	SubscriberMode bool `json:"subscriber_mode"`
+	// Whether users may only post unique messages.
+	UniqueChatMode bool `json:"unique_chat_mode"`
+}
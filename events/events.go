@@ -0,0 +1,10 @@
+// Package events provides typed structs for the event payloads of EventSub notifications, see
+// https://dev.twitch.tv/docs/eventsub/eventsub-reference/#events. Register a handler for the struct of a subscription
+// type with twitchwh.OnEvent:
+//
+//	twitchwh.OnEvent(client, twitchwh.TypeStreamOnline, func(online events.StreamOnlineEvent) {
+//		log.Println(online.BroadcasterUserLogin, "went live at", online.StartedAt)
+//	})
+//
+// Fields follow the latest version of each subscription type known to twitchwh.
+package events
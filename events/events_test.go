@@ -0,0 +1,355 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+	"github.com/macluxHD/twitchwh/twitchwhtest"
+)
+
+func TestStreamOnlineEvent(t *testing.T) {
+	var event StreamOnlineEvent
+	err := json.Unmarshal([]byte(`{"id":"9001","broadcaster_user_id":"1337","broadcaster_user_login":"cool_user",`+
+		`"broadcaster_user_name":"Cool_User","type":"live","started_at":"2020-10-11T10:11:12.123Z"}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := StreamOnlineEvent{
+		ID:                   "9001",
+		BroadcasterUserID:    "1337",
+		BroadcasterUserLogin: "cool_user",
+		BroadcasterUserName:  "Cool_User",
+		Type:                 "live",
+		StartedAt:            time.Date(2020, 10, 11, 10, 11, 12, 123000000, time.UTC),
+	}
+	if !event.StartedAt.Equal(want.StartedAt) {
+		t.Fatalf("Expected %v, got %v", want.StartedAt, event.StartedAt)
+	}
+	event.StartedAt = want.StartedAt
+	if event != want {
+		t.Fatalf("Expected %+v, got %+v", want, event)
+	}
+}
+
+func TestChannelUpdateEvent(t *testing.T) {
+	var event ChannelUpdateEvent
+	err := json.Unmarshal([]byte(`{"broadcaster_user_id":"1337","broadcaster_user_login":"cool_user",`+
+		`"broadcaster_user_name":"Cool_User","title":"Best Stream Ever","language":"en","category_id":"12453",`+
+		`"category_name":"Grand Theft Auto","content_classification_labels":["MatureGame"]}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Title != "Best Stream Ever" || event.CategoryName != "Grand Theft Auto" ||
+		!slices.Equal(event.ContentClassificationLabels, []string{"MatureGame"}) {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelSubscriptionGiftEvent(t *testing.T) {
+	var event ChannelSubscriptionGiftEvent
+	err := json.Unmarshal([]byte(`{"user_id":null,"user_login":null,"user_name":null,"broadcaster_user_id":"1337",`+
+		`"broadcaster_user_login":"cooler_user","broadcaster_user_name":"Cooler_User","total":2,"tier":"1000",`+
+		`"cumulative_total":null,"is_anonymous":true}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.UserID != "" || event.Total != 2 || event.CumulativeTotal != nil || !event.IsAnonymous {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelSubscriptionMessageEvent(t *testing.T) {
+	var event ChannelSubscriptionMessageEvent
+	err := json.Unmarshal([]byte(`{"user_id":"1234","user_login":"cool_user","user_name":"Cool_User",`+
+		`"broadcaster_user_id":"1337","broadcaster_user_login":"cooler_user","broadcaster_user_name":"Cooler_User",`+
+		`"tier":"1000","message":{"text":"Love the stream! FevziGG","emotes":[{"begin":23,"end":30,"id":"302976485"}]},`+
+		`"cumulative_months":15,"streak_months":1,"duration_months":6}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MessageEmote{{Begin: 23, End: 30, ID: "302976485"}}
+	if !slices.Equal(event.Message.Emotes, want) || event.StreakMonths == nil || *event.StreakMonths != 1 ||
+		event.CumulativeMonths != 15 || event.DurationMonths != 6 {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelPointsCustomRewardEvent(t *testing.T) {
+	var event ChannelPointsCustomRewardEvent
+	err := json.Unmarshal([]byte(`{"id":"9001","broadcaster_user_id":"1337","broadcaster_user_login":"cool_user",`+
+		`"broadcaster_user_name":"Cool_User","is_enabled":true,"is_paused":false,"is_in_stock":true,"title":"Cool Reward",`+
+		`"cost":100,"prompt":"reward prompt","is_user_input_required":true,"should_redemptions_skip_request_queue":false,`+
+		`"cooldown_expires_at":"2019-11-16T10:11:12.634234626Z","redemptions_redeemed_current_stream":123,`+
+		`"max_per_stream":{"is_enabled":true,"value":1000},"max_per_user_per_stream":{"is_enabled":true,"value":1000},`+
+		`"global_cooldown":{"is_enabled":true,"seconds":1000},"background_color":"#FA1ED2","image":null,`+
+		`"default_image":{"url_1x":"https://static-cdn.jtvnw.net/image-1.png","url_2x":"https://static-cdn.jtvnw.net/image-2.png",`+
+		`"url_4x":"https://static-cdn.jtvnw.net/image-4.png"}}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Image != nil || event.DefaultImage.URL4x != "https://static-cdn.jtvnw.net/image-4.png" ||
+		!event.MaxPerStream.IsEnabled || event.GlobalCooldown.Seconds != 1000 || event.CooldownExpiresAt == nil ||
+		event.RedemptionsRedeemedCurrentStream == nil || *event.RedemptionsRedeemedCurrentStream != 123 {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelPointsCustomRewardRedemptionEvent(t *testing.T) {
+	var event ChannelPointsCustomRewardRedemptionEvent
+	err := json.Unmarshal([]byte(`{"id":"17fa2df1-ad76-4804-bfa5-a40ef63efe63","broadcaster_user_id":"1337",`+
+		`"broadcaster_user_login":"cool_user","broadcaster_user_name":"Cool_User","user_id":"9001","user_login":"cooler_user",`+
+		`"user_name":"Cooler_User","user_input":"pogchamp","status":"unfulfilled","reward":{"id":"92af127c-7326-4483-a52b-b0da0be61c01",`+
+		`"title":"title","cost":100,"prompt":"reward prompt"},"redeemed_at":"2020-07-15T17:16:03.17106713Z"}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Status != "unfulfilled" || event.Reward.Cost != 100 || event.RedeemedAt.IsZero() {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelPointsAutomaticRewardRedemptionEvent(t *testing.T) {
+	var event ChannelPointsAutomaticRewardRedemptionEvent
+	err := json.Unmarshal([]byte(`{"broadcaster_user_id":"12826","broadcaster_user_name":"Twitch","broadcaster_user_login":"twitch",`+
+		`"user_id":"141981764","user_name":"TwitchDev","user_login":"twitchdev","id":"f024099a-e0aa-4339-9a6a-2b4d6d9bcc0b",`+
+		`"reward":{"type":"send_highlighted_message","channel_points":100,"emote":null},"message":{"text":"Hello world! VoHiYo",`+
+		`"fragments":[{"type":"text","text":"Hello world! ","emote":null},{"type":"emote","text":"VoHiYo","emote":{"id":"81274"}}]},`+
+		`"redeemed_at":"2024-08-12T21:14:34.260398045Z"}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Reward.Emote != nil || len(event.Message.Fragments) != 2 || event.Message.Fragments[1].Emote == nil ||
+		event.Message.Fragments[1].Emote.ID != "81274" {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelPollEndEvent(t *testing.T) {
+	var event ChannelPollEndEvent
+	err := json.Unmarshal([]byte(`{"id":"1243456","broadcaster_user_id":"1337","broadcaster_user_login":"cool_user",`+
+		`"broadcaster_user_name":"Cool_User","title":"Aren’t shoes just really hard socks?","choices":[`+
+		`{"id":"123","title":"Blue","bits_votes":0,"channel_points_votes":70,"votes":120},`+
+		`{"id":"124","title":"Yellow","bits_votes":0,"channel_points_votes":40,"votes":80}],`+
+		`"bits_voting":{"is_enabled":false,"amount_per_vote":0},"channel_points_voting":{"is_enabled":true,"amount_per_vote":10},`+
+		`"status":"completed","started_at":"2020-07-15T17:16:03.17106713Z","ended_at":"2020-07-15T17:16:11.17106713Z"}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Status != "completed" || len(event.Choices) != 2 || event.Choices[0].Votes != 120 ||
+		event.ChannelPointsVoting.AmountPerVote != 10 || event.EndedAt.IsZero() {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelPredictionEndEvent(t *testing.T) {
+	var event ChannelPredictionEndEvent
+	err := json.Unmarshal([]byte(`{"id":"1243456","broadcaster_user_id":"1337","broadcaster_user_login":"cool_user",`+
+		`"broadcaster_user_name":"Cool_User","title":"Aren’t shoes just really hard socks?","winning_outcome_id":"12345",`+
+		`"outcomes":[{"id":"12345","title":"Yeah!","color":"blue","users":2,"channel_points":15000,"top_predictors":[`+
+		`{"user_name":"Cool_User","user_login":"cool_user","user_id":"1234","channel_points_won":10000,"channel_points_used":500},`+
+		`{"user_name":"Coolest_User","user_login":"coolest_user","user_id":"1236","channel_points_won":null,"channel_points_used":100}]},`+
+		`{"id":"22435","title":"No!","users":2,"channel_points":200,"color":"pink","top_predictors":[]}],`+
+		`"status":"resolved","started_at":"2020-07-15T17:16:03.17106713Z","ended_at":"2020-07-15T17:16:11.17106713Z"}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	top := event.Outcomes[0].TopPredictors
+	if event.WinningOutcomeID != "12345" || len(top) != 2 || top[0].ChannelPointsWon == nil || *top[0].ChannelPointsWon != 10000 ||
+		top[1].ChannelPointsWon != nil || event.Outcomes[1].Color != "pink" {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelHypeTrainProgressEvent(t *testing.T) {
+	var event ChannelHypeTrainProgressEvent
+	err := json.Unmarshal([]byte(`{"id":"1b0AsbInCHZW2SQFQkCzqN07Ib2","broadcaster_user_id":"1337","broadcaster_user_login":"cool_user",`+
+		`"broadcaster_user_name":"Cool_User","level":2,"total":700,"progress":200,"goal":1000,"top_contributions":[`+
+		`{"user_id":"123","user_login":"pogchamp","user_name":"PogChamp","type":"bits","total":50},`+
+		`{"user_id":"456","user_login":"kappa","user_name":"Kappa","type":"subscription","total":45}],`+
+		`"shared_train_participants":null,"started_at":"2020-07-15T17:16:03.17106713Z","expires_at":"2020-07-15T17:16:11.17106713Z",`+
+		`"type":"regular","is_shared_train":false}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Level != 2 || len(event.TopContributions) != 2 || event.TopContributions[1].Type != "subscription" ||
+		event.SharedTrainParticipants != nil {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+	if percent := event.ProgressPercent(); percent != 20 {
+		t.Fatalf("Expected 20%%, got %v", percent)
+	}
+	if percent := (ChannelHypeTrainBeginEvent{}).ProgressPercent(); percent != 0 {
+		t.Fatalf("Expected 0%% without a goal, got %v", percent)
+	}
+}
+
+func TestChannelChatMessageEvent(t *testing.T) {
+	var event ChannelChatMessageEvent
+	err := json.Unmarshal([]byte(`{"broadcaster_user_id":"1971641","broadcaster_user_login":"streamer","broadcaster_user_name":"streamer",`+
+		`"chatter_user_id":"4145994","chatter_user_login":"viewer32","chatter_user_name":"viewer32",`+
+		`"message_id":"cc106a89-1814-919d-454c-f4f2f970aae7","message":{"text":"Hi chat Cheer100 Kappa @streamer",`+
+		`"fragments":[{"type":"text","text":"Hi chat ","cheermote":null,"emote":null,"mention":null},`+
+		`{"type":"cheermote","text":"Cheer100","cheermote":{"prefix":"cheer","bits":100,"tier":1},"emote":null,"mention":null},`+
+		`{"type":"emote","text":"Kappa","cheermote":null,"emote":{"id":"25","emote_set_id":"0","owner_id":"0","format":["static"]},"mention":null},`+
+		`{"type":"mention","text":"@streamer","cheermote":null,"emote":null,"mention":{"user_id":"1971641","user_name":"streamer","user_login":"streamer"}}]},`+
+		`"color":"#00FF7F","badges":[{"set_id":"subscriber","id":"12","info":"16"}],"message_type":"text",`+
+		`"cheer":{"bits":100},"reply":null,"channel_points_custom_reward_id":null,"source_broadcaster_user_id":null,`+
+		`"source_broadcaster_user_login":null,"source_broadcaster_user_name":null,"source_message_id":null,"source_badges":null}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Message.Bits() != 100 || event.Cheer == nil || event.Cheer.Bits != 100 || event.Reply != nil {
+		t.Fatalf("Unexpected cheer %+v", event)
+	}
+	if emotes := event.Message.Emotes(); len(emotes) != 1 || emotes[0].ID != "25" {
+		t.Fatalf("Unexpected emotes %+v", emotes)
+	}
+	if mentions := event.Message.Mentions(); len(mentions) != 1 || mentions[0].UserLogin != "streamer" {
+		t.Fatalf("Unexpected mentions %+v", mentions)
+	}
+	if len(event.Badges) != 1 || event.Badges[0].Info != "16" || event.SourceBadges != nil || event.IsSourceOnly != nil {
+		t.Fatalf("Unexpected badges %+v", event)
+	}
+}
+
+func TestChannelChatNotificationEvent(t *testing.T) {
+	var event ChannelChatNotificationEvent
+	err := json.Unmarshal([]byte(`{"broadcaster_user_id":"1971641","broadcaster_user_login":"streamer","broadcaster_user_name":"streamer",`+
+		`"chatter_user_id":"49912639","chatter_user_login":"viewer23","chatter_user_name":"viewer23","chatter_is_anonymous":false,`+
+		`"color":"","badges":[],"system_message":"viewer23 subscribed at Tier 1. They've subscribed for 10 months!",`+
+		`"message_id":"d62235c8-47ff-a4f4-84e8-5a29a65a9c03","message":{"text":"","fragments":[]},"notice_type":"resub",`+
+		`"sub":null,"resub":{"cumulative_months":10,"duration_months":0,"streak_months":null,"sub_tier":"1000","is_prime":false,`+
+		`"is_gift":false,"gifter_is_anonymous":null,"gifter_user_id":null,"gifter_user_name":null,"gifter_user_login":null},`+
+		`"sub_gift":null,"community_sub_gift":null,"gift_paid_upgrade":null,"prime_paid_upgrade":null,"pay_it_forward":null,`+
+		`"raid":null,"unraid":null,"announcement":null,"bits_badge_tier":null,"charity_donation":null}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.NoticeType != "resub" || event.Resub == nil || event.Resub.CumulativeMonths != 10 || event.Resub.StreakMonths != nil ||
+		event.Sub != nil || event.Unraid != nil {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelChatSettingsUpdateEvent(t *testing.T) {
+	var event ChannelChatSettingsUpdateEvent
+	err := json.Unmarshal([]byte(`{"broadcaster_user_id":"1337","broadcaster_user_login":"cool_user","broadcaster_user_name":"Cool_User",`+
+		`"emote_mode":true,"follower_mode":false,"follower_mode_duration_minutes":null,"slow_mode":true,`+
+		`"slow_mode_wait_time_seconds":10,"subscriber_mode":false,"unique_chat_mode":false}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !event.EmoteMode || event.FollowerModeDurationMinutes != nil || event.SlowModeWaitTimeSeconds == nil || *event.SlowModeWaitTimeSeconds != 10 {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+// TestSamples decodes the documented payload of each typed subscription type, rejecting fields the struct lacks.
+func TestSamples(t *testing.T) {
+	for Type, event := range map[string]any{
+		twitchwh.TypeStreamOnline:                                     &StreamOnlineEvent{},
+		twitchwh.TypeStreamOffline:                                    &StreamOfflineEvent{},
+		twitchwh.TypeChannelUpdate:                                    &ChannelUpdateEvent{},
+		twitchwh.TypeChannelSubscribe:                                 &ChannelSubscribeEvent{},
+		twitchwh.TypeChannelSubscriptionEnd:                           &ChannelSubscriptionEndEvent{},
+		twitchwh.TypeChannelSubscriptionGift:                          &ChannelSubscriptionGiftEvent{},
+		twitchwh.TypeChannelSubscriptionMessage:                       &ChannelSubscriptionMessageEvent{},
+		twitchwh.TypeChannelChannelPointsCustomRewardAdd:              &ChannelPointsCustomRewardEvent{},
+		twitchwh.TypeChannelChannelPointsCustomRewardUpdate:           &ChannelPointsCustomRewardEvent{},
+		twitchwh.TypeChannelChannelPointsCustomRewardRemove:           &ChannelPointsCustomRewardEvent{},
+		twitchwh.TypeChannelChannelPointsCustomRewardRedemptionAdd:    &ChannelPointsCustomRewardRedemptionEvent{},
+		twitchwh.TypeChannelChannelPointsCustomRewardRedemptionUpdate: &ChannelPointsCustomRewardRedemptionEvent{},
+		twitchwh.TypeChannelChannelPointsAutomaticRewardRedemptionAdd: &ChannelPointsAutomaticRewardRedemptionEvent{},
+		twitchwh.TypeChannelPollBegin:                                 &ChannelPollBeginEvent{},
+		twitchwh.TypeChannelPollProgress:                              &ChannelPollProgressEvent{},
+		twitchwh.TypeChannelPollEnd:                                   &ChannelPollEndEvent{},
+		twitchwh.TypeChannelPredictionBegin:                           &ChannelPredictionBeginEvent{},
+		twitchwh.TypeChannelPredictionProgress:                        &ChannelPredictionProgressEvent{},
+		twitchwh.TypeChannelPredictionLock:                            &ChannelPredictionLockEvent{},
+		twitchwh.TypeChannelPredictionEnd:                             &ChannelPredictionEndEvent{},
+		twitchwh.TypeChannelHypeTrainBegin:                            &ChannelHypeTrainBeginEvent{},
+		twitchwh.TypeChannelHypeTrainProgress:                         &ChannelHypeTrainProgressEvent{},
+		twitchwh.TypeChannelHypeTrainEnd:                              &ChannelHypeTrainEndEvent{},
+		twitchwh.TypeChannelChatMessage:                               &ChannelChatMessageEvent{},
+		twitchwh.TypeChannelChatNotification:                          &ChannelChatNotificationEvent{},
+		twitchwh.TypeChannelChatMessageDelete:                         &ChannelChatMessageDeleteEvent{},
+		twitchwh.TypeChannelChatClear:                                 &ChannelChatClearEvent{},
+		twitchwh.TypeChannelChatSettingsUpdate:                        &ChannelChatSettingsUpdateEvent{},
+		twitchwh.TypeChannelBan:                                       &ChannelBanEvent{},
+		twitchwh.TypeChannelUnban:                                     &ChannelUnbanEvent{},
+		twitchwh.TypeChannelModerate:                                  &ChannelModerateEvent{},
+		twitchwh.TypeChannelModeratorAdd:                              &ChannelModeratorAddEvent{},
+		twitchwh.TypeChannelModeratorRemove:                           &ChannelModeratorRemoveEvent{},
+		twitchwh.TypeChannelShieldModeBegin:                           &ChannelShieldModeBeginEvent{},
+		twitchwh.TypeChannelShieldModeEnd:                             &ChannelShieldModeEndEvent{},
+		twitchwh.TypeChannelShoutoutCreate:                            &ChannelShoutoutCreateEvent{},
+		twitchwh.TypeChannelShoutoutReceive:                           &ChannelShoutoutReceiveEvent{},
+		twitchwh.TypeChannelGoalBegin:                                 &ChannelGoalBeginEvent{},
+		twitchwh.TypeChannelGoalProgress:                              &ChannelGoalProgressEvent{},
+		twitchwh.TypeChannelGoalEnd:                                   &ChannelGoalEndEvent{},
+	} {
+		decoder := json.NewDecoder(bytes.NewReader(twitchwhtest.Sample(Type)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(event); err != nil {
+			t.Errorf("Could not decode sample of %s: %v", Type, err)
+		}
+	}
+}
+
+func TestChannelModerateEvent(t *testing.T) {
+	var event ChannelModerateEvent
+	if err := json.Unmarshal(twitchwhtest.Sample(twitchwh.TypeChannelModerate), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Action != "timeout" || event.Timeout == nil || event.Timeout.UserLogin != "uncool_viewer" || event.Timeout.ExpiresAt.IsZero() {
+		t.Fatalf("Unexpected timeout %+v", event.Timeout)
+	}
+	if event.Ban != nil || event.SharedChatTimeout != nil || event.SourceBroadcasterUserID != "" {
+		t.Fatalf("Expected only the timeout to be set, got %+v", event)
+	}
+
+	err := json.Unmarshal([]byte(`{"action":"add_blocked_term","automod_terms":{"action":"add","list":"blocked",`+
+		`"terms":["foo","bar"],"from_automod":false}}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.AutomodTerms == nil || !slices.Equal(event.AutomodTerms.Terms, []string{"foo", "bar"}) {
+		t.Fatalf("Unexpected automod terms %+v", event.AutomodTerms)
+	}
+}
+
+func TestChannelBanEvent(t *testing.T) {
+	var event ChannelBanEvent
+	err := json.Unmarshal([]byte(`{"user_id":"1234","reason":"","banned_at":"2020-07-15T18:15:11.17106713Z",`+
+		`"ends_at":null,"is_permanent":true}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !event.IsPermanent || event.EndsAt != nil || event.BannedAt.IsZero() {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelShoutoutCreateEvent(t *testing.T) {
+	var event ChannelShoutoutCreateEvent
+	if err := json.Unmarshal(twitchwhtest.Sample(twitchwh.TypeChannelShoutoutCreate), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.ToBroadcasterUserLogin != "sandysanderman" || event.ViewerCount != 860 || !event.TargetCooldownEndsAt.After(event.CooldownEndsAt) {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
+
+func TestChannelGoalEndEvent(t *testing.T) {
+	var event ChannelGoalEndEvent
+	if err := json.Unmarshal(twitchwhtest.Sample(twitchwh.TypeChannelGoalEnd), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != GoalTypeSubscription || event.IsAchieved || event.CurrentAmount != 180 || event.TargetAmount != 220 {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
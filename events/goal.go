@@ -0,0 +1,72 @@
+package events
+
+import "time"
+
+// GoalType is the kind of contribution a creator goal counts.
+type GoalType string
+
+const (
+	// Followers of the channel.
+	GoalTypeFollow GoalType = "follow"
+	// Subscription points of the channel, a tier 2 subscription counts 2 and a tier 3 subscription counts 6.
+	GoalTypeSubscription GoalType = "subscription"
+	// Subscriptions to the channel.
+	GoalTypeSubscriptionCount GoalType = "subscription_count"
+	// Subscription points of the subscriptions added since the goal started.
+	GoalTypeNewSubscription GoalType = "new_subscription"
+	// Subscriptions added since the goal started.
+	GoalTypeNewSubscriptionCount GoalType = "new_subscription_count"
+	// Bits cheered since the goal started.
+	GoalTypeNewBit GoalType = "new_bit"
+	// Users who cheered since the goal started.
+	GoalTypeNewCheerer GoalType = "new_cheerer"
+)
+
+// ChannelGoalBeginEvent is the event of a channel.goal.begin notification, version 1.
+type ChannelGoalBeginEvent struct {
+	// The ID of the goal.
+	ID                   string   `json:"id"`
+	BroadcasterUserID    string   `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string   `json:"broadcaster_user_login"`
+	BroadcasterUserName  string   `json:"broadcaster_user_name"`
+	Type                 GoalType `json:"type"`
+	// The description the broadcaster gave the goal, empty if none.
+	Description   string    `json:"description"`
+	CurrentAmount int       `json:"current_amount"`
+	TargetAmount  int       `json:"target_amount"`
+	StartedAt     time.Time `json:"started_at"`
+}
+
+// ChannelGoalProgressEvent is the event of a channel.goal.progress notification, version 1, sent when the current
+// amount of a goal changes.
+type ChannelGoalProgressEvent struct {
+	// The ID of the goal.
+	ID                   string   `json:"id"`
+	BroadcasterUserID    string   `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string   `json:"broadcaster_user_login"`
+	BroadcasterUserName  string   `json:"broadcaster_user_name"`
+	Type                 GoalType `json:"type"`
+	// The description the broadcaster gave the goal, empty if none.
+	Description   string    `json:"description"`
+	CurrentAmount int       `json:"current_amount"`
+	TargetAmount  int       `json:"target_amount"`
+	StartedAt     time.Time `json:"started_at"`
+}
+
+// ChannelGoalEndEvent is the event of a channel.goal.end notification, version 1.
+type ChannelGoalEndEvent struct {
+	// The ID of the goal.
+	ID                   string   `json:"id"`
+	BroadcasterUserID    string   `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string   `json:"broadcaster_user_login"`
+	BroadcasterUserName  string   `json:"broadcaster_user_name"`
+	Type                 GoalType `json:"type"`
+	// The description the broadcaster gave the goal, empty if none.
+	Description string `json:"description"`
+	// Whether the target amount was reached, the goal may also end because the broadcaster ended it.
+	IsAchieved    bool      `json:"is_achieved"`
+	CurrentAmount int       `json:"current_amount"`
+	TargetAmount  int       `json:"target_amount"`
+	StartedAt     time.Time `json:"started_at"`
+	EndedAt       time.Time `json:"ended_at"`
+}
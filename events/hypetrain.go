@@ -0,0 +1,123 @@
+package events
+
+import "time"
+
+// ChannelHypeTrainBeginEvent is the event of a channel.hype_train.begin notification, version 2.
+type ChannelHypeTrainBeginEvent struct {
+	// The ID of the hype train.
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// Total points contributed to the hype train.
+	Total int `json:"total"`
+	// Points contributed in the current level.
+	Progress int `json:"progress"`
+	// Points needed to reach the next level.
+	Goal int `json:"goal"`
+	// The top contributors per contribution type.
+	TopContributions []HypeTrainContribution `json:"top_contributions"`
+	// The current level.
+	Level int `json:"level"`
+	// The highest level and total the channel ever reached.
+	AllTimeHighLevel int `json:"all_time_high_level"`
+	AllTimeHighTotal int `json:"all_time_high_total"`
+	// One of "regular", "treasure", or "golden_kappa".
+	Type string `json:"type"`
+	// Whether the hype train is shared between channels in a shared chat session.
+	IsSharedTrain bool `json:"is_shared_train"`
+	// The channels in the shared hype train, nil unless IsSharedTrain.
+	SharedTrainParticipants []HypeTrainParticipant `json:"shared_train_participants"`
+	StartedAt               time.Time              `json:"started_at"`
+	// When the hype train ends unless it reaches the next level.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ProgressPercent returns the progress towards the next level, from 0 to 100.
+func (e ChannelHypeTrainBeginEvent) ProgressPercent() float64 {
+	return progressPercent(e.Progress, e.Goal)
+}
+
+// ChannelHypeTrainProgressEvent is the event of a channel.hype_train.progress notification, version 2, sent when a
+// user contributes.
+type ChannelHypeTrainProgressEvent struct {
+	// The ID of the hype train.
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// Total points contributed to the hype train.
+	Total int `json:"total"`
+	// Points contributed in the current level.
+	Progress int `json:"progress"`
+	// Points needed to reach the next level.
+	Goal int `json:"goal"`
+	// The top contributors per contribution type.
+	TopContributions []HypeTrainContribution `json:"top_contributions"`
+	// The current level.
+	Level int `json:"level"`
+	// One of "regular", "treasure", or "golden_kappa".
+	Type string `json:"type"`
+	// Whether the hype train is shared between channels in a shared chat session.
+	IsSharedTrain bool `json:"is_shared_train"`
+	// The channels in the shared hype train, nil unless IsSharedTrain.
+	SharedTrainParticipants []HypeTrainParticipant `json:"shared_train_participants"`
+	StartedAt               time.Time              `json:"started_at"`
+	// When the hype train ends unless it reaches the next level.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ProgressPercent returns the progress towards the next level, from 0 to 100.
+func (e ChannelHypeTrainProgressEvent) ProgressPercent() float64 {
+	return progressPercent(e.Progress, e.Goal)
+}
+
+// ChannelHypeTrainEndEvent is the event of a channel.hype_train.end notification, version 2.
+type ChannelHypeTrainEndEvent struct {
+	// The ID of the hype train.
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// Total points contributed to the hype train.
+	Total int `json:"total"`
+	// The final level.
+	Level int `json:"level"`
+	// The top contributors per contribution type.
+	TopContributions []HypeTrainContribution `json:"top_contributions"`
+	// One of "regular", "treasure", or "golden_kappa".
+	Type string `json:"type"`
+	// Whether the hype train was shared between channels in a shared chat session.
+	IsSharedTrain bool `json:"is_shared_train"`
+	// The channels in the shared hype train, nil unless IsSharedTrain.
+	SharedTrainParticipants []HypeTrainParticipant `json:"shared_train_participants"`
+	StartedAt               time.Time              `json:"started_at"`
+	EndedAt                 time.Time              `json:"ended_at"`
+	// When the next hype train can start.
+	CooldownEndsAt time.Time `json:"cooldown_ends_at"`
+}
+
+// HypeTrainContribution is the contribution of a user to a hype train.
+type HypeTrainContribution struct {
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+	// One of "bits", "subscription", or "other".
+	Type string `json:"type"`
+	// The bits, or points for subscriptions (500 for tier 1, 1000 for tier 2, 2500 for tier 3).
+	Total int `json:"total"`
+}
+
+// HypeTrainParticipant is a channel in a shared hype train.
+type HypeTrainParticipant struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+}
+
+func progressPercent(progress, goal int) float64 {
+	if goal <= 0 {
+		return 0
+	}
+	return min(100, float64(progress)/float64(goal)*100)
+}
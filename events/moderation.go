@@ -0,0 +1,195 @@
+package events
+
+import "time"
+
+// ChannelBanEvent is the event of a channel.ban notification, version 1, sent for bans and timeouts.
+type ChannelBanEvent struct {
+	// The user who was banned.
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The moderator who banned the user.
+	ModeratorUserID    string    `json:"moderator_user_id"`
+	ModeratorUserLogin string    `json:"moderator_user_login"`
+	ModeratorUserName  string    `json:"moderator_user_name"`
+	Reason             string    `json:"reason"`
+	BannedAt           time.Time `json:"banned_at"`
+	// When the timeout ends, nil if IsPermanent.
+	EndsAt      *time.Time `json:"ends_at"`
+	IsPermanent bool       `json:"is_permanent"`
+}
+
+// ChannelUnbanEvent is the event of a channel.unban notification, version 1.
+type ChannelUnbanEvent struct {
+	// The user who was unbanned.
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The moderator who unbanned the user.
+	ModeratorUserID    string `json:"moderator_user_id"`
+	ModeratorUserLogin string `json:"moderator_user_login"`
+	ModeratorUserName  string `json:"moderator_user_name"`
+}
+
+// ChannelModeratorAddEvent is the event of a channel.moderator.add notification, version 1.
+type ChannelModeratorAddEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The user who was made a moderator.
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+}
+
+// ChannelModeratorRemoveEvent is the event of a channel.moderator.remove notification, version 1.
+type ChannelModeratorRemoveEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The user who is no longer a moderator.
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+}
+
+// ChannelModerateEvent is the event of a channel.moderate notification, version 2, sent for every moderator action.
+// Only the field matching Action is set, actions without details like "clear" or "emoteonly" set none.
+type ChannelModerateEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The channel the action happened in during a shared chat session, empty if it happened in this channel.
+	SourceBroadcasterUserID    string `json:"source_broadcaster_user_id"`
+	SourceBroadcasterUserLogin string `json:"source_broadcaster_user_login"`
+	SourceBroadcasterUserName  string `json:"source_broadcaster_user_name"`
+	// The moderator who took the action.
+	ModeratorUserID    string `json:"moderator_user_id"`
+	ModeratorUserLogin string `json:"moderator_user_login"`
+	ModeratorUserName  string `json:"moderator_user_name"`
+	// One of "ban", "timeout", "unban", "untimeout", "clear", "emoteonly", "emoteonlyoff", "followers",
+	// "followersoff", "uniquechat", "uniquechatoff", "slow", "slowoff", "subscribers", "subscribersoff", "unraid",
+	// "delete", "unvip", "vip", "raid", "add_blocked_term", "add_permitted_term", "remove_blocked_term",
+	// "remove_permitted_term", "mod", "unmod", "approve_unban_request", "deny_unban_request", "warn", or the
+	// "shared_chat_" variants of ban, unban, timeout, untimeout, and delete.
+	Action    string             `json:"action"`
+	Followers *ModerateFollowers `json:"followers"`
+	Slow      *ModerateSlow      `json:"slow"`
+	VIP       *ModerateUser      `json:"vip"`
+	Unvip     *ModerateUser      `json:"unvip"`
+	Mod       *ModerateUser      `json:"mod"`
+	Unmod     *ModerateUser      `json:"unmod"`
+	Ban       *ModerateBan       `json:"ban"`
+	Unban     *ModerateUser      `json:"unban"`
+	Timeout   *ModerateTimeout   `json:"timeout"`
+	Untimeout *ModerateUser      `json:"untimeout"`
+	Raid      *ModerateRaid      `json:"raid"`
+	Unraid    *ModerateUser      `json:"unraid"`
+	Delete    *ModerateDelete    `json:"delete"`
+	// Set for the add_blocked_term, add_permitted_term, remove_blocked_term, and remove_permitted_term actions.
+	AutomodTerms *ModerateAutomodTerms `json:"automod_terms"`
+	// Set for the approve_unban_request and deny_unban_request actions.
+	UnbanRequest *ModerateUnbanRequest `json:"unban_request"`
+	Warn         *ModerateWarn         `json:"warn"`
+	// Set instead of the fields above for actions in another channel of a shared chat session.
+	SharedChatBan       *ModerateBan     `json:"shared_chat_ban"`
+	SharedChatUnban     *ModerateUser    `json:"shared_chat_unban"`
+	SharedChatTimeout   *ModerateTimeout `json:"shared_chat_timeout"`
+	SharedChatUntimeout *ModerateUser    `json:"shared_chat_untimeout"`
+	SharedChatDelete    *ModerateDelete  `json:"shared_chat_delete"`
+}
+
+// ModerateUser is the user a moderator action targets, eg: the user who was made a VIP.
+type ModerateUser struct {
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+}
+
+// ModerateFollowers is the followers-only mode a moderator turned on.
+type ModerateFollowers struct {
+	// How long users must have followed the channel to chat.
+	FollowDurationMinutes int `json:"follow_duration_minutes"`
+}
+
+// ModerateSlow is the slow mode a moderator turned on.
+type ModerateSlow struct {
+	// How long users must wait between messages.
+	WaitTimeSeconds int `json:"wait_time_seconds"`
+}
+
+// ModerateBan is a user a moderator banned.
+type ModerateBan struct {
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+	// Empty if the moderator gave no reason.
+	Reason string `json:"reason"`
+}
+
+// ModerateTimeout is a user a moderator timed out.
+type ModerateTimeout struct {
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+	// Empty if the moderator gave no reason.
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ModerateRaid is a raid a moderator started.
+type ModerateRaid struct {
+	// The raided channel.
+	UserID      string `json:"user_id"`
+	UserLogin   string `json:"user_login"`
+	UserName    string `json:"user_name"`
+	ViewerCount int    `json:"viewer_count"`
+}
+
+// ModerateDelete is a chat message a moderator deleted.
+type ModerateDelete struct {
+	// The user who sent the message.
+	UserID      string `json:"user_id"`
+	UserLogin   string `json:"user_login"`
+	UserName    string `json:"user_name"`
+	MessageID   string `json:"message_id"`
+	MessageBody string `json:"message_body"`
+}
+
+// ModerateAutomodTerms are the AutoMod terms a moderator added or removed.
+type ModerateAutomodTerms struct {
+	// Either "add" or "remove".
+	Action string `json:"action"`
+	// Either "blocked" or "permitted".
+	List  string   `json:"list"`
+	Terms []string `json:"terms"`
+	// Whether the terms were added by resolving an AutoMod hold.
+	FromAutomod bool `json:"from_automod"`
+}
+
+// ModerateUnbanRequest is an unban request a moderator approved or denied.
+type ModerateUnbanRequest struct {
+	IsApproved bool `json:"is_approved"`
+	// The user who requested to be unbanned.
+	UserID           string `json:"user_id"`
+	UserLogin        string `json:"user_login"`
+	UserName         string `json:"user_name"`
+	ModeratorMessage string `json:"moderator_message"`
+}
+
+// ModerateWarn is a warning a moderator sent to a user.
+type ModerateWarn struct {
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+	// Empty if the moderator gave no reason.
+	Reason string `json:"reason"`
+	// The chat rules the moderator cited, nil if none.
+	ChatRulesCited []string `json:"chat_rules_cited"`
+}
@@ -0,0 +1,73 @@
+package events
+
+import "time"
+
+// ChannelPollBeginEvent is the event of a channel.poll.begin notification, version 1.
+type ChannelPollBeginEvent struct {
+	// The ID of the poll.
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The question of the poll.
+	Title string `json:"title"`
+	// The choices of the poll, without votes.
+	Choices []PollChoice `json:"choices"`
+	// Deprecated by Twitch, bits voting is always disabled.
+	BitsVoting          PollVoting `json:"bits_voting"`
+	ChannelPointsVoting PollVoting `json:"channel_points_voting"`
+	StartedAt           time.Time  `json:"started_at"`
+	EndsAt              time.Time  `json:"ends_at"`
+}
+
+// ChannelPollProgressEvent is the event of a channel.poll.progress notification, version 1, sent when a user votes.
+type ChannelPollProgressEvent struct {
+	// The ID of the poll.
+	ID                   string       `json:"id"`
+	BroadcasterUserID    string       `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string       `json:"broadcaster_user_login"`
+	BroadcasterUserName  string       `json:"broadcaster_user_name"`
+	Title                string       `json:"title"`
+	Choices              []PollChoice `json:"choices"`
+	// Deprecated by Twitch, bits voting is always disabled.
+	BitsVoting          PollVoting `json:"bits_voting"`
+	ChannelPointsVoting PollVoting `json:"channel_points_voting"`
+	StartedAt           time.Time  `json:"started_at"`
+	EndsAt              time.Time  `json:"ends_at"`
+}
+
+// ChannelPollEndEvent is the event of a channel.poll.end notification, version 1.
+type ChannelPollEndEvent struct {
+	// The ID of the poll.
+	ID                   string       `json:"id"`
+	BroadcasterUserID    string       `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string       `json:"broadcaster_user_login"`
+	BroadcasterUserName  string       `json:"broadcaster_user_name"`
+	Title                string       `json:"title"`
+	Choices              []PollChoice `json:"choices"`
+	// Deprecated by Twitch, bits voting is always disabled.
+	BitsVoting          PollVoting `json:"bits_voting"`
+	ChannelPointsVoting PollVoting `json:"channel_points_voting"`
+	// One of "completed", "archived", or "terminated".
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+}
+
+// PollChoice is a choice of a poll. The votes are zero in ChannelPollBeginEvent.
+type PollChoice struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Deprecated by Twitch, always zero.
+	BitsVotes int `json:"bits_votes"`
+	// The number of votes cast with channel points.
+	ChannelPointsVotes int `json:"channel_points_votes"`
+	// The total number of votes, including ChannelPointsVotes.
+	Votes int `json:"votes"`
+}
+
+// PollVoting is whether users can buy additional votes, and at which price.
+type PollVoting struct {
+	IsEnabled     bool `json:"is_enabled"`
+	AmountPerVote int  `json:"amount_per_vote"`
+}
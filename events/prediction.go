@@ -0,0 +1,89 @@
+package events
+
+import "time"
+
+// ChannelPredictionBeginEvent is the event of a channel.prediction.begin notification, version 1.
+type ChannelPredictionBeginEvent struct {
+	// The ID of the prediction.
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	Title                string `json:"title"`
+	// The outcomes of the prediction, without predictions.
+	Outcomes  []PredictionOutcome `json:"outcomes"`
+	StartedAt time.Time           `json:"started_at"`
+	// When predictions are no longer accepted.
+	LocksAt time.Time `json:"locks_at"`
+}
+
+// ChannelPredictionProgressEvent is the event of a channel.prediction.progress notification, version 1, sent when a
+// user makes a prediction.
+type ChannelPredictionProgressEvent struct {
+	// The ID of the prediction.
+	ID                   string              `json:"id"`
+	BroadcasterUserID    string              `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string              `json:"broadcaster_user_login"`
+	BroadcasterUserName  string              `json:"broadcaster_user_name"`
+	Title                string              `json:"title"`
+	Outcomes             []PredictionOutcome `json:"outcomes"`
+	StartedAt            time.Time           `json:"started_at"`
+	LocksAt              time.Time           `json:"locks_at"`
+}
+
+// ChannelPredictionLockEvent is the event of a channel.prediction.lock notification, version 1.
+type ChannelPredictionLockEvent struct {
+	// The ID of the prediction.
+	ID                   string              `json:"id"`
+	BroadcasterUserID    string              `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string              `json:"broadcaster_user_login"`
+	BroadcasterUserName  string              `json:"broadcaster_user_name"`
+	Title                string              `json:"title"`
+	Outcomes             []PredictionOutcome `json:"outcomes"`
+	StartedAt            time.Time           `json:"started_at"`
+	LockedAt             time.Time           `json:"locked_at"`
+}
+
+// ChannelPredictionEndEvent is the event of a channel.prediction.end notification, version 1.
+type ChannelPredictionEndEvent struct {
+	// The ID of the prediction.
+	ID                   string              `json:"id"`
+	BroadcasterUserID    string              `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string              `json:"broadcaster_user_login"`
+	BroadcasterUserName  string              `json:"broadcaster_user_name"`
+	Title                string              `json:"title"`
+	Outcomes             []PredictionOutcome `json:"outcomes"`
+	// The ID of the outcome that won, empty if the prediction was canceled.
+	WinningOutcomeID string `json:"winning_outcome_id"`
+	// One of "resolved" or "canceled".
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+}
+
+// PredictionOutcome is an outcome of a prediction. Users, ChannelPoints, and TopPredictors are empty in
+// ChannelPredictionBeginEvent.
+type PredictionOutcome struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// One of "pink" or "blue".
+	Color string `json:"color"`
+	// The number of users who predicted the outcome.
+	Users int `json:"users"`
+	// The number of channel points spent on the outcome.
+	ChannelPoints int `json:"channel_points"`
+	// Up to 10 users who spent the most channel points on the outcome.
+	TopPredictors []Predictor `json:"top_predictors"`
+}
+
+// Predictor is a user who made a prediction.
+type Predictor struct {
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+	// The number of channel points won, nil until the prediction ended. Zero if the user lost, or the prediction
+	// was canceled.
+	ChannelPointsWon *int `json:"channel_points_won"`
+	// The number of channel points spent.
+	ChannelPointsUsed int `json:"channel_points_used"`
+}
@@ -0,0 +1,27 @@
+package events
+
+import "time"
+
+// ChannelShieldModeBeginEvent is the event of a channel.shield_mode.begin notification, version 1.
+type ChannelShieldModeBeginEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The moderator who activated Shield Mode.
+	ModeratorUserID    string    `json:"moderator_user_id"`
+	ModeratorUserLogin string    `json:"moderator_user_login"`
+	ModeratorUserName  string    `json:"moderator_user_name"`
+	StartedAt          time.Time `json:"started_at"`
+}
+
+// ChannelShieldModeEndEvent is the event of a channel.shield_mode.end notification, version 1.
+type ChannelShieldModeEndEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The moderator who deactivated Shield Mode.
+	ModeratorUserID    string    `json:"moderator_user_id"`
+	ModeratorUserLogin string    `json:"moderator_user_login"`
+	ModeratorUserName  string    `json:"moderator_user_name"`
+	EndedAt            time.Time `json:"ended_at"`
+}
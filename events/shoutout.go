@@ -0,0 +1,41 @@
+package events
+
+import "time"
+
+// ChannelShoutoutCreateEvent is the event of a channel.shoutout.create notification, version 1, sent when the
+// broadcaster gives a shoutout.
+type ChannelShoutoutCreateEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The channel that received the shoutout.
+	ToBroadcasterUserID    string `json:"to_broadcaster_user_id"`
+	ToBroadcasterUserLogin string `json:"to_broadcaster_user_login"`
+	ToBroadcasterUserName  string `json:"to_broadcaster_user_name"`
+	// The moderator who sent the shoutout, the broadcaster if they sent it themselves.
+	ModeratorUserID    string `json:"moderator_user_id"`
+	ModeratorUserLogin string `json:"moderator_user_login"`
+	ModeratorUserName  string `json:"moderator_user_name"`
+	// The number of users watching the stream when the shoutout was sent.
+	ViewerCount int       `json:"viewer_count"`
+	StartedAt   time.Time `json:"started_at"`
+	// When the broadcaster may send the next shoutout.
+	CooldownEndsAt time.Time `json:"cooldown_ends_at"`
+	// When the broadcaster may send the next shoutout to the same channel.
+	TargetCooldownEndsAt time.Time `json:"target_cooldown_ends_at"`
+}
+
+// ChannelShoutoutReceiveEvent is the event of a channel.shoutout.receive notification, version 1, sent when the
+// broadcaster receives a shoutout.
+type ChannelShoutoutReceiveEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The channel that sent the shoutout.
+	FromBroadcasterUserID    string `json:"from_broadcaster_user_id"`
+	FromBroadcasterUserLogin string `json:"from_broadcaster_user_login"`
+	FromBroadcasterUserName  string `json:"from_broadcaster_user_name"`
+	// The number of users watching the sending channel's stream when the shoutout was sent.
+	ViewerCount int       `json:"viewer_count"`
+	StartedAt   time.Time `json:"started_at"`
+}
@@ -0,0 +1,22 @@
+package events
+
+import "time"
+
+// StreamOnlineEvent is the event of a stream.online notification, version 1.
+type StreamOnlineEvent struct {
+	// The ID of the stream.
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The stream type, one of "live", "playlist", "watch_party", "premiere", or "rerun".
+	Type      string    `json:"type"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// StreamOfflineEvent is the event of a stream.offline notification, version 1.
+type StreamOfflineEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+}
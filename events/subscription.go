@@ -0,0 +1,88 @@
+package events
+
+// ChannelSubscribeEvent is the event of a channel.subscribe notification, version 1.
+type ChannelSubscribeEvent struct {
+	// The user who subscribed.
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The tier of the subscription, one of "1000", "2000", or "3000".
+	Tier string `json:"tier"`
+	// Whether the subscription is a gift.
+	IsGift bool `json:"is_gift"`
+}
+
+// ChannelSubscriptionEndEvent is the event of a channel.subscription.end notification, version 1.
+type ChannelSubscriptionEndEvent struct {
+	// The user whose subscription ended.
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The tier of the subscription that ended, one of "1000", "2000", or "3000".
+	Tier string `json:"tier"`
+	// Whether the subscription was a gift.
+	IsGift bool `json:"is_gift"`
+}
+
+// ChannelSubscriptionGiftEvent is the event of a channel.subscription.gift notification, version 1.
+type ChannelSubscriptionGiftEvent struct {
+	// The user who sent the gift, empty if IsAnonymous.
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The number of subscriptions in the gift.
+	Total int `json:"total"`
+	// The tier of the gifted subscriptions, one of "1000", "2000", or "3000".
+	Tier string `json:"tier"`
+	// The number of subscriptions the user gifted in the channel so far. Nil if IsAnonymous, or if the user chose
+	// not to share it.
+	CumulativeTotal *int `json:"cumulative_total"`
+	IsAnonymous     bool `json:"is_anonymous"`
+}
+
+// ChannelSubscriptionMessageEvent is the event of a channel.subscription.message notification, version 1, sent when a
+// user shares a resubscription in chat.
+type ChannelSubscriptionMessageEvent struct {
+	// The user who resubscribed.
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	// The tier of the subscription, one of "1000", "2000", or "3000".
+	Tier    string              `json:"tier"`
+	Message SubscriptionMessage `json:"message"`
+	// The total number of months the user has been subscribed.
+	CumulativeMonths int `json:"cumulative_months"`
+	// The number of consecutive months the user has been subscribed, nil if the user chose not to share it.
+	StreakMonths *int `json:"streak_months"`
+	// The number of months of the subscription, eg: 6 for a 6 month subscription bought at once.
+	DurationMonths int `json:"duration_months"`
+}
+
+// SubscriptionMessage is the message a user sent with a resubscription.
+type SubscriptionMessage struct {
+	Text string `json:"text"`
+	// The emotes in Text, nil if there are none.
+	Emotes []MessageEmote `json:"emotes"`
+}
+
+// MessageEmote is the position of an emote in the text of a message.
+type MessageEmote struct {
+	// Index of the first character of the emote in the text.
+	Begin int `json:"begin"`
+	// Index of the last character of the emote in the text.
+	End int `json:"end"`
+	// The ID of the emote.
+	ID string `json:"id"`
+}
@@ -0,0 +1,72 @@
+package twitchwh
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// Names of the counters published under the "twitchwh" expvar map.
+const (
+	expvarEventsHandled     = "events_handled"
+	expvarDuplicateEvents   = "duplicate_events"
+	expvarSignatureFailures = "signature_failures"
+	expvarTokenRefreshes    = "token_refreshes"
+	expvarShedEvents        = "shed_events"
+	expvarDroppedEvents     = "dropped_events"
+)
+
+var (
+	expvarOnce sync.Once
+	expvarMap  *expvar.Map
+)
+
+// expvarCounters returns the process-wide "twitchwh" expvar map, publishing it on first use.
+// expvar.Publish panics on duplicate names, so every client with ExpvarMetrics enabled shares the same map.
+func expvarCounters() *expvar.Map {
+	expvarOnce.Do(func() {
+		expvarMap = expvar.NewMap("twitchwh")
+		for _, name := range []string{expvarEventsHandled, expvarDuplicateEvents, expvarSignatureFailures, expvarTokenRefreshes, expvarShedEvents, expvarDroppedEvents} {
+			expvarMap.Add(name, 0)
+		}
+	})
+	return expvarMap
+}
+
+// expvarMetricsHook is the MetricsHook used when ClientConfig.ExpvarMetrics is enabled.
+type expvarMetricsHook struct {
+	NoopMetricsHook
+	counters *expvar.Map
+}
+
+func newExpvarMetricsHook() *expvarMetricsHook {
+	return &expvarMetricsHook{counters: expvarCounters()}
+}
+
+func (e *expvarMetricsHook) EventReceived(string) {
+	e.counters.Add(expvarEventsHandled, 1)
+}
+
+func (e *expvarMetricsHook) DuplicateEvent(string) {
+	e.counters.Add(expvarDuplicateEvents, 1)
+}
+
+func (e *expvarMetricsHook) HandlerFinished(string, time.Duration, error) {}
+
+func (e *expvarMetricsHook) SignatureRejected() {
+	e.counters.Add(expvarSignatureFailures, 1)
+}
+
+func (e *expvarMetricsHook) TokenRefreshed() {
+	e.counters.Add(expvarTokenRefreshes, 1)
+}
+
+func (e *expvarMetricsHook) NotificationShed(string) {
+	e.counters.Add(expvarShedEvents, 1)
+}
+
+func (e *expvarMetricsHook) ResponseWritten(string, int, time.Duration) {}
+
+func (e *expvarMetricsHook) NotificationDropped(string, string) {
+	e.counters.Add(expvarDroppedEvents, 1)
+}
@@ -0,0 +1,46 @@
+package twitchwh
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func expvarValue(name string) int64 {
+	counter, ok := expvarCounters().Get(name).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return counter.Value()
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, ExpvarMetrics: true})
+	names := []string{expvarEventsHandled, expvarDuplicateEvents, expvarSignatureFailures}
+	before := make(map[string]int64)
+	for _, name := range names {
+		before[name] = expvarValue(name)
+	}
+
+	for _, id := range []string{"a", "a"} {
+		w := httptest.NewRecorder()
+		c.Handler(w, signedRequest(id, messageTypeNotification, chatMessageBody))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+	}
+	r := signedRequest("b", messageTypeNotification, chatMessageBody)
+	r.Header.Set(twitchMessageSignature, "sha256=invalid")
+	c.Handler(httptest.NewRecorder(), r)
+
+	// The map is shared by every client in the process, so only the difference is checked
+	for _, name := range names {
+		if got := expvarValue(name) - before[name]; got != 1 {
+			t.Errorf("Expected %s to grow by 1, got %d", name, got)
+		}
+	}
+	if expvar.Get("twitchwh") == nil {
+		t.Error("Expected the twitchwh map to be published")
+	}
+}
@@ -0,0 +1,225 @@
+// Package grpcfanout provides an optional gRPC service that streams EventSub notifications to internal services.
+//
+// Server implements twitchwh.Sink, add it to a client to receive notifications, and http.Handler, serving the
+// Fanout service defined in fanout.proto. Clients open a server-streaming Subscribe RPC filtered by event type
+// and broadcaster, and receive notifications in real time.
+//
+// gRPC requires HTTP/2. net/http negotiates it automatically over TLS:
+//
+//	fanout := grpcfanout.New(grpcfanout.Options{})
+//	client.AddSink(fanout)
+//
+//	mux := http.NewServeMux()
+//	mux.Handle(grpcfanout.SubscribePath, fanout)
+//	go http.ListenAndServeTLS(":8443", "cert.pem", "key.pem", mux)
+//
+// For plaintext HTTP/2 (h2c), wrap the handler with golang.org/x/net/http2/h2c.
+//
+// The package does not depend on the gRPC or protobuf runtimes, generate a client from fanout.proto in your language of choice.
+package grpcfanout
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// SubscribePath is the HTTP path of the Subscribe RPC.
+const SubscribePath = "/twitchwh.grpcfanout.v1.Fanout/Subscribe"
+
+// gRPC status codes used by the server.
+// See: https://grpc.github.io/grpc/core/md_doc_statuscodes.html
+const (
+	statusOK            = "0"
+	statusInvalidArg    = "3"
+	statusUnimplemented = "12"
+	statusInternal      = "13"
+)
+
+// Maximum size of a SubscribeRequest.
+const maxRequestSize = 64 * 1024
+
+// Options configures a Server.
+type Options struct {
+	// Number of notifications buffered per client. Notifications are dropped for clients whose buffer is full,
+	// so a slow client can't block the others. Defaults to 256.
+	BufferSize int
+}
+
+type stream struct {
+	filter  subscribeRequest
+	events  chan []byte
+	dropped atomic.Int64
+}
+
+func (s *stream) matches(n twitchwh.Notification) bool {
+	if len(s.filter.types) > 0 && !slices.Contains(s.filter.types, n.Subscription.Type) {
+		return false
+	}
+	if len(s.filter.broadcasterIDs) > 0 && !slices.Contains(s.filter.broadcasterIDs, n.BroadcasterUserID()) {
+		return false
+	}
+	return true
+}
+
+// Server fans out notifications to gRPC clients.
+type Server struct {
+	options Options
+	mu      sync.RWMutex
+	streams map[*stream]struct{}
+}
+
+// New creates a new fanout server.
+func New(options Options) *Server {
+	if options.BufferSize <= 0 {
+		options.BufferSize = 256
+	}
+	return &Server{
+		options: options,
+		streams: make(map[*stream]struct{}),
+	}
+}
+
+// Clients returns the number of connected clients.
+func (s *Server) Clients() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.streams)
+}
+
+// Publish implements twitchwh.Sink. It never blocks on slow clients.
+func (s *Server) Publish(ctx context.Context, n twitchwh.Notification) error {
+	var message []byte
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for st := range s.streams {
+		if !st.matches(n) {
+			continue
+		}
+		if message == nil {
+			var err error
+			message, err = encodeNotification(n)
+			if err != nil {
+				return err
+			}
+		}
+		select {
+		case st.events <- message:
+		default:
+			st.dropped.Add(1)
+		}
+	}
+	return nil
+}
+
+func encodeNotification(n twitchwh.Notification) ([]byte, error) {
+	subscription, err := json.Marshal(n.Subscription)
+	if err != nil {
+		return nil, err
+	}
+	var b []byte
+	b = appendStringField(b, 1, n.MessageID)
+	if !n.Timestamp.IsZero() {
+		b = appendStringField(b, 2, n.Timestamp.Format(time.RFC3339Nano))
+	}
+	b = appendStringField(b, 3, n.Subscription.ID)
+	b = appendStringField(b, 4, n.Subscription.Type)
+	b = appendStringField(b, 5, n.Subscription.Version)
+	b = appendStringField(b, 6, n.BroadcasterUserID())
+	b = appendBytesField(b, 7, n.Event)
+	b = appendBytesField(b, 8, subscription)
+	return b, nil
+}
+
+// ServeHTTP implements the Subscribe RPC.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	if r.ProtoMajor != 2 || r.Method != http.MethodPost || r.URL.Path != SubscribePath {
+		writeStatus(w, statusUnimplemented, "unknown method or protocol")
+		return
+	}
+
+	req, err := readRequest(r.Body)
+	if err != nil {
+		writeStatus(w, statusInvalidArg, err.Error())
+		return
+	}
+
+	st := &stream{
+		filter: req,
+		events: make(chan []byte, s.options.BufferSize),
+	}
+	s.mu.Lock()
+	s.streams[st] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, st)
+		s.mu.Unlock()
+	}()
+
+	flusher, _ := w.(http.Flusher)
+	w.WriteHeader(http.StatusOK)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case message := <-st.events:
+			if err := writeMessage(w, message); err != nil {
+				w.Header().Set("Grpc-Status", statusInternal)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// readRequest reads a single length-prefixed, uncompressed gRPC message.
+func readRequest(body io.Reader) (subscribeRequest, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return subscribeRequest{}, err
+	}
+	if prefix[0] != 0 {
+		return subscribeRequest{}, errCompressed
+	}
+	length := binary.BigEndian.Uint32(prefix[1:])
+	if length > maxRequestSize {
+		return subscribeRequest{}, errTooLarge
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(body, message); err != nil {
+		return subscribeRequest{}, err
+	}
+	return decodeSubscribeRequest(message)
+}
+
+func writeMessage(w io.Writer, message []byte) error {
+	var prefix [5]byte
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(message)))
+	if _, err := w.Write(prefix[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(message)
+	return err
+}
+
+func writeStatus(w http.ResponseWriter, status string, message string) {
+	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Grpc-Status", status)
+	w.Header().Set("Grpc-Message", message)
+}
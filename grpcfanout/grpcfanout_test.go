@@ -0,0 +1,66 @@
+package grpcfanout
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestSubscribe(t *testing.T) {
+	fanout := New(Options{})
+	server := httptest.NewUnstartedServer(fanout)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	var request []byte
+	request = appendStringField(request, 1, "stream.online")
+	body := make([]byte, 5, 5+len(request))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(request)))
+	body = append(body, request...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "POST", server.URL+SubscribePath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/grpc")
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	for fanout.Clients() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	fanout.Publish(ctx, twitchwh.Notification{MessageID: "ignored", Subscription: twitchwh.Subscription{Type: "stream.offline"}})
+	fanout.Publish(ctx, twitchwh.Notification{
+		MessageID:    "abc",
+		Subscription: twitchwh.Subscription{ID: "sub", Type: "stream.online"},
+		Event:        json.RawMessage(`{"broadcaster_user_id":"1"}`),
+	})
+
+	var prefix [5]byte
+	if _, err := io.ReadFull(res.Body, prefix[:]); err != nil {
+		t.Fatal(err)
+	}
+	message := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	if _, err := io.ReadFull(res.Body, message); err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := encodeNotification(twitchwh.Notification{
+		MessageID:    "abc",
+		Subscription: twitchwh.Subscription{ID: "sub", Type: "stream.online"},
+		Event:        json.RawMessage(`{"broadcaster_user_id":"1"}`),
+	})
+	if !bytes.Equal(message, expected) {
+		t.Fatalf("Unexpected message %x", message)
+	}
+}
@@ -0,0 +1,86 @@
+package grpcfanout
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Minimal protobuf encoding for the messages in fanout.proto, so the package doesn't depend on the protobuf runtime.
+
+const (
+	wireVarint = 0
+	wire64     = 1
+	wireBytes  = 2
+	wire32     = 5
+)
+
+var errMalformed = errors.New("malformed protobuf message")
+
+type subscribeRequest struct {
+	types          []string
+	broadcasterIDs []string
+}
+
+func decodeSubscribeRequest(b []byte) (subscribeRequest, error) {
+	var req subscribeRequest
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return req, errMalformed
+		}
+		b = b[n:]
+		field, wireType := tag>>3, tag&7
+		switch wireType {
+		case wireVarint:
+			_, n = binary.Uvarint(b)
+			if n <= 0 {
+				return req, errMalformed
+			}
+			b = b[n:]
+		case wire64:
+			if len(b) < 8 {
+				return req, errMalformed
+			}
+			b = b[8:]
+		case wire32:
+			if len(b) < 4 {
+				return req, errMalformed
+			}
+			b = b[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < length {
+				return req, errMalformed
+			}
+			value := string(b[n : n+int(length)])
+			b = b[n+int(length):]
+			switch field {
+			case 1:
+				req.types = append(req.types, value)
+			case 2:
+				req.broadcasterIDs = append(req.broadcasterIDs, value)
+			}
+		default:
+			return req, errMalformed
+		}
+	}
+	return req, nil
+}
+
+func appendBytesField(b []byte, field int, value []byte) []byte {
+	if len(value) == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+func appendStringField(b []byte, field int, value string) []byte {
+	return appendBytesField(b, field, []byte(value))
+}
+
+var (
+	errCompressed = errors.New("compressed requests are not supported")
+	errTooLarge   = errors.New("request is too large")
+)
@@ -1,9 +1,14 @@
 package twitchwh
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"io"
+	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 )
 
 // List of request headers sent from Twitch
@@ -11,7 +16,7 @@ import (
 const twitchMessageID = "Twitch-Eventsub-Message-Id"
 const twitchMessageTimestamp = "Twitch-Eventsub-Message-Timestamp"
 const twitchMessageSignature = "Twitch-Eventsub-Message-Signature"
-const messageType = "Twitch-Eventsub-Message-Type"
+const twitchMessageType = "Twitch-Eventsub-Message-Type"
 
 // Message types
 const messageTypeNotification = "notification"
@@ -24,6 +29,91 @@ type webhookPayload struct {
 	Event        json.RawMessage `json:"event"`
 }
 
+// notificationHeader is the part of a notification needed to deduplicate, sample, and dispatch it.
+// Handlers only receive the event, so decoding the rest of the subscription is wasted work for them.
+type notificationHeader struct {
+	Subscription struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	} `json:"subscription"`
+}
+
+// filteredNotificationHeader is a notificationHeader with the condition, for the broadcaster lists.
+type filteredNotificationHeader struct {
+	Subscription struct {
+		ID        string    `json:"id"`
+		Type      string    `json:"type"`
+		Condition Condition `json:"condition"`
+	} `json:"subscription"`
+}
+
+// notificationEvent is the event of a notification, decoded once the notification is accepted.
+type notificationEvent struct {
+	Event json.RawMessage `json:"event"`
+}
+
+// decodeNotificationHeader decodes the subscription of a notification without its event. Only the ID and type are
+// decoded, unless the broadcaster lists need the condition. Notifications rejected as duplicates, by the broadcaster
+// lists, or by their sample rate are never decoded further.
+func (c *Client) decodeNotificationHeader(body []byte) (Subscription, error) {
+	if c.broadcasterFilterSet.Load() {
+		var header filteredNotificationHeader
+		err := json.Unmarshal(body, &header)
+		return Subscription{ID: header.Subscription.ID, Type: header.Subscription.Type, Condition: header.Subscription.Condition}, err
+	}
+	var header notificationHeader
+	err := json.Unmarshal(body, &header)
+	return Subscription{ID: header.Subscription.ID, Type: header.Subscription.Type}, err
+}
+
+// decodeNotification decodes the event of an accepted notification. The full subscription is only decoded if it is
+// needed, see needsSubscription.
+func (c *Client) decodeNotification(n Notification, body []byte) (Notification, error) {
+	if c.needsSubscription() {
+		var payload webhookPayload
+		err := json.Unmarshal(body, &payload)
+		n.Subscription = payload.Subscription
+		n.Event = payload.Event
+		return n, err
+	}
+	var payload notificationEvent
+	err := json.Unmarshal(body, &payload)
+	n.Event = payload.Event
+	return n, err
+}
+
+// needsSubscription reports whether notifications must be decoded with the full subscription, because the outbox,
+// sinks, watchdog, handler dead letter, event version mappings, or batch handlers read more than its ID and type.
+func (c *Client) needsSubscription() bool {
+	if c.outbox != nil || c.sinks.len() > 0 || c.watchdog.watching() || c.handlerDeadLetter != nil {
+		return true
+	}
+	c.handlersMu.RLock()
+	defer c.handlersMu.RUnlock()
+	return len(c.eventMappers) > 0 || len(c.batchers) > 0
+}
+
+// bodyPool holds the buffers request bodies are read into.
+var bodyPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// maxPooledBody is the capacity above which a body buffer is not returned to the pool, so a single large request
+// doesn't keep its memory alive.
+const maxPooledBody = 64 << 10
+
+// maxPreallocatedBody caps the buffer allocated up front based on the Content-Length header,
+// so a request can't make the handler allocate more than it actually sends.
+const maxPreallocatedBody = 4 << 20
+
+func putBody(body *bytes.Buffer) {
+	if body.Cap() <= maxPooledBody {
+		bodyPool.Put(body)
+	}
+}
+
 // Handler is the HTTP handler for requests from Twitch.
 // It is up to you to assign this handler to the correct path according to your setup
 //
@@ -36,71 +126,228 @@ type webhookPayload struct {
 //
 // This example assumes https://mydomain.com is pointing to the Go app.
 func (c *Client) Handler(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		c.logger.Printf("Could not read request body: %s", err)
-		w.WriteHeader(500)
+	start := time.Now()
+	w = c.wrapResponseWriter(w, r)
+	if c.mode == ModeAPI {
+		c.sampledLogger.log("api-mode", slog.LevelWarn, "Received request, but the client is in API mode", "remote_addr", c.clientIP(r))
+		c.respond(w, start, "", 404)
+		return
+	}
+	if r.Method != http.MethodPost {
+		// Twitch only sends POST requests, anything else is a probe and not worth verifying
+		c.respondProbe(w, start)
 		return
 	}
+	messageID := r.Header.Get(twitchMessageID)
+	rawTimestamp := r.Header.Get(twitchMessageTimestamp)
+	message_type := r.Header.Get(twitchMessageType)
 
-	hmacMessage := r.Header.Get(twitchMessageID) + r.Header.Get(twitchMessageTimestamp) + string(body)
-	expectedSignature := "sha256=" + generateHmac(c.GetWebhookSecret(), hmacMessage)
-	if verifyHmac(expectedSignature, r.Header.Get(twitchMessageSignature)) {
-		c.logger.Println("Received valid signature")
+	// The body is hashed while it is read, so it is only copied once
+	mac := c.signatureHashes()
+	defer mac.release()
+	io.WriteString(mac, messageID)
+	io.WriteString(mac, rawTimestamp)
+	body := bodyPool.Get().(*bytes.Buffer)
+	defer putBody(body)
+	body.Reset()
+	if r.ContentLength > 0 {
+		// Avoids growing the buffer repeatedly for large bodies, eg: batched drop.entitlement.grant notifications
+		body.Grow(int(min(r.ContentLength, maxPreallocatedBody)))
+	}
+	if _, err := body.ReadFrom(io.TeeReader(r.Body, mac)); err != nil {
+		c.reportError("Could not read request body", err)
+		c.respond(w, start, message_type, 500)
+		return
+	}
 
-		if isMessageTooOld(r.Header.Get(twitchMessageTimestamp)) {
-			w.WriteHeader(204)
-			return
-		}
+	if mac.check(r.Header.Get(twitchMessageSignature)) {
+		c.logger.Debug("Received valid signature")
 
-		var payload webhookPayload
-		err := json.Unmarshal(body, &payload)
-		if err != nil {
-			c.logger.Printf("Could not serialize webhook payload: %s", err)
-			w.WriteHeader(500)
+		timestamp, _ := time.Parse(time.RFC3339, rawTimestamp)
+		if !c.twitchCLI && isTimestampTooOld(timestamp) {
+			c.sampledLogger.log("too-old", slog.LevelDebug, "Message is too old, ignoring...", "message_id", messageID)
+			c.respond(w, start, message_type, c.statusPolicy.TooOld)
 			return
 		}
 
-		message_type := r.Header.Get(messageType)
 		if message_type == messageTypeNotification {
-			c.logger.Printf("Received event for %s ", payload.Subscription.Type)
-			messageID := r.Header.Get(twitchMessageID)
-			if c.handledEventsChecker.IsHandled(messageID) {
-				c.logger.Println("Got request for handled event, ignoring...")
-				w.WriteHeader(204)
+			subscription, err := c.decodeNotificationHeader(body.Bytes())
+			if err != nil {
+				c.reportError("Could not serialize webhook payload", err)
+				c.respond(w, start, message_type, 500)
 				return
-			} else {
-				c.handledEventsChecker.MarkHandled(messageID)
 			}
-
-			if handler, ok := c.handlers[payload.Subscription.Type]; ok {
-				go handler(payload.Event)
+			// Checked first, building the attributes allocates even if debug logging is disabled
+			if c.logger.Enabled(r.Context(), slog.LevelDebug) {
+				c.logger.Debug("Received event", "type", subscription.Type, "message_id", messageID)
+			}
+			notification := Notification{
+				MessageID:    messageID,
+				Timestamp:    timestamp,
+				Subscription: subscription,
+			}
+			var status int
+			if c.responseDeadline > 0 {
+				// Accepting may outlive the request, and with it the pooled body
+				status = c.acceptNotificationBefore(r.Context(), notification, bytes.Clone(body.Bytes()), start.Add(c.responseDeadline))
 			} else {
-				c.logger.Printf("No handler for event %s", payload.Subscription.Type)
+				status = c.acceptNotification(r.Context(), notification, body.Bytes())
 			}
+			c.respond(w, start, message_type, status)
+			return
+		}
 
-			w.WriteHeader(204)
+		// Strings and json.RawMessage are copied by Unmarshal, so the payload doesn't reference the pooled body
+		var payload webhookPayload
+		if err := json.Unmarshal(body.Bytes(), &payload); err != nil {
+			c.reportError("Could not serialize webhook payload", err)
+			c.respond(w, start, message_type, 500)
 			return
 		}
+		subscription := payload.Subscription
 		if message_type == messageTypeVerification {
-			c.logger.Printf("Got challenge request for %s", payload.Subscription.ID)
-			go func() {
-				c.VerifiedSubscriptions <- payload.Subscription.ID
-			}()
-			w.WriteHeader(200)
+			if err := validateChallenge(payload); err != nil {
+				c.reportError("Invalid challenge request", err, "subscription_id", subscription.ID, "remote_addr", c.clientIP(r))
+				c.respond(w, start, message_type, 400)
+				return
+			}
+			if isSelfCheck(subscription) {
+				w.Header().Set("Content-Type", "text/plain")
+				c.respond(w, start, message_type, 200)
+				w.Write([]byte(payload.Challenge))
+				return
+			}
+			c.logger.Debug("Got challenge request", "subscription_id", subscription.ID)
+			c.auditRequest(AuditActionVerified, subscription, "", c.clientIP(r))
+			c.verifications.verified(subscription.ID)
+			if c.verificationRelay != nil {
+				c.publishVerification(subscription.ID)
+			}
+			// Only delivered if someone is receiving, the channel is kept for compatibility
+			select {
+			case c.VerifiedSubscriptions <- subscription.ID:
+			default:
+			}
+			if c.OnChallenge != nil {
+				c.OnChallenge(subscription, payload.Challenge)
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			c.respond(w, start, message_type, 200)
 			w.Write([]byte(payload.Challenge))
 			return
 		}
 		if message_type == messageTypeRevocation {
 			// Subscription was revoked. This could be as simple as a user deactivating or Twitch not reaching the endpoint.
-			c.logger.Printf("Twitch revoked subscription %s", payload.Subscription.ID)
-			if c.OnRevocation != nil {
-				c.OnRevocation(payload.Subscription)
-			}
-			w.WriteHeader(204)
+			c.logger.Warn("Twitch revoked subscription", "subscription_id", subscription.ID, "type", subscription.Type, "status", subscription.Status)
+			c.auditRequest(AuditActionRevoked, subscription, subscription.Status, c.clientIP(r))
+			c.handleRevocation(subscription)
+			c.respond(w, start, message_type, 204)
 			return
 		}
+		c.sampledLogger.log("unknown-message-type", slog.LevelWarn, "Received request with unknown message type", "message_id", messageID,
+			"message_type", message_type)
+		c.respond(w, start, message_type, c.statusPolicy.UnknownMessageType)
 	} else {
-		w.WriteHeader(403)
+		c.sampledLogger.log("invalid-signature", slog.LevelWarn, "Received request with invalid signature", "message_id", messageID, "remote_addr", c.clientIP(r))
+		c.metrics.SignatureRejected()
+		c.respond(w, start, message_type, c.statusPolicy.InvalidSignature)
+	}
+}
+
+// respondProbe answers a request that isn't from Twitch with ClientConfig.ProbeStatus.
+func (c *Client) respondProbe(w http.ResponseWriter, start time.Time) {
+	if c.probeStatus >= 300 {
+		w.Header().Set("Allow", http.MethodPost)
+		c.respond(w, start, "", c.probeStatus)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	c.respond(w, start, "", c.probeStatus)
+	w.Write([]byte("ok"))
+}
+
+// acceptNotification deduplicates, persists, and dispatches a notification. notification has no event yet, see
+// decodeNotificationHeader. The event is decoded from body once the notification passed the deduplication,
+// broadcaster lists, and sample rate. Returns the status code to respond with.
+func (c *Client) acceptNotification(ctx context.Context, notification Notification, body []byte) int {
+	messageID := notification.MessageID
+	eventType := notification.Subscription.Type
+	policy := c.responsePolicy(eventType)
+	if c.handledEventsChecker.IsHandled(messageID) {
+		c.metrics.DuplicateEvent(eventType)
+		c.dedup.duplicates.Add(1)
+		if !policy.DeliverDuplicates {
+			c.sampledLogger.log("duplicate", slog.LevelDebug, "Got request for handled event, ignoring...", "message_id", messageID)
+			return c.statusPolicy.Duplicate
+		}
+	}
+	if reason := c.broadcasterDropReason(notification); reason != "" {
+		c.sampledLogger.log("dropped:"+reason, slog.LevelDebug, "Dropping notification of filtered broadcaster", "type", eventType,
+			"broadcaster_user_id", notification.BroadcasterUserID(), "reason", reason)
+		c.handledEventsChecker.MarkHandled(messageID)
+		c.metrics.NotificationDropped(eventType, reason)
+		return 204
+	}
+	if c.draining.Load() {
+		c.sampledLogger.log("draining", slog.LevelInfo, "Shutting down, rejecting notification", "type", eventType, "message_id", messageID)
+		c.metrics.NotificationShed(eventType)
+		return 503
+	}
+	if c.saturated(eventType) {
+		// Not marked as handled, so Twitch redelivers it
+		c.sampledLogger.log("shed", slog.LevelWarn, "Too many running handlers, rejecting notification", "type", eventType, "message_id", messageID)
+		c.metrics.NotificationShed(eventType)
+		return 503
+	}
+
+	if c.sampledOut(notification) {
+		c.sampledLogger.log("sampled:"+eventType, slog.LevelDebug, "Dropping notification by sample rate", "type", eventType)
+		c.handledEventsChecker.MarkHandled(messageID)
+		c.metrics.EventReceived(eventType)
+		c.metrics.NotificationDropped(eventType, DropReasonSampled)
+		if c.watchdog.watching() {
+			// The watchdog keeps the last seen subscription, which needs the full decode
+			if full, err := c.decodeNotification(notification, body); err == nil {
+				c.watchdog.seen(full.Subscription)
+			}
+		}
+		return 204
+	}
+	notification, err := c.decodeNotification(notification, body)
+	if err != nil {
+		c.reportError("Could not serialize webhook payload", err, "message_id", messageID)
+		return 500
+	}
+	notification, err = c.mapEventVersion(notification)
+	if err != nil {
+		c.reportError("Could not map event version", err, "type", eventType, "version", notification.Subscription.Version, "message_id", messageID)
+		return 500
+	}
+	if c.outbox != nil {
+		// Persist before acknowledging, so Twitch redelivers the event if this fails
+		if err := c.outbox.Store(ctx, notification); err != nil {
+			c.reportError("Could not store notification in outbox", err, "message_id", messageID)
+			return 500
+		}
+	}
+	c.metrics.EventReceived(eventType)
+	c.watchdog.seen(notification.Subscription)
+	c.checkSchema(notification)
+	if policy.HandleBeforeResponse && c.outbox == nil {
+		// Only marked as handled if the handler succeeded, so Twitch's redelivery isn't dropped
+		return c.handleNotificationNow(notification, policy)
+	}
+	if c.outbox != nil {
+		c.handledEventsChecker.MarkHandled(messageID)
+		c.wakeOutbox()
+		return 204
+	}
+	if !c.handleNotification(notification) {
+		// Not marked as handled, so Twitch redelivers it
+		c.sampledLogger.log("shed", slog.LevelWarn, "Handler queue is full, rejecting notification", "type", eventType, "message_id", messageID)
+		c.metrics.NotificationShed(eventType)
+		return 503
 	}
+	c.handledEventsChecker.MarkHandled(messageID)
+	return 204
 }
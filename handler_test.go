@@ -0,0 +1,434 @@
+package twitchwh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWebhookSecret = "0123456789abcdef"
+
+const chatMessageBody = `{"subscription":{"id":"f1c2a387-161a-49f9-a165-0f21d7a4e1c4","type":"channel.chat.message","version":"1",` +
+	`"status":"enabled","cost":0,"condition":{"broadcaster_user_id":"1971641","user_id":"2914196"},` +
+	`"transport":{"method":"webhook","callback":"https://example.com/webhooks/callback"},"created_at":"2023-11-06T18:11:47.492253549Z"},` +
+	`"event":{"broadcaster_user_id":"1971641","broadcaster_user_login":"streamer","broadcaster_user_name":"streamer",` +
+	`"chatter_user_id":"4145994","chatter_user_login":"viewer32","chatter_user_name":"viewer32",` +
+	`"message_id":"cc106a89-1814-919d-454c-f4f2f970aae7","message":{"text":"Hi chat","fragments":[{"type":"text","text":"Hi chat",` +
+	`"cheermote":null,"emote":null,"mention":null}]},"color":"#00FF7F","badges":[{"set_id":"moderator","id":"1","info":""}],` +
+	`"message_type":"text","cheer":null,"reply":null,"channel_points_custom_reward_id":null}}`
+
+// entitlementGrantBody returns a batched drop.entitlement.grant notification with n entitlements.
+func entitlementGrantBody(n int) string {
+	var events strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			events.WriteByte(',')
+		}
+		fmt.Fprintf(&events, `{"id":"bf7c8577-e3e2-4ba0-9fa3-%012d","data":{"organization_id":"9001","category_id":"9002",`+
+			`"category_name":"Fortnite","campaign_id":"9003","user_id":"1234","user_name":"Cool_User","user_login":"cool_user",`+
+			`"entitlement_id":"fb78259e-fb81-4d1b-8333-34a06ffc24c0","benefit_id":"74c52265-e214-48a6-91b9-23b6014e8041",`+
+			`"created_at":"2019-01-28T04:17:53.2600Z"}}`, i)
+	}
+	return `{"subscription":{"id":"f1c2a387-161a-49f9-a165-0f21d7a4e1c4","type":"drop.entitlement.grant","version":"1",` +
+		`"status":"enabled","cost":0,"condition":{"organization_id":"9001","category_id":"9002"},` +
+		`"transport":{"method":"webhook","callback":"https://example.com/webhooks/callback"},"created_at":"2023-11-06T18:11:47.492253549Z"},` +
+		`"events":[` + events.String() + `]}`
+}
+
+// nopEventsChecker never reports events as handled, so benchmarks measure the handler and not the dedup store.
+type nopEventsChecker struct{}
+
+func (nopEventsChecker) IsHandled(messageID string) bool { return false }
+func (nopEventsChecker) MarkHandled(messageID string)    {}
+
+func signedRequest(messageID string, messageType string, body string) *http.Request {
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	r := httptest.NewRequest(http.MethodPost, "/eventsub", bytes.NewBufferString(body))
+	r.Header.Set(twitchMessageID, messageID)
+	r.Header.Set(twitchMessageTimestamp, timestamp)
+	r.Header.Set(twitchMessageSignature, Signature(testWebhookSecret, messageID, timestamp, []byte(body)))
+	r.Header.Set(twitchMessageType, messageType)
+	return r
+}
+
+func TestHandlerNotification(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	received := make(chan json.RawMessage, 1)
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		received <- event
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	select {
+	case event := <-received:
+		var chat struct {
+			ChatterUserID string `json:"chatter_user_id"`
+		}
+		if err := json.Unmarshal(event, &chat); err != nil || chat.ChatterUserID != "4145994" {
+			t.Fatalf("Unexpected event %s", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Handler was not called")
+	}
+
+	r := signedRequest("b", messageTypeNotification, chatMessageBody)
+	r.Header.Set(twitchMessageSignature, "sha256=00")
+	w = httptest.NewRecorder()
+	c.Handler(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestHandlerShedsWhenSaturated(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, MaxConcurrentHandlers: 1})
+	release := make(chan struct{})
+	c.On("channel.chat.message", func(event json.RawMessage) {
+		<-release
+	})
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	c.Handler(w, signedRequest("b", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503, got %d", w.Code)
+	}
+	if c.handledEventsChecker.IsHandled("b") {
+		t.Fatal("Expected shed notification to not be marked as handled")
+	}
+	if shed := c.Stats().Shed; shed != 1 {
+		t.Fatalf("Expected 1 shed notification, got %d", shed)
+	}
+
+	close(release)
+	for deadline := time.Now().Add(time.Second); c.saturated("channel.chat.message"); {
+		if time.Now().After(deadline) {
+			t.Fatal("Handler did not finish")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	w = httptest.NewRecorder()
+	c.Handler(w, signedRequest("b", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204 after the handler finished, got %d", w.Code)
+	}
+}
+
+func TestHandlerDispatchWorkers(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, DispatchMode: DispatchWorkers, DispatchQueueSize: 2})
+	release := make(chan struct{})
+	handled := make(chan string, 3)
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		<-release
+		handled <- MessageIDFromContext(ctx)
+		return nil
+	})
+
+	// The first notification is taken by the worker, the next two fill the queue
+	for _, id := range []string{"a", "b", "c"} {
+		w := httptest.NewRecorder()
+		c.Handler(w, signedRequest(id, messageTypeNotification, chatMessageBody))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204 for %s, got %d", id, w.Code)
+		}
+		for id == "a" && c.runningHandlers.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("d", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503 with a full queue, got %d", w.Code)
+	}
+
+	close(release)
+	for _, id := range []string{"a", "b", "c"} {
+		select {
+		case got := <-handled:
+			if got != id {
+				t.Fatalf("Expected %s, got %s", id, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not handled", id)
+		}
+	}
+}
+
+func TestHandleNotificationFullQueue(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, DispatchMode: DispatchWorkers, DispatchQueueSize: 1})
+	release := make(chan struct{})
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		<-release
+		return nil
+	})
+	n := Notification{MessageID: "a", Subscription: Subscription{Type: "channel.chat.message"}}
+
+	if !c.handleNotification(n) {
+		t.Fatal("Expected the first notification to be accepted")
+	}
+	for c.runningHandlers.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if !c.handleNotification(n) {
+		t.Fatal("Expected the second notification to be queued")
+	}
+	// Must return instead of blocking until the worker is free
+	if c.handleNotification(n) {
+		t.Fatal("Expected the notification to be rejected with a full queue")
+	}
+
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if c.handleNotification(n) {
+		t.Fatal("Expected the notification to be rejected after Shutdown")
+	}
+	// Returns only once the workers stopped
+	c.Close()
+}
+
+func TestHandlerLazyDecode(t *testing.T) {
+	// Only the full decode reads the status, so it fails on this body while the header decode doesn't
+	invalidBody := strings.Replace(chatMessageBody, `"status":"enabled"`, `"status":5`, 1)
+	newSinkClient := func() (*Client, chan Notification) {
+		c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+		published := make(chan Notification, 1)
+		// Sinks need the full subscription
+		c.AddSink(funcSink(func(ctx context.Context, n Notification) error {
+			published <- n
+			return nil
+		}))
+		return c, published
+	}
+
+	tests := []struct {
+		name  string
+		setup func(c *Client)
+	}{
+		{"duplicate", func(c *Client) { c.handledEventsChecker.MarkHandled("a") }},
+		{"denied broadcaster", func(c *Client) { c.DenyBroadcasters("1971641") }},
+		{"sampled", func(c *Client) { c.SetSampleRate("channel.chat.message", 0) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, _ := newSinkClient()
+			test.setup(c)
+			w := httptest.NewRecorder()
+			c.Handler(w, signedRequest("a", messageTypeNotification, invalidBody))
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("Expected the rejected notification not to be fully decoded, got status %d", w.Code)
+			}
+		})
+	}
+
+	c, published := newSinkClient()
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, invalidBody))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected an accepted notification to be fully decoded, got status %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	c.Handler(w, signedRequest("b", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	var want webhookPayload
+	json.Unmarshal([]byte(chatMessageBody), &want)
+	select {
+	case n := <-published:
+		if n.Subscription.Status != "enabled" || n.Subscription.Condition.UserID != "2914196" || !bytes.Equal(n.Event, want.Event) {
+			t.Fatalf("Expected the full subscription and event, got %+v", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the notification to be published")
+	}
+
+	// Without sinks, handlers still receive the full event
+	c = newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	handled := make(chan json.RawMessage, 1)
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		handled <- event
+		return nil
+	})
+	c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+	select {
+	case event := <-handled:
+		if !bytes.Equal(event, want.Event) {
+			t.Fatalf("Expected the full event, got %s", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the notification to be handled")
+	}
+}
+
+func BenchmarkHandlerChatMessage(b *testing.B) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, HandledEventsChecker: nopEventsChecker{}})
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		return nil
+	})
+	requests := make([]*http.Request, b.N)
+	for i := range requests {
+		requests[i] = signedRequest(strconv.Itoa(i), messageTypeNotification, chatMessageBody)
+	}
+	w := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Handler(w, requests[i])
+	}
+}
+
+func BenchmarkHandlerLargeBody(b *testing.B) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, HandledEventsChecker: nopEventsChecker{}})
+	body := entitlementGrantBody(1000)
+	requests := make([]*http.Request, b.N)
+	for i := range requests {
+		requests[i] = signedRequest(strconv.Itoa(i), messageTypeNotification, body)
+	}
+	w := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Handler(w, requests[i])
+	}
+}
+
+type slowChecker struct {
+	*DefaultHandledEventsChecker
+	delay time.Duration
+}
+
+func (s slowChecker) IsHandled(messageID string) bool {
+	time.Sleep(s.delay)
+	return s.DefaultHandledEventsChecker.IsHandled(messageID)
+}
+
+type responseRecorder struct {
+	NoopMetricsHook
+	latencies chan time.Duration
+}
+
+func (r responseRecorder) ResponseWritten(messageType string, status int, latency time.Duration) {
+	r.latencies <- latency
+}
+
+func TestHandlerResponseDeadline(t *testing.T) {
+	hook := responseRecorder{latencies: make(chan time.Duration, 1)}
+	c := newClient(ClientConfig{
+		WebhookSecret:        testWebhookSecret,
+		HandledEventsChecker: slowChecker{NewDefaultHandledEventsChecker(), 500 * time.Millisecond},
+		ResponseDeadline:     20 * time.Millisecond,
+		MetricsHook:          hook,
+	})
+	received := make(chan struct{}, 1)
+	c.On("channel.chat.message", func(event json.RawMessage) {
+		received <- struct{}{}
+	})
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	if latency := <-hook.latencies; latency > 200*time.Millisecond {
+		t.Fatalf("Expected response within the deadline, took %s", latency)
+	}
+	select {
+	case <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected notification to be handled after the response")
+	}
+}
+
+func TestHandlerResponseStats(t *testing.T) {
+	var logs bytes.Buffer
+	c := newClient(ClientConfig{
+		WebhookSecret:         testWebhookSecret,
+		HandledEventsChecker:  slowChecker{NewDefaultHandledEventsChecker(), 20 * time.Millisecond},
+		SlowResponseThreshold: 10 * time.Millisecond,
+		Logger:                slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+
+	c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+	unsigned := signedRequest("b", "unknown", chatMessageBody)
+	unsigned.Header.Set(twitchMessageSignature, "sha256=00")
+	c.Handler(httptest.NewRecorder(), unsigned)
+	responses := c.Stats().Responses
+	if len(responses) != 1 {
+		t.Fatalf("Expected only the notification to be counted, got %+v", responses)
+	}
+	r := responses[messageTypeNotification]
+	if r.Responses != 1 || r.Slow != 1 || r.MaxLatency < 20*time.Millisecond || r.MeanLatency() != r.MaxLatency {
+		t.Fatalf("Unexpected response stats %+v", r)
+	}
+	if !strings.Contains(logs.String(), "Slow response") {
+		t.Fatalf("Expected a slow response warning, got %q", logs.String())
+	}
+}
+
+func TestHandlerProbes(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	w := httptest.NewRecorder()
+	c.Handler(w, httptest.NewRequest(http.MethodHead, "/eventsub", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Fatalf("Expected status 405 with Allow header, got %d", w.Code)
+	}
+
+	c = newClient(ClientConfig{WebhookSecret: testWebhookSecret, ProbeStatus: http.StatusOK})
+	w = httptest.NewRecorder()
+	c.Handler(w, httptest.NewRequest(http.MethodGet, "/eventsub", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Fatalf("Expected status 200 with body ok, got %d %q", w.Code, w.Body.String())
+	}
+	if c.Stats().SignatureFailures != 0 {
+		t.Error("Expected probes not to count as signature failures")
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status *int
+}
+
+func (s statusRecorder) WriteHeader(status int) {
+	*s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func TestWrapResponseWriter(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	var status int
+	c.WrapResponseWriter(func(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+		w.Header().Set("Cache-Control", "no-store")
+		return statusRecorder{w, &status}
+	})
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if status != http.StatusNoContent || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Expected wrapper to see status 204 and set headers, got %d %v", status, w.Header())
+	}
+}
@@ -0,0 +1,137 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Pinger can optionally be implemented by a HandledEventsChecker backed by an external store.
+// Client.Readyz reports the store as unavailable if Ping returns an error.
+type Pinger interface {
+	Ping() error
+}
+
+// HealthReport is the state reported by Client.Health, Client.Healthz, and Client.Readyz.
+type HealthReport struct {
+	// "ok" if the client is ready to receive events, "unavailable" otherwise.
+	Status string `json:"status"`
+	// Whether the last token generation or validation succeeded. Always true for clients that don't manage an app
+	// token, eg: in ModeReceiver or with ClientConfig.Helix.
+	TokenValid bool `json:"token_valid"`
+	// Time of the last Helix request that returned a successful status code. Nil if none were made yet.
+	LastHelixSuccess *time.Time `json:"last_helix_success,omitempty"`
+	// Number of subscriptions per status, as of the last call to GetSubscriptions. Nil if it was never called.
+	Subscriptions map[string]int `json:"subscriptions,omitempty"`
+	// Time the subscription counts were fetched.
+	SubscriptionsCheckedAt *time.Time `json:"subscriptions_checked_at,omitempty"`
+	// "ok", "unknown" if the HandledEventsChecker does not implement Pinger, or the error returned by Ping.
+	DedupStore string `json:"dedup_store"`
+	// Whether Client.Shutdown was called.
+	Draining bool `json:"draining,omitempty"`
+}
+
+// healthState is updated by the rest of the client and read by the health handlers.
+type healthState struct {
+	mu                     sync.RWMutex
+	tokenValid             bool
+	lastHelixSuccess       time.Time
+	subscriptions          map[string]int
+	subscriptionsCheckedAt time.Time
+
+	// Whether the client generated an app token, tokenValid is meaningless otherwise
+	tokenManaged bool
+}
+
+func (h *healthState) setTokenValid(valid bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokenValid = valid
+	h.tokenManaged = true
+}
+
+func (h *healthState) helixSucceeded() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastHelixSuccess = time.Now()
+}
+
+func (h *healthState) setSubscriptions(subscriptions []Subscription) {
+	counts := make(map[string]int)
+	for _, sub := range subscriptions {
+		counts[sub.Status]++
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscriptions = counts
+	h.subscriptionsCheckedAt = time.Now()
+}
+
+// Health returns the current health of the client.
+// The client is considered ready if the app token is valid (or the client doesn't manage one), the dedup store (if it implements Pinger) is reachable,
+// and Shutdown was not called.
+func (c *Client) Health() HealthReport {
+	c.health.mu.RLock()
+	report := HealthReport{
+		TokenValid: c.health.tokenValid || !c.health.tokenManaged,
+	}
+	if !c.health.lastHelixSuccess.IsZero() {
+		t := c.health.lastHelixSuccess
+		report.LastHelixSuccess = &t
+	}
+	if c.health.subscriptions != nil {
+		report.Subscriptions = make(map[string]int, len(c.health.subscriptions))
+		for status, count := range c.health.subscriptions {
+			report.Subscriptions[status] = count
+		}
+		t := c.health.subscriptionsCheckedAt
+		report.SubscriptionsCheckedAt = &t
+	}
+	c.health.mu.RUnlock()
+
+	dedupOK := true
+	report.DedupStore = "unknown"
+	if pinger, ok := c.handledEventsChecker.(Pinger); ok {
+		if err := pinger.Ping(); err != nil {
+			dedupOK = false
+			report.DedupStore = err.Error()
+		} else {
+			report.DedupStore = "ok"
+		}
+	}
+
+	report.Draining = c.draining.Load()
+	report.Status = "ok"
+	if !report.TokenValid || !dedupOK || report.Draining {
+		report.Status = "unavailable"
+	}
+	return report
+}
+
+// Healthz is an HTTP handler for liveness probes. It always responds 200 with the HealthReport as JSON,
+// since a failing dependency is not fixed by restarting the process.
+//
+//	http.HandleFunc("/healthz", client.Healthz)
+func (c *Client) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, http.StatusOK, c.Health())
+}
+
+// Readyz is an HTTP handler for readiness probes. It responds 200 if the client is ready to receive events,
+// and 503 otherwise, with the HealthReport as JSON.
+//
+//	http.HandleFunc("/readyz", client.Readyz)
+func (c *Client) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := c.Health()
+	status := http.StatusOK
+	if report.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealthReport(w, status, report)
+}
+
+func writeHealthReport(w http.ResponseWriter, status int, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,82 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadyzWithoutAppToken(t *testing.T) {
+	receiver, err := NewReceiver(testWebhookSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	injected := newClient(ClientConfig{Helix: receiverHelix{}})
+	for name, c := range map[string]*Client{"receiver": receiver, "injected Helix": injected} {
+		w := httptest.NewRecorder()
+		c.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if w.Code != http.StatusOK || !c.Health().TokenValid {
+			t.Errorf("Expected a %s client to be ready, got %d: %s", name, w.Code, w.Body)
+		}
+	}
+}
+
+type pingChecker struct {
+	*DefaultHandledEventsChecker
+	err error
+}
+
+func (p pingChecker) Ping() error {
+	return p.err
+}
+
+func TestHealthProbes(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(c *Client)
+		ready  bool
+		status string
+	}{
+		{"ready", func(c *Client) { c.health.setTokenValid(true) }, true, "ok"},
+		{"invalid token", func(c *Client) { c.health.setTokenValid(false) }, false, "unavailable"},
+		{"dedup store down", func(c *Client) {
+			c.handledEventsChecker = pingChecker{NewDefaultHandledEventsChecker(), errors.New("connection refused")}
+		}, false, "unavailable"},
+		{"draining", func(c *Client) { c.draining.Store(true) }, false, "unavailable"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newClient(ClientConfig{})
+			c.handledEventsChecker = pingChecker{NewDefaultHandledEventsChecker(), nil}
+			test.setup(c)
+
+			w := httptest.NewRecorder()
+			c.Healthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected Healthz to respond 200, got %d", w.Code)
+			}
+
+			w = httptest.NewRecorder()
+			c.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			want := http.StatusOK
+			if !test.ready {
+				want = http.StatusServiceUnavailable
+			}
+			if w.Code != want {
+				t.Fatalf("Expected Readyz to respond %d, got %d", want, w.Code)
+			}
+			if w.Header().Get("Content-Type") != "application/json" {
+				t.Errorf("Expected a JSON response, got %q", w.Header().Get("Content-Type"))
+			}
+			var report HealthReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Status != test.status {
+				t.Fatalf("Expected status %q, got %+v", test.status, report)
+			}
+		})
+	}
+}
@@ -1,18 +1,303 @@
 package twitchwh
 
-import "net/http"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
 
-const helixURL = "https://api.twitch.tv/helix"
+const defaultHelixURL = "https://api.twitch.tv/helix"
 
-// Interal generic request function that includes authorization headers.
-// TODO: Should this return the request rather than the response?
-func (c *Client) genericRequest(method string, endpoint string) (*http.Response, error) {
-	req, err := http.NewRequest(method, helixURL+endpoint, nil)
+// HelixAPI is the part of the Twitch Helix API the client uses. The default implementation sends requests to
+// Twitch with the client's app access token. Set ClientConfig.Helix to replace it, eg: with a fake in tests:
+//
+//	type fakeHelix struct {
+//		twitchwh.HelixAPI
+//		subs []twitchwh.Subscription
+//	}
+//
+//	func (f *fakeHelix) GetSubscriptions(ctx context.Context, query twitchwh.SubscriptionQuery) ([]twitchwh.Subscription, string, error) {
+//		return f.subs, "", nil
+//	}
+//
+// Implementations should return UnauthorizedError, DuplicateSubscriptionError, SubscriptionNotFoundError, and
+// UnhandledStatusError where the default one does, so the client handles them the same way.
+type HelixAPI interface {
+	// CreateSubscription creates an EventSub subscription and returns it, usually with its verification pending.
+	// Returns DuplicateSubscriptionError if a subscription with the same type and condition exists, and
+	// UnsupportedTypeError if the type or version is not supported.
+	CreateSubscription(ctx context.Context, req SubscriptionRequest) (Subscription, error)
+	// DeleteSubscription deletes an EventSub subscription. Returns SubscriptionNotFoundError if it does not exist.
+	DeleteSubscription(ctx context.Context, id string) error
+	// GetSubscriptions returns a page of the subscriptions matching the query, and the cursor of the next page,
+	// which is empty on the last page.
+	GetSubscriptions(ctx context.Context, query SubscriptionQuery) (subs []Subscription, cursor string, err error)
+	// GetStreams returns the live streams of up to 100 users.
+	GetStreams(ctx context.Context, userIDs []string) ([]Stream, error)
+	// GetChannels returns the channel information of up to 100 broadcasters.
+	GetChannels(ctx context.Context, broadcasterIDs []string) ([]Channel, error)
+	// GetConduits returns the conduits of the application.
+	GetConduits(ctx context.Context) ([]Conduit, error)
+	// CreateConduit creates a conduit with the given number of shards.
+	CreateConduit(ctx context.Context, shardCount int) (Conduit, error)
+	// UpdateConduit changes the number of shards of a conduit.
+	UpdateConduit(ctx context.Context, conduitID string, shardCount int) error
+	// UpdateConduitShards assigns transports to shards of a conduit, and returns the shards Twitch could not update.
+	UpdateConduitShards(ctx context.Context, conduitID string, shards []ConduitShard) ([]ConduitShardError, error)
+}
+
+// SubscriptionRequest is the body of a Create EventSub Subscription request.
+type SubscriptionRequest struct {
+	Type      string                `json:"type"`
+	Version   string                `json:"version"`
+	Condition Condition             `json:"condition"`
+	Transport SubscriptionTransport `json:"transport"`
+}
+
+// SubscriptionTransport is the transport of a new subscription.
+type SubscriptionTransport struct {
+	Method   string `json:"method"`
+	Callback string `json:"callback"`
+	Secret   string `json:"secret"`
+}
+
+// SubscriptionQuery filters the subscriptions returned by HelixAPI.GetSubscriptions. Twitch accepts at most one of
+// Type, Status, UserID, and SubscriptionID. All subscriptions are returned if none is set.
+type SubscriptionQuery struct {
+	Type           string
+	Status         string
+	UserID         string
+	SubscriptionID string
+	// Cursor of the page to return, empty for the first page.
+	After string
+}
+
+// encode returns the query as URL parameters, including the leading "?", or an empty string if no field is set.
+func (q SubscriptionQuery) encode() string {
+	var params []string
+	for _, param := range [][2]string{
+		{"type", q.Type}, {"status", q.Status}, {"user_id", q.UserID}, {"subscription_id", q.SubscriptionID}, {"after", q.After},
+	} {
+		if param[1] != "" {
+			params = append(params, param[0]+"="+url.QueryEscape(param[1]))
+		}
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + strings.Join(params, "&")
+}
+
+// helixClient is the default HelixAPI, sending requests with the client's HTTP client and app access token.
+type helixClient struct {
+	c *Client
+}
+
+func (h helixClient) CreateSubscription(ctx context.Context, req SubscriptionRequest) (Subscription, error) {
+	var response struct {
+		Data []Subscription `json:"data"`
+	}
+	err := h.c.helixJSON(ctx, "POST", "/eventsub/subscriptions", req, 202, &response)
+	var usErr *UnhandledStatusError
+	if errors.As(err, &usErr) && usErr.Status == 409 {
+		return Subscription{}, &DuplicateSubscriptionError{Condition: req.Condition, Type: req.Type}
+	}
+	if errors.As(err, &usErr) && usErr.Status == 400 {
+		if unsupported := unsupportedTypeError(req, usErr.Body); unsupported != nil {
+			return Subscription{}, unsupported
+		}
+	}
+	if err != nil {
+		return Subscription{}, err
+	}
+	// Returned body is an array that contains a single subscription
+	if len(response.Data) < 1 {
+		return Subscription{}, &InternalError{"Helix did not return the subscription they were supposed to", nil}
+	}
+	return response.Data[0], nil
+}
+
+func (h helixClient) DeleteSubscription(ctx context.Context, id string) error {
+	err := h.c.helixJSON(ctx, "DELETE", "/eventsub/subscriptions?id="+url.QueryEscape(id), nil, 204, nil)
+	var usErr *UnhandledStatusError
+	if errors.As(err, &usErr) && usErr.Status == 404 {
+		return &SubscriptionNotFoundError{}
+	}
+	return err
+}
+
+func (h helixClient) GetSubscriptions(ctx context.Context, query SubscriptionQuery) ([]Subscription, string, error) {
+	var response struct {
+		Data       []Subscription `json:"data"`
+		Pagination struct {
+			Cursor string `json:"cursor"`
+		} `json:"pagination"`
+	}
+	err := h.c.helixJSON(ctx, "GET", "/eventsub/subscriptions"+query.encode(), nil, 200, &response)
+	return response.Data, response.Pagination.Cursor, err
+}
+
+func (h helixClient) GetStreams(ctx context.Context, userIDs []string) ([]Stream, error) {
+	params := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		params = append(params, "user_id="+url.QueryEscape(id))
+	}
+	var response struct {
+		Data []Stream `json:"data"`
+	}
+	err := h.c.helixJSON(ctx, "GET", "/streams?first=100&"+strings.Join(params, "&"), nil, 200, &response)
+	return response.Data, err
+}
+
+func (h helixClient) GetChannels(ctx context.Context, broadcasterIDs []string) ([]Channel, error) {
+	params := make([]string, 0, len(broadcasterIDs))
+	for _, id := range broadcasterIDs {
+		params = append(params, "broadcaster_id="+url.QueryEscape(id))
+	}
+	var response struct {
+		Data []Channel `json:"data"`
+	}
+	err := h.c.helixJSON(ctx, "GET", "/channels?"+strings.Join(params, "&"), nil, 200, &response)
+	return response.Data, err
+}
+
+func (h helixClient) GetConduits(ctx context.Context) ([]Conduit, error) {
+	var response struct {
+		Data []Conduit `json:"data"`
+	}
+	err := h.c.helixJSON(ctx, "GET", "/eventsub/conduits", nil, 200, &response)
+	return response.Data, err
+}
+
+func (h helixClient) CreateConduit(ctx context.Context, shardCount int) (Conduit, error) {
+	var response struct {
+		Data []Conduit `json:"data"`
+	}
+	err := h.c.helixJSON(ctx, "POST", "/eventsub/conduits", map[string]int{"shard_count": shardCount}, 200, &response)
 	if err != nil {
-		return nil, err
+		return Conduit{}, err
+	}
+	if len(response.Data) < 1 {
+		return Conduit{}, &InternalError{"Helix did not return the conduit they were supposed to", nil}
+	}
+	return response.Data[0], nil
+}
+
+func (h helixClient) UpdateConduit(ctx context.Context, conduitID string, shardCount int) error {
+	body := struct {
+		ID         string `json:"id"`
+		ShardCount int    `json:"shard_count"`
+	}{conduitID, shardCount}
+	return h.c.helixJSON(ctx, "PATCH", "/eventsub/conduits", body, 200, nil)
+}
+
+func (h helixClient) UpdateConduitShards(ctx context.Context, conduitID string, shards []ConduitShard) ([]ConduitShardError, error) {
+	body := struct {
+		ConduitID string         `json:"conduit_id"`
+		Shards    []ConduitShard `json:"shards"`
+	}{conduitID, shards}
+	var response struct {
+		Errors []ConduitShardError `json:"errors"`
+	}
+	err := h.c.helixJSON(ctx, "PATCH", "/eventsub/conduits/shards", body, 202, &response)
+	return response.Errors, err
+}
+
+// helixJSON sends a request with a JSON body and decodes the JSON response into out (if not nil).
+// It authenticates with the token provider of ctx if any, see Client.WithToken, otherwise with the app access token.
+// The token is refreshed and the request retried once on 401. Returns UnhandledStatusError for any status
+// other than the expected one.
+func (c *Client) helixJSON(ctx context.Context, method string, endpoint string, body any, expectedStatus int, out any) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		reqBody, err = json.Marshal(body)
+		if err != nil {
+			return &InternalError{"Could not serialize request body to JSON", err}
+		}
 	}
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Client-ID", c.clientID)
+	provider := tokenProviderFromContext(ctx)
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.helixURL+endpoint, bytes.NewReader(reqBody))
+		if err != nil {
+			return &InternalError{"Could not create request", err}
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		token := c.getToken()
+		if provider != nil {
+			if token, err = provider.Token(ctx, attempt > 0); err != nil {
+				return err
+			}
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Client-ID", c.clientID)
 
-	return c.httpClient.Do(req)
+		res, err := c.helixDo(req)
+		if err != nil {
+			return &InternalError{"Could not send request", err}
+		}
+		if response := helixResponseFromContext(ctx); response != nil {
+			response.record(res.Header)
+		}
+		resBody, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return &InternalError{"Could not read response body", err}
+		}
+
+		if res.StatusCode == 401 && attempt == 0 {
+			// A token provider refreshes its token on the next attempt
+			if provider == nil {
+				if err := c.refreshToken(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if res.StatusCode == 401 {
+			return &UnauthorizedError{}
+		}
+		if res.StatusCode != expectedStatus {
+			return &UnhandledStatusError{res.StatusCode, resBody}
+		}
+		c.health.helixSucceeded()
+		if out != nil {
+			if err := json.Unmarshal(resBody, out); err != nil {
+				return &InternalError{"Could not parse response body", err}
+			}
+		}
+		return nil
+	}
+}
+
+// unsupportedTypeError returns an UnsupportedTypeError if a 400 response body says the type or version of a
+// subscription is not supported, otherwise nil.
+func unsupportedTypeError(req SubscriptionRequest, body []byte) *UnsupportedTypeError {
+	var response struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &response)
+	message := strings.ToLower(response.Message)
+	if !strings.Contains(message, "version") && !strings.Contains(message, "subscription type") {
+		return nil
+	}
+	err := &UnsupportedTypeError{Type: req.Type, Version: req.Version, Message: response.Message}
+	if versions, ok := subscriptionVersions[req.Type]; ok {
+		if slices.Contains(versions, req.Version) {
+			// Known to be valid, the message is about something else
+			return nil
+		}
+		err.KnownVersions = slices.Clone(versions)
+	} else {
+		err.ClosestTypes = closestTypes(req.Type)
+	}
+	return err
 }
@@ -0,0 +1,106 @@
+package twitchwh
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type fakeHelix struct {
+	HelixAPI
+	pages   map[string][]Subscription
+	queries []SubscriptionQuery
+	deleted []string
+}
+
+func (f *fakeHelix) GetSubscriptions(ctx context.Context, query SubscriptionQuery) ([]Subscription, string, error) {
+	f.queries = append(f.queries, query)
+	if query.After == "" {
+		return f.pages[""], "next", nil
+	}
+	return f.pages[query.After], "", nil
+}
+
+func (f *fakeHelix) DeleteSubscription(ctx context.Context, id string) error {
+	if id == "missing" {
+		return &SubscriptionNotFoundError{}
+	}
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func TestInjectedHelix(t *testing.T) {
+	fake := &fakeHelix{pages: map[string][]Subscription{
+		"":     {{ID: "1", Type: "stream.online"}},
+		"next": {{ID: "2", Type: "stream.online"}},
+	}}
+	// No token is generated, so no request is sent
+	c, err := NewClient("id", "secret", WithConfig(ClientConfig{Helix: fake}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subs, err := c.GetSubscriptionsByType("stream.online")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 || subs[1].ID != "2" {
+		t.Fatalf("Expected both pages, got %+v", subs)
+	}
+	if len(fake.queries) != 2 || fake.queries[0].Type != "stream.online" || fake.queries[1].After != "next" {
+		t.Fatalf("Unexpected queries %+v", fake.queries)
+	}
+
+	if err := c.RemoveSubscription("1"); err != nil {
+		t.Fatal(err)
+	}
+	var nfErr *SubscriptionNotFoundError
+	if err := c.RemoveSubscription("missing"); !errors.As(err, &nfErr) {
+		t.Fatalf("Expected SubscriptionNotFoundError, got %v", err)
+	}
+	if len(fake.deleted) != 1 || fake.deleted[0] != "1" {
+		t.Fatalf("Unexpected deletions %v", fake.deleted)
+	}
+}
+
+func TestSubscriptionQueryEncode(t *testing.T) {
+	for query, expected := range map[SubscriptionQuery]string{
+		{}:                             "",
+		{Type: "stream.online"}:        "?type=stream.online",
+		{UserID: "1234", After: "a b"}: "?user_id=1234&after=a+b",
+	} {
+		if encoded := query.encode(); encoded != expected {
+			t.Errorf("Expected %q, got %q", expected, encoded)
+		}
+	}
+}
+
+func TestUnsupportedTypeError(t *testing.T) {
+	c := newClient(ClientConfig{})
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(400, `{"error":"Bad Request","status":400,"message":"unsupported subscription type or version"}`), nil
+	})}
+
+	_, err := c.AddSubscription("channel.follow", "1", Condition{BroadcasterUserID: "1"})
+	var unsupported *UnsupportedTypeError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Expected UnsupportedTypeError, got %v", err)
+	}
+	if !slices.Equal(unsupported.KnownVersions, []string{"2"}) {
+		t.Fatalf("Expected known versions of channel.follow, got %v", unsupported.KnownVersions)
+	}
+
+	_, err = c.AddSubscription("stream.onlin", "1", Condition{BroadcasterUserID: "1"})
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Expected UnsupportedTypeError, got %v", err)
+	}
+	if !slices.Equal(unsupported.ClosestTypes, []string{TypeStreamOnline}) {
+		t.Fatalf("Expected stream.online to be suggested, got %v", unsupported.ClosestTypes)
+	}
+	if !strings.Contains(err.Error(), "did you mean stream.online?") {
+		t.Fatalf("Unexpected message %q", err)
+	}
+}
@@ -0,0 +1,223 @@
+package twitchwh
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Priority orders Helix requests waiting for the rate limit or ClientConfig.MaxConcurrentHelixRequests.
+type Priority int
+
+const (
+	// Calls made directly on the Client, eg: an operator removing a subscription. The default.
+	PriorityInteractive Priority = iota
+	// Calls made through Client.Background and by the client's own sync jobs, eg: ConduitScaler.
+	// They wait until no interactive request is queued.
+	PriorityBackground
+)
+
+type priorityKey struct{}
+
+// withPriority returns a context whose Helix requests are queued with the given priority.
+func withPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func priorityFromContext(ctx context.Context) Priority {
+	priority, _ := ctx.Value(priorityKey{}).(Priority)
+	return priority
+}
+
+// helixQueue limits concurrent Helix requests and holds them back while the rate limit bucket is empty, letting
+// waiting requests through in priority order.
+type helixQueue struct {
+	mu sync.Mutex
+	// Maximum number of requests in flight, unlimited if 0
+	limit  int
+	active int
+	// Requests left in the rate limit bucket as of the last response, see the Ratelimit-Remaining header.
+	// Unknown until the first response.
+	remaining int
+	known     bool
+	reset     time.Time
+	timer     *time.Timer
+	waiting   [PriorityBackground + 1][]chan struct{}
+}
+
+// acquire blocks until a request with the given priority may be sent. The caller must call release afterwards.
+func (q *helixQueue) acquire(ctx context.Context, priority Priority) error {
+	q.mu.Lock()
+	if q.queued() == 0 && q.ready() {
+		q.take()
+		q.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	q.waiting[priority] = append(q.waiting[priority], ready)
+	q.dispatch()
+	q.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		select {
+		case <-ready:
+			// Granted while giving up, pass the slot on
+			q.active--
+			q.dispatch()
+		default:
+			q.remove(priority, ready)
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees the slot of a request and updates the rate limit from its response, which may be nil if the request
+// failed.
+func (q *helixQueue) release(res *http.Response) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.active--
+	if res != nil {
+		q.update(res.Header)
+	}
+	q.dispatch()
+}
+
+// update reads the rate limit headers Twitch sends with every response.
+func (q *helixQueue) update(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("Ratelimit-Remaining"))
+	if err != nil {
+		return
+	}
+	q.remaining = remaining
+	q.known = true
+	if reset, err := strconv.ParseInt(header.Get("Ratelimit-Reset"), 10, 64); err == nil {
+		q.reset = time.Unix(reset, 0)
+	}
+}
+
+// ready reports whether a request may be sent now.
+func (q *helixQueue) ready() bool {
+	if q.limit > 0 && q.active >= q.limit {
+		return false
+	}
+	if q.known && q.remaining <= q.active && time.Now().Before(q.reset) {
+		return false
+	}
+	return true
+}
+
+func (q *helixQueue) take() {
+	q.active++
+	if q.known && !time.Now().Before(q.reset) {
+		// The bucket refilled, the next response tells how much
+		q.known = false
+	}
+}
+
+func (q *helixQueue) queued() int {
+	n := 0
+	for _, waiting := range q.waiting {
+		n += len(waiting)
+	}
+	return n
+}
+
+// dispatch lets waiting requests through, interactive ones first.
+func (q *helixQueue) dispatch() {
+	for priority := range q.waiting {
+		for len(q.waiting[priority]) > 0 && q.ready() {
+			q.take()
+			close(q.waiting[priority][0])
+			q.waiting[priority] = q.waiting[priority][1:]
+		}
+	}
+	q.schedule()
+}
+
+// schedule wakes the queue when the rate limit bucket refills, if requests are waiting for it.
+func (q *helixQueue) schedule() {
+	if q.timer != nil || q.queued() == 0 || !q.known || !time.Now().Before(q.reset) {
+		return
+	}
+	q.timer = time.AfterFunc(time.Until(q.reset), func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.timer = nil
+		q.dispatch()
+	})
+}
+
+func (q *helixQueue) remove(priority Priority, ready chan struct{}) {
+	for i, waiting := range q.waiting[priority] {
+		if waiting == ready {
+			q.waiting[priority] = append(q.waiting[priority][:i:i], q.waiting[priority][i+1:]...)
+			return
+		}
+	}
+}
+
+// helixDo sends a Helix request through the queue, with the priority of the request context.
+func (c *Client) helixDo(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := c.helixQueue.acquire(ctx, priorityFromContext(ctx)); err != nil {
+		return nil, err
+	}
+	res, err := c.httpClient.Do(req)
+	c.helixQueue.release(res)
+	return res, err
+}
+
+// Background is a view of the client whose Helix requests wait until no interactive request is queued, so bulk jobs
+// like reconciling subscriptions don't hold up manual calls when the rate limit runs low.
+//
+//	subs, _ := client.Background().GetSubscriptions()
+//	for _, sub := range subs {
+//		if stale(sub) {
+//			client.Background().RemoveSubscription(sub.ID)
+//		}
+//	}
+type Background struct {
+	c *Client
+}
+
+// Background returns a view of the client with PriorityBackground.
+func (c *Client) Background() *Background {
+	return &Background{c: c}
+}
+
+func (b *Background) ctx() context.Context {
+	return withPriority(context.Background(), PriorityBackground)
+}
+
+// AddSubscription is like Client.AddSubscription.
+func (b *Background) AddSubscription(Type string, version string, condition Condition) (string, error) {
+	return b.c.createSubscription(b.ctx(), Type, version, condition)
+}
+
+// RemoveSubscription is like Client.RemoveSubscription.
+func (b *Background) RemoveSubscription(id string) error {
+	return b.c.deleteSubscription(b.ctx(), id)
+}
+
+// GetSubscriptions is like Client.GetSubscriptions.
+func (b *Background) GetSubscriptions() ([]Subscription, error) {
+	return b.c.getSubscriptions(b.ctx())
+}
+
+// GetSubscriptionsByType is like Client.GetSubscriptionsByType.
+func (b *Background) GetSubscriptionsByType(Type string) ([]Subscription, error) {
+	return b.c.getSubscriptionsByType(b.ctx(), Type)
+}
+
+// GetSubscriptionsByStatus is like Client.GetSubscriptionsByStatus.
+func (b *Background) GetSubscriptionsByStatus(status string) ([]Subscription, error) {
+	return b.c.getSubscriptionsByStatus(b.ctx(), status)
+}
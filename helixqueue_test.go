@@ -0,0 +1,89 @@
+package twitchwh
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHelixQueuePriority(t *testing.T) {
+	c := newClient(ClientConfig{MaxConcurrentHelixRequests: 1})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Query().Get("type") == "block" {
+			<-release
+		}
+		mu.Lock()
+		order = append(order, r.URL.Query().Get("type"))
+		mu.Unlock()
+		return jsonResponse(200, `{"data":[],"pagination":{}}`), nil
+	})}
+
+	blocked := make(chan struct{})
+	go func() {
+		c.GetSubscriptionsByType("block")
+		close(blocked)
+	}()
+	waitQueue := func(active, queued int) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); ; {
+			c.helixQueue.mu.Lock()
+			done := c.helixQueue.active == active && c.helixQueue.queued() == queued
+			c.helixQueue.mu.Unlock()
+			if done {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Requests were not queued")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitQueue(1, 0)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.Background().GetSubscriptionsByType("background")
+	}()
+	waitQueue(1, 1)
+	go func() {
+		defer wg.Done()
+		c.GetSubscriptionsByType("interactive")
+	}()
+	waitQueue(1, 2)
+
+	close(release)
+	<-blocked
+	wg.Wait()
+	if len(order) != 3 || order[1] != "interactive" || order[2] != "background" {
+		t.Fatalf("Expected the interactive request before the background one, got %v", order)
+	}
+}
+
+func TestHelixQueueRateLimit(t *testing.T) {
+	var q helixQueue
+	reset := time.Now().Add(50 * time.Millisecond)
+	if err := q.acquire(context.Background(), PriorityInteractive); err != nil {
+		t.Fatal(err)
+	}
+	q.release(&http.Response{Header: http.Header{
+		"Ratelimit-Remaining": {"0"},
+		"Ratelimit-Reset":     {strconv.FormatInt(reset.Unix()+1, 10)},
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.acquire(ctx, PriorityInteractive); err == nil {
+		t.Fatal("Expected the request to wait for the rate limit to reset")
+	}
+	if q.queued() != 0 {
+		t.Fatal("Expected cancelled request to leave the queue")
+	}
+}
@@ -0,0 +1,82 @@
+package twitchwh
+
+import (
+	"context"
+	"sync"
+)
+
+// Prefix of the keys Idempotent stores in the HandledEventsChecker, so they can't collide with message IDs.
+const idempotentKeyPrefix = "twitchwh:idempotent:"
+
+// keyLocks serializes Idempotent calls with the same key within the process.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func (k *keyLocks) lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyLock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		k.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// Idempotent runs fn unless it already succeeded for key, using the HandledEventsChecker to remember successful keys.
+// Handlers use it to guard side effects against redelivery and replays, with the same persistence the client
+// already uses for deduplication:
+//
+//	client.OnContext("channel.cheer", func(ctx context.Context, event json.RawMessage) error {
+//		return client.Idempotent("points:"+twitchwh.MessageIDFromContext(ctx), func() error {
+//			return db.AwardPoints(ctx, cheer.UserID, cheer.Bits)
+//		})
+//	})
+//
+// fn runs again if it returns an error. Calls with the same key are serialized within the process, and across
+// replicas if ClientConfig.Locker is set. Keys are kept as long as the checker keeps message IDs.
+func (c *Client) Idempotent(key string, fn func() error) error {
+	key = idempotentKeyPrefix + key
+	unlock := c.idempotencyLocks.lock(key)
+	defer unlock()
+	if c.locker != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+		defer cancel()
+		unlockRemote, err := c.locker.Lock(ctx, key)
+		if err != nil {
+			return &InternalError{"Could not acquire idempotency lock", err}
+		}
+		defer unlockRemote()
+	}
+
+	if c.handledEventsChecker.IsHandled(key) {
+		c.logger.Debug("Skipping side effect that already succeeded", "key", key)
+		return nil
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	c.handledEventsChecker.MarkHandled(key)
+	return nil
+}
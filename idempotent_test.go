@@ -0,0 +1,43 @@
+package twitchwh
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIdempotent(t *testing.T) {
+	c := newClient(ClientConfig{})
+	var runs atomic.Int64
+	fail := true
+	fn := func() error {
+		runs.Add(1)
+		if fail {
+			return errors.New("database unavailable")
+		}
+		return nil
+	}
+
+	if err := c.Idempotent("points:a", fn); err == nil {
+		t.Fatal("Expected error from fn")
+	}
+	fail = false
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Idempotent("points:a", fn); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if runs.Load() != 2 {
+		t.Fatalf("Expected fn to run again after failing and then only once, ran %d times", runs.Load())
+	}
+	if c.handledEventsChecker.IsHandled("points:a") {
+		t.Error("Expected key not to collide with message IDs")
+	}
+}
@@ -0,0 +1,197 @@
+package twitchwh
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+const statusVerificationPending = "webhook_callback_verification_pending"
+
+// PendingJournal records subscription requests while AddSubscription is in flight, so subscriptions left behind by
+// a crash can be found on restart, see Client.ResolvePendingSubscriptions. It is used if ClientConfig.Registry
+// implements it, like MemoryRegistry does. Persist entries next to the registry, an in-memory journal is lost along
+// with the process.
+type PendingJournal interface {
+	// RecordPending adds or replaces the entry with the same Key.
+	RecordPending(entry PendingSubscription) error
+	// CompletePending removes an entry. Removing an entry that doesn't exist is not an error.
+	CompletePending(key string) error
+	// PendingSubscriptions returns the entries that were never completed.
+	PendingSubscriptions() ([]PendingSubscription, error)
+}
+
+// PendingSubscription is an AddSubscription call recorded in a PendingJournal. It is recorded before the request is
+// sent to Helix, recorded again with the subscription ID once Helix created it, and completed when AddSubscription
+// returns.
+type PendingSubscription struct {
+	// Unique per AddSubscription call.
+	Key       string    `json:"key"`
+	Type      string    `json:"type"`
+	Version   string    `json:"version"`
+	Condition Condition `json:"condition"`
+	Callback  string    `json:"callback"`
+	// Empty until Helix created the subscription.
+	SubscriptionID string    `json:"subscription_id,omitempty"`
+	Started        time.Time `json:"started"`
+}
+
+// RecordPending implements PendingJournal.
+func (m *MemoryRegistry) RecordPending(entry PendingSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pending == nil {
+		m.pending = make(map[string]PendingSubscription)
+	}
+	m.pending[entry.Key] = entry
+	return nil
+}
+
+// CompletePending implements PendingJournal.
+func (m *MemoryRegistry) CompletePending(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, key)
+	return nil
+}
+
+// PendingSubscriptions implements PendingJournal.
+func (m *MemoryRegistry) PendingSubscriptions() ([]PendingSubscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := make([]PendingSubscription, 0, len(m.pending))
+	for _, entry := range m.pending {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// beginPending records a subscription request in the journal, if the registry is one. The returned function
+// records the subscription ID, and done completes the entry.
+func (c *Client) beginPending(Type string, version string, condition Condition) (created func(id string), done func(), err error) {
+	journal, ok := c.registry.(PendingJournal)
+	if !ok {
+		return func(string) {}, func() {}, nil
+	}
+	random := make([]byte, 16)
+	rand.Read(random)
+	entry := PendingSubscription{
+		Key:       hex.EncodeToString(random),
+		Type:      Type,
+		Version:   version,
+		Condition: condition,
+		Callback:  c.GetWebhookURL(),
+		Started:   time.Now(),
+	}
+	if err := journal.RecordPending(entry); err != nil {
+		return nil, nil, &InternalError{"Could not record pending subscription", err}
+	}
+	created = func(id string) {
+		entry.SubscriptionID = id
+		if err := journal.RecordPending(entry); err != nil {
+			c.reportError("Could not record pending subscription", err, "subscription_id", id)
+		}
+	}
+	done = func() {
+		if err := journal.CompletePending(entry.Key); err != nil {
+			c.reportError("Could not complete pending subscription", err, "type", Type)
+		}
+	}
+	return created, done, nil
+}
+
+// ResolvePendingSubscriptions resolves the entries a crash left in the PendingJournal, eg: at startup once the
+// handler is being served:
+//
+//   - Subscriptions that were verified in the meantime are kept.
+//   - Subscriptions still pending verification are waited for, like AddSubscription does.
+//   - Other subscriptions, eg: those that failed verification because nobody answered the challenge, are deleted.
+//
+// Entries without a subscription ID are matched by type, condition, and callback, since the crash may have happened
+// after Helix created the subscription but before its ID was recorded. Returns the IDs of the subscriptions that are
+// enabled, and the errors of entries that could not be resolved, which stay in the journal.
+func (c *Client) ResolvePendingSubscriptions(ctx context.Context) (ids []string, err error) {
+	journal, ok := c.registry.(PendingJournal)
+	if !ok {
+		return nil, nil
+	}
+	if err := c.checkLeader(); err != nil {
+		return nil, err
+	}
+	entries, err := journal.PendingSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	ctx = withPriority(ctx, PriorityBackground)
+	var errs []error
+	for _, entry := range entries {
+		id, err := c.resolvePending(ctx, entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if id != "" {
+			ids = append(ids, id)
+		}
+		errs = append(errs, journal.CompletePending(entry.Key))
+	}
+	return ids, errors.Join(errs...)
+}
+
+// resolvePending resolves a journal entry, and returns the subscription ID if it is enabled.
+func (c *Client) resolvePending(ctx context.Context, entry PendingSubscription) (string, error) {
+	var sub Subscription
+	if entry.SubscriptionID != "" {
+		subs, err := c.fetchSubscriptions(ctx, SubscriptionQuery{SubscriptionID: entry.SubscriptionID})
+		if err != nil {
+			return "", err
+		}
+		for _, s := range subs {
+			if s.ID == entry.SubscriptionID {
+				sub = s
+			}
+		}
+	} else {
+		subs, err := c.fetchSubscriptions(ctx, SubscriptionQuery{Type: entry.Type})
+		if err != nil {
+			return "", err
+		}
+		for _, s := range subs {
+			if s.Condition.EqualFor(entry.Type, entry.Condition) && s.Transport.Callback == entry.Callback && !s.CreatedAt.Before(entry.Started.Add(-time.Minute)) {
+				sub = s
+			}
+		}
+	}
+	if sub.ID == "" {
+		c.logger.Info("Pending subscription was never created", "type", entry.Type, "subscription_id", entry.SubscriptionID)
+		return "", nil
+	}
+
+	if sub.Status == statusVerificationPending {
+		verified := c.verifications.wait(sub.ID)
+		defer c.verifications.cancel(sub.ID)
+		timer := time.NewTimer(verificationTimeout)
+		defer timer.Stop()
+		select {
+		case <-verified:
+			sub.Status = "enabled"
+		case <-timer.C:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if sub.Status == "enabled" {
+		c.logger.Info("Pending subscription was verified", "subscription_id", sub.ID, "type", sub.Type)
+		c.created.add(sub.ID)
+		return sub.ID, nil
+	}
+
+	c.logger.Info("Deleting pending subscription", "subscription_id", sub.ID, "type", sub.Type, "status", sub.Status)
+	var nfErr *SubscriptionNotFoundError
+	if err := c.removeSubscription(ctx, sub.ID); err != nil && !errors.As(err, &nfErr) {
+		return "", err
+	}
+	return "", nil
+}
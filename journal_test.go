@@ -0,0 +1,69 @@
+package twitchwh
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPendingJournal(t *testing.T) {
+	registry := NewMemoryRegistry()
+	c := newClient(ClientConfig{Registry: registry})
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if entries, _ := registry.PendingSubscriptions(); len(entries) != 1 {
+			t.Errorf("Expected the request to be recorded before it is sent, got %+v", entries)
+		}
+		c.verifications.verified("1")
+		return jsonResponse(202, `{"data":[{"id":"1","type":"stream.online","version":"1","status":"webhook_callback_verification_pending"}]}`), nil
+	})}
+
+	if _, err := c.AddSubscription("stream.online", "1", Condition{BroadcasterUserID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := registry.PendingSubscriptions(); len(entries) != 0 {
+		t.Fatalf("Expected the entry to be completed, got %+v", entries)
+	}
+}
+
+func TestResolvePendingSubscriptions(t *testing.T) {
+	registry := NewMemoryRegistry()
+	c := newClient(ClientConfig{Registry: registry, WebhookURL: "https://example.com/eventsub"})
+	started := time.Now()
+	registry.RecordPending(PendingSubscription{Key: "a", Type: "stream.online", SubscriptionID: "1", Started: started})
+	registry.RecordPending(PendingSubscription{Key: "b", Type: "stream.online", SubscriptionID: "2", Started: started})
+	registry.RecordPending(PendingSubscription{Key: "c", Type: "stream.offline", Callback: "https://example.com/eventsub",
+		Condition: Condition{BroadcasterUserID: "1"}, Started: started})
+
+	var removed []string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodDelete:
+			removed = append(removed, query.Get("id"))
+			return jsonResponse(204, ""), nil
+		case query.Get("subscription_id") == "1":
+			return jsonResponse(200, `{"data":[{"id":"1","status":"enabled"}],"pagination":{}}`), nil
+		case query.Get("subscription_id") == "2":
+			return jsonResponse(200, `{"data":[{"id":"2","status":"webhook_callback_verification_failed"}],"pagination":{}}`), nil
+		}
+		// Created by the crashed call, but its ID was never recorded
+		return jsonResponse(200, `{"data":[{"id":"3","status":"webhook_callback_verification_failed","created_at":"`+
+			started.Add(time.Second).Format(time.RFC3339)+`","condition":{"broadcaster_user_id":"1"},`+
+			`"transport":{"method":"webhook","callback":"https://example.com/eventsub"}}],"pagination":{}}`), nil
+	})}
+
+	ids, err := c.ResolvePendingSubscriptions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != "1" {
+		t.Fatalf("Expected the verified subscription to be kept, got %v", ids)
+	}
+	if len(removed) != 2 {
+		t.Fatalf("Expected failed subscriptions to be deleted, got %v", removed)
+	}
+	if entries, _ := registry.PendingSubscriptions(); len(entries) != 0 {
+		t.Fatalf("Expected all entries to be resolved, got %+v", entries)
+	}
+}
@@ -0,0 +1,49 @@
+package twitchwh
+
+import "context"
+
+// Elector decides which replica manages subscriptions when several instances of an app share the same Twitch
+// application. All replicas serve webhooks, but only the leader creates and deletes subscriptions, so replicas running
+// the same reconciliation logic don't race each other.
+//
+// Implement it on top of Redis, etcd, or Postgres advisory locks, eg:
+//
+//	twitchwh.ElectorFunc(func(ctx context.Context) (bool, error) {
+//		var leader bool
+//		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockID).Scan(&leader)
+//		return leader, err
+//	})
+//
+// The advisory lock is held by the database session, so conn must be a dedicated *sql.Conn.
+type Elector interface {
+	// IsLeader reports whether this instance currently holds leadership. Called before every subscription change.
+	IsLeader(ctx context.Context) (bool, error)
+}
+
+// ElectorFunc adapts a function to an Elector.
+type ElectorFunc func(ctx context.Context) (bool, error)
+
+func (f ElectorFunc) IsLeader(ctx context.Context) (bool, error) {
+	return f(ctx)
+}
+
+// IsLeader reports whether this instance may change subscriptions. Always true if ClientConfig.Elector is not set.
+// Use it to run reconciliation loops only on the leader.
+func (c *Client) IsLeader() bool {
+	return c.checkLeader() == nil
+}
+
+// checkLeader returns a NotLeaderError if an Elector is configured and this instance is not the leader.
+func (c *Client) checkLeader() error {
+	if c.elector == nil {
+		return nil
+	}
+	leader, err := c.elector.IsLeader(context.Background())
+	if err != nil {
+		return &InternalError{"Could not check leadership", err}
+	}
+	if !leader {
+		return &NotLeaderError{}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package twitchwh
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNotLeader(t *testing.T) {
+	c := newClient(ClientConfig{Elector: ElectorFunc(func(ctx context.Context) (bool, error) {
+		return false, nil
+	})})
+	if c.IsLeader() {
+		t.Fatal("Expected instance to not be the leader")
+	}
+	var notLeader *NotLeaderError
+	if _, err := c.AddSubscription("stream.online", "1", Condition{BroadcasterUserID: "1"}); !errors.As(err, &notLeader) {
+		t.Fatalf("Expected NotLeaderError, got %v", err)
+	}
+	if err := c.RemoveSubscription("1"); !errors.As(err, &notLeader) {
+		t.Fatalf("Expected NotLeaderError, got %v", err)
+	}
+}
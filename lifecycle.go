@@ -0,0 +1,138 @@
+package twitchwh
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// How often Shutdown and FlushSinks check whether draining finished.
+const drainPollInterval = 50 * time.Millisecond
+
+// background tracks the goroutines that run for the lifetime of a client, eg: the sink workers. Close cancels ctx and
+// waits for them to return.
+type background struct {
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// goBackground runs fn in a goroutine that Close waits for. fn must return once ctx is done.
+func (c *Client) goBackground(fn func(ctx context.Context)) {
+	c.background.wg.Add(1)
+	go func() {
+		defer c.background.wg.Done()
+		fn(c.background.ctx)
+	}()
+}
+
+// stopBackground cancels the background goroutines and waits for them to return.
+func (c *Client) stopBackground() {
+	c.background.stop()
+	c.background.wg.Wait()
+}
+
+// sleepContext waits for d, or until ctx is done. Returns ctx.Err() if ctx was done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// DrainStatus is the progress of a Shutdown, returned by Client.DrainStatus.
+type DrainStatus struct {
+	// Whether Shutdown was called. Notifications are rejected with 503 while draining.
+	Draining bool `json:"draining"`
+	// Handlers that are currently running.
+	RunningHandlers int64 `json:"running_handlers"`
+	// Notifications waiting for a worker, see DispatchWorkers.
+	QueuedNotifications int `json:"queued_notifications"`
+	// Notifications queued for or being published to sinks.
+	PendingSinkNotifications int64 `json:"pending_sink_notifications"`
+}
+
+// DrainStatus returns the progress of a Shutdown.
+func (c *Client) DrainStatus() DrainStatus {
+	status := DrainStatus{
+		Draining:        c.draining.Load(),
+		RunningHandlers: c.runningHandlers.Load(),
+	}
+	c.typeQueues.mu.Lock()
+	for _, queue := range c.typeQueues.queues {
+		status.QueuedNotifications += len(queue)
+	}
+	c.typeQueues.mu.Unlock()
+	c.sinks.mu.RLock()
+	for _, entry := range c.sinks.entries {
+		status.PendingSinkNotifications += entry.pending.Load()
+	}
+	c.sinks.mu.RUnlock()
+	return status
+}
+
+// Shutdown stops accepting notifications, hands over pending batches (see OnBatch), stops the outbox dispatcher, and
+// waits for running and queued handlers to finish, or ctx to be done. Entries left in the outbox (see
+// ClientConfig.Outbox) are claimed by another instance sharing it, or after the next start.
+// From then on, Readyz reports the client as unavailable and notifications are rejected with 503 so Twitch redelivers
+// them to another instance. Verification challenges and revocations are still handled.
+//
+// Call it when the process receives SIGTERM, before shutting down the HTTP server:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//	defer stop()
+//	<-ctx.Done()
+//	drainCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+//	defer cancel()
+//	client.Shutdown(drainCtx)
+//	client.FlushSinks(drainCtx)
+//	server.Shutdown(drainCtx)
+//
+// Returns ctx.Err() if handlers or the outbox dispatcher were still running when ctx was done, see DrainStatus for the progress.
+func (c *Client) Shutdown(ctx context.Context) error {
+	if !c.draining.Swap(true) {
+		c.logger.Info("Shutting down, rejecting new notifications")
+	}
+	// The dispatcher may add entries to batches until it stopped
+	stopErr := c.stopOutboxDispatcher(ctx)
+	c.flushBatches()
+	if stopErr != nil {
+		return stopErr
+	}
+	if err := c.waitDrained(ctx, func(status DrainStatus) bool {
+		return status.RunningHandlers == 0 && status.QueuedNotifications == 0
+	}); err != nil {
+		return err
+	}
+	c.closeTypeQueues()
+	return nil
+}
+
+// FlushSinks waits until every queued notification was published to its sink (or dead-lettered), or ctx is done.
+func (c *Client) FlushSinks(ctx context.Context) error {
+	return c.waitDrained(ctx, func(status DrainStatus) bool {
+		return status.PendingSinkNotifications == 0
+	})
+}
+
+func (c *Client) waitDrained(ctx context.Context, drained func(DrainStatus) bool) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		status := c.DrainStatus()
+		if drained(status) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			c.logger.Warn("Could not drain in time", "running_handlers", status.RunningHandlers,
+				"queued_notifications", status.QueuedNotifications, "pending_sink_notifications", status.PendingSinkNotifications)
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	release := make(chan struct{})
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		<-release
+		return nil
+	})
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); err == nil {
+		t.Fatal("Expected Shutdown to time out while a handler is running")
+	}
+	if status := c.DrainStatus(); !status.Draining || status.RunningHandlers != 1 {
+		t.Fatalf("Unexpected drain status %+v", status)
+	}
+
+	w = httptest.NewRecorder()
+	c.Handler(w, signedRequest("b", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503 while draining, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	c.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected Readyz to fail while draining, got %d", w.Code)
+	}
+
+	close(release)
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCloseStopsBackground(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, Outbox: &memoryOutbox{}})
+	c.startOutbox()
+	published := make(chan Notification, 1)
+	c.AddSink(funcSink(func(ctx context.Context, n Notification) error {
+		published <- n
+		return nil
+	}))
+	c.WatchType("channel.chat.message", time.Minute)
+
+	closed := make(chan error)
+	go func() {
+		closed <- c.Close()
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return, background goroutines are still running")
+	}
+
+	c.publish(Notification{MessageID: "a"})
+	select {
+	case <-published:
+		t.Fatal("Expected the sink worker to be stopped")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestShutdownStopsOutbox(t *testing.T) {
+	outbox := &memoryOutbox{}
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, Outbox: outbox})
+	c.startOutbox()
+	defer c.Close()
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+	c.Handler(httptest.NewRecorder(), signedRequest("b", messageTypeNotification, chatMessageBody))
+	<-started
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- c.Shutdown(context.Background())
+	}()
+	select {
+	case <-shutdown:
+		t.Fatal("Expected Shutdown to wait for the entry being dispatched")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if err := <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+
+	outbox.mu.Lock()
+	defer outbox.mu.Unlock()
+	if len(outbox.completed) != 1 || len(started) != 0 {
+		t.Fatalf("Expected only the first entry to be delivered, completed %v", outbox.completed)
+	}
+}
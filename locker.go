@@ -0,0 +1,67 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Locker serializes subscription creation across replicas, see ClientConfig.Locker.
+//
+// Implement it with the same primitives as an Elector, eg: a Redis SET NX with expiry, or pg_advisory_lock on a hash
+// of the key.
+type Locker interface {
+	// Lock blocks until the lock for key is acquired or ctx is done, and returns a function releasing it.
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// LockerFunc adapts a function to a Locker.
+type LockerFunc func(ctx context.Context, key string) (unlock func(), err error)
+
+func (f LockerFunc) Lock(ctx context.Context, key string) (func(), error) {
+	return f(ctx, key)
+}
+
+// How long AddSubscription waits for the lock. Longer than a verification, so a replica waiting for another one's
+// creation to finish doesn't give up early.
+const lockTimeout = 3 * verificationTimeout
+
+// subscriptionLockKey is the Locker key for a subscription type and condition, eg: "twitchwh:stream.online:{...}".
+func subscriptionLockKey(Type string, condition Condition) (string, error) {
+	data, err := json.Marshal(condition.Normalize())
+	if err != nil {
+		return "", err
+	}
+	return "twitchwh:" + Type + ":" + string(data), nil
+}
+
+// lockSubscription acquires the lock for a subscription type and condition, and returns a DuplicateSubscriptionError
+// if another replica created the subscription while this one was waiting. The subscriptions are fetched from Helix
+// even if SubscriptionCacheTTL is set, the cache doesn't know about subscriptions created by other replicas.
+func (c *Client) lockSubscription(ctx context.Context, Type string, condition Condition) (unlock func(), err error) {
+	key, err := subscriptionLockKey(Type, condition)
+	if err != nil {
+		return nil, &InternalError{"Could not serialize condition", err}
+	}
+	lockCtx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+	start := time.Now()
+	unlock, err = c.locker.Lock(lockCtx, key)
+	if err != nil {
+		return nil, &InternalError{"Could not acquire subscription lock", err}
+	}
+	c.logger.Debug("Acquired subscription lock", "type", Type, "waited", time.Since(start))
+
+	subs, err := c.fetchSubscriptions(ctx, SubscriptionQuery{Type: Type})
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	for _, sub := range subs {
+		if sub.Type == Type && sub.Condition.EqualFor(Type, condition) && (sub.Status == "enabled" || sub.Status == statusVerificationPending) {
+			unlock()
+			return nil, &DuplicateSubscriptionError{Condition: condition, Type: Type}
+		}
+	}
+	return unlock, nil
+}
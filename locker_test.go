@@ -0,0 +1,37 @@
+package twitchwh
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLockerSkipsExistingSubscription(t *testing.T) {
+	var locked []string
+	c := newClient(ClientConfig{SubscriptionCacheTTL: time.Hour, Locker: LockerFunc(func(ctx context.Context, key string) (func(), error) {
+		locked = append(locked, key)
+		return func() {}, nil
+	})})
+	// Cached before another replica created the subscription
+	c.subscriptionCache.subs = []Subscription{}
+	c.subscriptionCache.fetchedAt = time.Now()
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected %s request", r.Method)
+			return jsonResponse(500, ""), nil
+		}
+		return jsonResponse(200, `{"data":[{"id":"1","type":"stream.online","status":"enabled",`+
+			`"condition":{"broadcaster_user_id":"1971641"}}],"pagination":{}}`), nil
+	})}
+
+	_, err := c.AddSubscription("stream.online", "1", Condition{BroadcasterUserID: "1971641"})
+	var duplicate *DuplicateSubscriptionError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("Expected DuplicateSubscriptionError, got %v", err)
+	}
+	if len(locked) != 1 {
+		t.Fatalf("Expected the lock to be acquired once, got %v", locked)
+	}
+}
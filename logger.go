@@ -0,0 +1,126 @@
+package twitchwh
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogLevel controls which messages the default logger writes.
+// It has no effect when ClientConfig.Logger is set, configure the level on your own handler instead.
+type LogLevel int
+
+const (
+	// Logging is disabled, unless ClientConfig.Debug is true.
+	LogLevelOff LogLevel = iota
+	// Only errors, like failed token refreshes or unreadable requests.
+	LogLevelError
+	// Errors and warnings, like revocations and failed verifications.
+	LogLevelWarn
+	// Errors, warnings, and subscription changes.
+	LogLevelInfo
+	// Everything, including a line per received event.
+	LogLevelDebug
+)
+
+func (l LogLevel) slogLevel() slog.Level {
+	switch l {
+	case LogLevelError:
+		return slog.LevelError
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelInfo:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// defaultLogger returns the logger used when ClientConfig.Logger is nil.
+// Debug is kept for compatibility and is equivalent to LogLevelDebug.
+func defaultLogger(level LogLevel, debug bool) *slog.Logger {
+	if debug {
+		level = LogLevelDebug
+	}
+	if level == LogLevelOff {
+		return slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level.slogLevel()})).With("lib", "twitchwh")
+}
+
+// Default interval for LogSampleInterval.
+const defaultLogSampleInterval = 10 * time.Second
+
+// logSampler rate limits repetitive log lines, like invalid signatures or duplicate events.
+// The first line per key is logged every interval, the rest are counted and summarized when the interval ends.
+type logSampler struct {
+	logger   *slog.Logger
+	interval time.Duration
+	mu       sync.Mutex
+	keys     map[string]*sampledKey
+}
+
+type sampledKey struct {
+	windowStart time.Time
+	suppressed  int
+	level       slog.Level
+	message     string
+}
+
+func newLogSampler(logger *slog.Logger, interval time.Duration) *logSampler {
+	return &logSampler{
+		logger:   logger,
+		interval: interval,
+		keys:     make(map[string]*sampledKey),
+	}
+}
+
+// log writes the message unless a message with the same key was already written in the current interval.
+func (s *logSampler) log(key string, level slog.Level, message string, attrs ...any) {
+	if s.interval <= 0 {
+		s.logger.Log(context.Background(), level, message, attrs...)
+		return
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	k, ok := s.keys[key]
+	if ok && now.Sub(k.windowStart) < s.interval {
+		k.suppressed++
+		if k.suppressed == 1 {
+			time.AfterFunc(s.interval-now.Sub(k.windowStart), func() { s.flush(key) })
+		}
+		s.mu.Unlock()
+		return
+	}
+	var suppressed int
+	if ok {
+		// The window ended before the flush timer ran, summarize here instead
+		suppressed = k.suppressed
+	}
+	s.keys[key] = &sampledKey{windowStart: now, level: level, message: message}
+	s.mu.Unlock()
+
+	if suppressed > 0 {
+		s.logger.Log(context.Background(), level, "Suppressed repeated log messages", "message", message, "count", suppressed, "interval", s.interval)
+	}
+	s.logger.Log(context.Background(), level, message, attrs...)
+}
+
+// flush writes a summary line for the suppressed messages of a key.
+func (s *logSampler) flush(key string) {
+	s.mu.Lock()
+	k, ok := s.keys[key]
+	if !ok || k.suppressed == 0 {
+		s.mu.Unlock()
+		return
+	}
+	suppressed := k.suppressed
+	delete(s.keys, key)
+	s.mu.Unlock()
+
+	s.logger.Log(context.Background(), k.level, "Suppressed repeated log messages", "message", k.message, "count", suppressed, "interval", s.interval)
+}
@@ -0,0 +1,25 @@
+package twitchwh
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogSampler(t *testing.T) {
+	var buf bytes.Buffer
+	sampler := newLogSampler(slog.New(slog.NewTextHandler(&buf, nil)), time.Hour)
+	for range 5 {
+		sampler.log("invalid-signature", slog.LevelWarn, "Received request with invalid signature")
+	}
+	if count := strings.Count(buf.String(), "Received request with invalid signature"); count != 1 {
+		t.Fatalf("Expected 1 line, got %d", count)
+	}
+
+	sampler.flush("invalid-signature")
+	if !strings.Contains(buf.String(), "count=4") {
+		t.Fatalf("Expected summary of 4 suppressed lines, got %q", buf.String())
+	}
+}
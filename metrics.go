@@ -0,0 +1,102 @@
+package twitchwh
+
+import "time"
+
+// MetricsHook receives telemetry from the client. Implement it to bridge TwitchWH to Prometheus, OpenTelemetry, or
+// any other metrics system.
+//
+// Methods are called synchronously from the request path and should not block.
+// Embed NoopMetricsHook to only implement the methods you need; new methods may be added in future versions.
+type MetricsHook interface {
+	// A verified, non-duplicate notification was received.
+	EventReceived(eventType string)
+	// A notification was ignored because its message ID was already handled.
+	DuplicateEvent(eventType string)
+	// A handler returned. err is non-nil if the handler returned an error or panicked.
+	HandlerFinished(eventType string, duration time.Duration, err error)
+	// A handler took longer than ClientConfig.SlowHandlerThreshold. Called in addition to HandlerFinished.
+	SlowHandler(eventType string, duration time.Duration)
+	// A request was rejected because of an invalid signature.
+	SignatureRejected()
+	// The app access token was regenerated.
+	TokenRefreshed()
+	// A notification was rejected with 503 Service Unavailable because ClientConfig.MaxConcurrentHandlers was reached,
+	// its queue was full, or the client is shutting down. Twitch redelivers it later.
+	NotificationShed(eventType string)
+	// A response was written to a webhook request. latency is the time since the request was received, Twitch
+	// expects a response within a few seconds. messageType is the Twitch-Eventsub-Message-Type header, empty for
+	// requests that aren't POST.
+	ResponseWritten(messageType string, status int, latency time.Duration)
+	// A notification was acknowledged but not passed to handlers and sinks. reason is one of the DropReason constants.
+	NotificationDropped(eventType string, reason string)
+}
+
+// NoopMetricsHook implements MetricsHook and does nothing.
+type NoopMetricsHook struct{}
+
+func (NoopMetricsHook) EventReceived(string)                         {}
+func (NoopMetricsHook) DuplicateEvent(string)                        {}
+func (NoopMetricsHook) HandlerFinished(string, time.Duration, error) {}
+func (NoopMetricsHook) SlowHandler(string, time.Duration)            {}
+func (NoopMetricsHook) SignatureRejected()                           {}
+func (NoopMetricsHook) TokenRefreshed()                              {}
+func (NoopMetricsHook) NotificationShed(string)                      {}
+func (NoopMetricsHook) ResponseWritten(string, int, time.Duration)   {}
+func (NoopMetricsHook) NotificationDropped(string, string)           {}
+
+// multiMetricsHook forwards every call to all hooks.
+type multiMetricsHook []MetricsHook
+
+func (m multiMetricsHook) EventReceived(eventType string) {
+	for _, h := range m {
+		h.EventReceived(eventType)
+	}
+}
+
+func (m multiMetricsHook) DuplicateEvent(eventType string) {
+	for _, h := range m {
+		h.DuplicateEvent(eventType)
+	}
+}
+
+func (m multiMetricsHook) HandlerFinished(eventType string, duration time.Duration, err error) {
+	for _, h := range m {
+		h.HandlerFinished(eventType, duration, err)
+	}
+}
+
+func (m multiMetricsHook) SlowHandler(eventType string, duration time.Duration) {
+	for _, h := range m {
+		h.SlowHandler(eventType, duration)
+	}
+}
+
+func (m multiMetricsHook) SignatureRejected() {
+	for _, h := range m {
+		h.SignatureRejected()
+	}
+}
+
+func (m multiMetricsHook) TokenRefreshed() {
+	for _, h := range m {
+		h.TokenRefreshed()
+	}
+}
+
+func (m multiMetricsHook) NotificationShed(eventType string) {
+	for _, h := range m {
+		h.NotificationShed(eventType)
+	}
+}
+
+func (m multiMetricsHook) ResponseWritten(messageType string, status int, latency time.Duration) {
+	for _, h := range m {
+		h.ResponseWritten(messageType, status, latency)
+	}
+}
+
+func (m multiMetricsHook) NotificationDropped(eventType string, reason string) {
+	for _, h := range m {
+		h.NotificationDropped(eventType, reason)
+	}
+}
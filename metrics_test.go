@@ -0,0 +1,132 @@
+package twitchwh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type handlerResult struct {
+	eventType string
+	duration  time.Duration
+	err       error
+}
+
+// recordingMetricsHook records the calls of the MetricsHook methods the tests check.
+type recordingMetricsHook struct {
+	NoopMetricsHook
+	mu        sync.Mutex
+	received  []string
+	responses []int
+	latencies []time.Duration
+	finished  chan handlerResult
+}
+
+func (r *recordingMetricsHook) EventReceived(eventType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received = append(r.received, eventType)
+}
+
+func (r *recordingMetricsHook) HandlerFinished(eventType string, duration time.Duration, err error) {
+	r.finished <- handlerResult{eventType, duration, err}
+}
+
+func (r *recordingMetricsHook) ResponseWritten(messageType string, status int, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, status)
+	r.latencies = append(r.latencies, latency)
+}
+
+func TestMetricsHook(t *testing.T) {
+	hook := &recordingMetricsHook{finished: make(chan handlerResult, 2)}
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, MetricsHook: hook})
+	failure := errors.New("failed")
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		time.Sleep(10 * time.Millisecond)
+		if MessageIDFromContext(ctx) == "b" {
+			return failure
+		}
+		return nil
+	})
+
+	for _, id := range []string{"a", "b"} {
+		w := httptest.NewRecorder()
+		c.Handler(w, signedRequest(id, messageTypeNotification, chatMessageBody))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+	}
+
+	var failed int
+	for i := 0; i < 2; i++ {
+		select {
+		case result := <-hook.finished:
+			if result.eventType != "channel.chat.message" || result.duration < 10*time.Millisecond {
+				t.Errorf("Unexpected handler result %+v", result)
+			}
+			if result.err != nil {
+				if !errors.Is(result.err, failure) {
+					t.Errorf("Expected the handler's error, got %v", result.err)
+				}
+				failed++
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected HandlerFinished for every notification")
+		}
+	}
+	if failed != 1 {
+		t.Fatalf("Expected 1 failed handler, got %d", failed)
+	}
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+	if len(hook.received) != 2 || hook.received[0] != "channel.chat.message" {
+		t.Fatalf("Expected 2 received events, got %v", hook.received)
+	}
+	if len(hook.responses) != 2 || hook.responses[0] != http.StatusNoContent || hook.responses[1] != http.StatusNoContent {
+		t.Fatalf("Expected 2 responses with status 204, got %v", hook.responses)
+	}
+	for _, latency := range hook.latencies {
+		if latency <= 0 || latency > time.Second {
+			t.Fatalf("Unexpected response latency %s", latency)
+		}
+	}
+}
+
+func TestSlowHandlerThreshold(t *testing.T) {
+	var logs bytes.Buffer
+	c := newClient(ClientConfig{
+		SlowHandlerThreshold: 20 * time.Millisecond,
+		Logger:               slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	c.OnContext("stream.online", func(ctx context.Context, event json.RawMessage) error {
+		return nil
+	})
+	c.OnContext("stream.offline", func(ctx context.Context, event json.RawMessage) error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	})
+
+	if err := c.processNotification(Notification{Subscription: Subscription{Type: "stream.online"}}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(logs.String(), "Slow handler") {
+		t.Fatalf("Expected no warning for a fast handler, got %q", logs.String())
+	}
+	if err := c.processNotification(Notification{Subscription: Subscription{Type: "stream.offline"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "Slow handler") || !strings.Contains(logs.String(), "type=stream.offline") {
+		t.Fatalf("Expected a slow handler warning, got %q", logs.String())
+	}
+}
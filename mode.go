@@ -0,0 +1,83 @@
+package twitchwh
+
+import "context"
+
+// Mode selects the parts of the client that are used, for architectures that split the webhook receiver from the
+// subscription manager across services. Both need the same webhook secret.
+type Mode int
+
+const (
+	// Receive notifications and manage subscriptions. The default.
+	ModeFull Mode = iota
+	// Only receive notifications: Client.Handler verifies and dispatches them. No client secret is needed and no
+	// token is generated. Helix calls, eg: AddSubscription, return ModeError.
+	ModeReceiver
+	// Only manage subscriptions. Client.Handler responds 404 Not Found. Since the receiver answers the verification
+	// challenge, AddSubscription returns as soon as Twitch accepted the subscription, unless
+	// ClientConfig.VerificationRelay is set.
+	ModeAPI
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeFull:
+		return "full"
+	case ModeReceiver:
+		return "receiver"
+	case ModeAPI:
+		return "API"
+	}
+	return "unknown"
+}
+
+// NewReceiver creates a client in ModeReceiver, which only needs the webhook secret.
+//
+//	client, err := twitchwh.NewReceiver(os.Getenv("WEBHOOK_SECRET"))
+//	client.On("stream.online", handleOnline)
+//	http.HandleFunc("/eventsub", client.Handler)
+func NewReceiver(webhookSecret string, opts ...Option) (*Client, error) {
+	opts = append(opts, func(o *clientOptions) {
+		o.config.Mode = ModeReceiver
+		o.config.WebhookSecret = webhookSecret
+	})
+	return NewClient("", "", opts...)
+}
+
+// receiverHelix is the HelixAPI of a client in ModeReceiver, every call fails.
+type receiverHelix struct{}
+
+func (receiverHelix) CreateSubscription(ctx context.Context, req SubscriptionRequest) (Subscription, error) {
+	return Subscription{}, &ModeError{ModeReceiver}
+}
+
+func (receiverHelix) DeleteSubscription(ctx context.Context, id string) error {
+	return &ModeError{ModeReceiver}
+}
+
+func (receiverHelix) GetSubscriptions(ctx context.Context, query SubscriptionQuery) ([]Subscription, string, error) {
+	return nil, "", &ModeError{ModeReceiver}
+}
+
+func (receiverHelix) GetStreams(ctx context.Context, userIDs []string) ([]Stream, error) {
+	return nil, &ModeError{ModeReceiver}
+}
+
+func (receiverHelix) GetChannels(ctx context.Context, broadcasterIDs []string) ([]Channel, error) {
+	return nil, &ModeError{ModeReceiver}
+}
+
+func (receiverHelix) GetConduits(ctx context.Context) ([]Conduit, error) {
+	return nil, &ModeError{ModeReceiver}
+}
+
+func (receiverHelix) CreateConduit(ctx context.Context, shardCount int) (Conduit, error) {
+	return Conduit{}, &ModeError{ModeReceiver}
+}
+
+func (receiverHelix) UpdateConduit(ctx context.Context, conduitID string, shardCount int) error {
+	return &ModeError{ModeReceiver}
+}
+
+func (receiverHelix) UpdateConduitShards(ctx context.Context, conduitID string, shards []ConduitShard) ([]ConduitShardError, error) {
+	return nil, &ModeError{ModeReceiver}
+}
@@ -0,0 +1,58 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReceiverMode(t *testing.T) {
+	// No token is generated, so no request is sent
+	c, err := NewReceiver(testWebhookSecret, WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		t.Fatalf("Unexpected request to %s", r.URL)
+		return nil, nil
+	})}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan struct{}, 1)
+	c.On("channel.chat.message", func(event json.RawMessage) {
+		received <- struct{}{}
+	})
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", w.Code)
+	}
+	<-received
+
+	var modeErr *ModeError
+	if _, err := c.AddSubscription("stream.online", "1", Condition{BroadcasterUserID: "1"}); !errors.As(err, &modeErr) || modeErr.Mode != ModeReceiver {
+		t.Fatalf("Expected ModeError, got %v", err)
+	}
+
+	if _, err := NewReceiver(""); err == nil {
+		t.Fatal("Expected error without a webhook secret")
+	}
+}
+
+func TestAPIMode(t *testing.T) {
+	c := newClient(ClientConfig{Mode: ModeAPI, WebhookSecret: testWebhookSecret, WebhookURL: "https://example.com/eventsub"})
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(202, `{"data":[{"id":"1","type":"stream.online","version":"1","status":"webhook_callback_verification_pending"}]}`), nil
+	})}
+
+	// Returns without waiting for the challenge, which another service answers
+	id, err := c.AddSubscription("stream.online", "1", Condition{BroadcasterUserID: "1"})
+	if err != nil || id != "1" {
+		t.Fatalf("Expected subscription 1, got %q, %v", id, err)
+	}
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404, got %d", w.Code)
+	}
+}
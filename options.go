@@ -0,0 +1,84 @@
+package twitchwh
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Option configures a client created with NewClient.
+type Option func(o *clientOptions)
+
+type clientOptions struct {
+	config     ClientConfig
+	httpClient *http.Client
+	helixURL   string
+	oauthURL   string
+}
+
+// WithConfig applies every field of config except ClientID and ClientSecret, which are passed to NewClient.
+// Options after it override its fields.
+func WithConfig(config ClientConfig) Option {
+	return func(o *clientOptions) {
+		o.config = config
+	}
+}
+
+// WithWebhook sets the EventSub callback URL (eg: https://mydomain.com/eventsub) and the secret used to verify events.
+func WithWebhook(url string, secret string) Option {
+	return func(o *clientOptions) {
+		o.config.WebhookURL = url
+		o.config.WebhookSecret = secret
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for Helix and OAuth requests. Defaults to a new http.Client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithLogger sets the structured logger, see ClientConfig.Logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) {
+		o.config.Logger = logger
+	}
+}
+
+// WithChecker sets the store used to detect duplicate notifications, see ClientConfig.HandledEventsChecker.
+func WithChecker(checker HandledEventsChecker) Option {
+	return func(o *clientOptions) {
+		o.config.HandledEventsChecker = checker
+	}
+}
+
+// WithMetricsHook sets the telemetry hook, see ClientConfig.MetricsHook.
+func WithMetricsHook(hook MetricsHook) Option {
+	return func(o *clientOptions) {
+		o.config.MetricsHook = hook
+	}
+}
+
+// WithErrorHandler sets the function receiving internal errors, see ClientConfig.ErrorHandler.
+func WithErrorHandler(handler func(error)) Option {
+	return func(o *clientOptions) {
+		o.config.ErrorHandler = handler
+	}
+}
+
+// WithHelixURL sets the base URL of the Helix API, eg: to point the client at the Twitch CLI mock API
+// (http://localhost:8080/mock) or a test server. Defaults to https://api.twitch.tv/helix.
+func WithHelixURL(url string) Option {
+	return func(o *clientOptions) {
+		o.helixURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithAuthURL sets the base URL of the OAuth API used to generate and validate tokens.
+// Defaults to https://id.twitch.tv/oauth2.
+func WithAuthURL(url string) Option {
+	return func(o *clientOptions) {
+		o.oauthURL = strings.TrimSuffix(url, "/")
+	}
+}
@@ -0,0 +1,44 @@
+package twitchwh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewClientOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/token":
+			w.Write([]byte(`{"access_token":"token","expires_in":3600,"token_type":"bearer"}`))
+		case "/helix/eventsub/subscriptions":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(401)
+				return
+			}
+			w.Write([]byte(`{"data":[{"id":"1","type":"stream.online","status":"enabled"}],"pagination":{}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient("client-id", "client-secret",
+		WithWebhook("https://example.com/eventsub", testWebhookSecret),
+		WithHelixURL(server.URL+"/helix/"),
+		WithAuthURL(server.URL+"/oauth2"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.GetWebhookURL() != "https://example.com/eventsub" || c.GetWebhookSecret() != testWebhookSecret {
+		t.Fatal("Expected webhook URL and secret to be set")
+	}
+	subs, err := c.GetSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 {
+		t.Fatalf("Expected 1 subscription, got %d", len(subs))
+	}
+}
@@ -0,0 +1,159 @@
+package twitchwh
+
+import (
+	"context"
+	"time"
+)
+
+// Outbox persists notifications before they are acknowledged, so a crash between receiving and processing an event
+// doesn't lose it. See ClientConfig.Outbox and the sqloutbox package for a SQLite/Postgres implementation.
+type Outbox interface {
+	// Store persists a notification. Storing a message ID that already exists must not return an error.
+	Store(ctx context.Context, n Notification) error
+	// Claim returns up to limit undelivered entries that are due, oldest first, and leases them to the caller until
+	// the lease expires, so no other dispatcher claims them in the meantime.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxEntry, error)
+	// Complete marks an entry as delivered.
+	Complete(ctx context.Context, id int64) error
+	// Fail records a failed delivery. The entry is claimable again at retryAt, or never if retryAt is zero.
+	Fail(ctx context.Context, id int64, cause error, retryAt time.Time) error
+}
+
+// OutboxEntry is a stored notification.
+type OutboxEntry struct {
+	ID int64
+	// Number of times the entry was claimed, including the current claim.
+	Attempts     int
+	Notification Notification
+}
+
+const (
+	defaultOutboxPollInterval = 5 * time.Second
+	defaultOutboxMaxAttempts  = 10
+	outboxBatchSize           = 100
+	defaultOutboxLease        = 1 * time.Minute
+)
+
+// outboxRetryPolicy is the backoff between delivery attempts of an entry.
+var outboxRetryPolicy = RetryPolicy{InitialBackoff: 1 * time.Second, MaxBackoff: 5 * time.Minute}
+
+// wakeOutbox makes the outbox dispatcher check for new entries immediately.
+func (c *Client) wakeOutbox() {
+	select {
+	case c.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// startOutbox starts the outbox dispatcher, which runs until Shutdown or Close.
+func (c *Client) startOutbox() {
+	ctx, stop := context.WithCancel(c.background.ctx)
+	c.stopOutbox = stop
+	c.outboxDone = make(chan struct{})
+	c.goBackground(func(context.Context) {
+		defer close(c.outboxDone)
+		c.runOutbox(ctx)
+	})
+}
+
+// stopOutboxDispatcher stops the outbox dispatcher and waits for its current entry to be delivered, or ctx to be
+// done. Does nothing if the dispatcher wasn't started.
+func (c *Client) stopOutboxDispatcher(ctx context.Context) error {
+	if c.stopOutbox == nil {
+		return nil
+	}
+	c.stopOutbox()
+	select {
+	case <-c.outboxDone:
+		return nil
+	case <-ctx.Done():
+		c.logger.Warn("Outbox dispatcher did not stop in time")
+		return ctx.Err()
+	}
+}
+
+// runOutbox delivers outbox entries to the handlers and sinks until ctx is done.
+func (c *Client) runOutbox(ctx context.Context) {
+	ticker := time.NewTicker(c.outboxPollInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil && c.dispatchOutbox() == outboxBatchSize {
+			// Full batch, there may be more entries
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.outboxWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchOutbox delivers a batch of entries and returns the number of entries claimed.
+//
+// Entries are delivered one after another under the lease of the batch. Once half of the lease is used up, the rest
+// of the batch is left alone, so no entry is started that another dispatcher may claim while it is delivered. The
+// skipped entries are claimable again when the lease expires. The same goes for the rest of the batch once Shutdown was
+// called.
+func (c *Client) dispatchOutbox() int {
+	ctx := context.Background()
+	claimed := time.Now()
+	entries, err := c.outbox.Claim(ctx, outboxBatchSize, c.outboxLease)
+	if err != nil {
+		c.reportError("Could not claim outbox entries", err)
+		return 0
+	}
+	for i, entry := range entries {
+		if time.Since(claimed) > c.outboxLease/2 {
+			c.logger.Debug("Outbox lease half used, leaving the rest of the batch for the next claim", "skipped", len(entries)-i)
+			break
+		}
+		if c.draining.Load() {
+			c.logger.Debug("Shutting down, leaving the rest of the outbox batch for the next claim", "skipped", len(entries)-i)
+			break
+		}
+		c.dispatchOutboxEntry(ctx, entry)
+	}
+	return len(entries)
+}
+
+// dispatchOutboxEntry delivers an entry to the handlers and sinks. Entries of types registered with OnBatch are
+// finished once their batch was handled, so they aren't lost if the process crashes before.
+func (c *Client) dispatchOutboxEntry(ctx context.Context, entry OutboxEntry) {
+	n := entry.Notification
+	b, ok := c.batcher(n.Subscription.Type)
+	if !ok {
+		c.finishOutboxEntry(ctx, entry, c.processNotification(n))
+		return
+	}
+	c.sinks.mu.RLock()
+	sinks := c.sinks.entries
+	c.sinks.mu.RUnlock()
+	if err := c.deliver(sinks, n); err != nil {
+		c.finishOutboxEntry(ctx, entry, err)
+		return
+	}
+	b.add(n, func(err error) {
+		c.finishOutboxEntry(context.WithoutCancel(ctx), entry, err)
+	})
+}
+
+// finishOutboxEntry completes an entry if err is nil, and records the failed delivery otherwise.
+func (c *Client) finishOutboxEntry(ctx context.Context, entry OutboxEntry, err error) {
+	if err == nil {
+		if err := c.outbox.Complete(ctx, entry.ID); err != nil {
+			c.reportError("Could not complete outbox entry", err, "message_id", entry.Notification.MessageID)
+		}
+		return
+	}
+
+	var retryAt time.Time
+	if entry.Attempts < c.outboxMaxAttempts {
+		retryAt = time.Now().Add(outboxRetryPolicy.backoff(entry.Attempts))
+	} else {
+		c.logger.Error("Giving up on outbox entry", "message_id", entry.Notification.MessageID, "attempts", entry.Attempts)
+	}
+	if err := c.outbox.Fail(ctx, entry.ID, err, retryAt); err != nil {
+		c.reportError("Could not record outbox failure", err, "message_id", entry.Notification.MessageID)
+	}
+}
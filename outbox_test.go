@@ -0,0 +1,138 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryOutbox is an Outbox that records completions and failures.
+type memoryOutbox struct {
+	mu        sync.Mutex
+	entries   []*memoryOutboxEntry
+	completed []int64
+	retryAt   []time.Time
+}
+
+type memoryOutboxEntry struct {
+	OutboxEntry
+	availableAt time.Time
+	delivered   bool
+}
+
+func (o *memoryOutbox) Store(ctx context.Context, n Notification) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, e := range o.entries {
+		if e.Notification.MessageID == n.MessageID {
+			return nil
+		}
+	}
+	o.entries = append(o.entries, &memoryOutboxEntry{OutboxEntry: OutboxEntry{ID: int64(len(o.entries) + 1), Notification: n}})
+	return nil
+}
+
+func (o *memoryOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now()
+	var claimed []OutboxEntry
+	for _, e := range o.entries {
+		if len(claimed) == limit {
+			break
+		}
+		if e.delivered || e.availableAt.IsZero() && e.Attempts > 0 || e.availableAt.After(now) {
+			continue
+		}
+		e.Attempts++
+		e.availableAt = now.Add(lease)
+		claimed = append(claimed, e.OutboxEntry)
+	}
+	return claimed, nil
+}
+
+func (o *memoryOutbox) Complete(ctx context.Context, id int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries[id-1].delivered = true
+	o.completed = append(o.completed, id)
+	return nil
+}
+
+func (o *memoryOutbox) Fail(ctx context.Context, id int64, cause error, retryAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries[id-1].availableAt = retryAt
+	o.retryAt = append(o.retryAt, retryAt)
+	return nil
+}
+
+func TestOutboxDispatch(t *testing.T) {
+	outbox := &memoryOutbox{}
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, Outbox: outbox, OutboxMaxAttempts: 2})
+	fail := true
+	var handled []string
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		handled = append(handled, string(event))
+		if fail {
+			return errors.New("broken")
+		}
+		return nil
+	})
+
+	c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+	if len(outbox.entries) != 1 {
+		t.Fatalf("Expected the notification to be stored, got %d entries", len(outbox.entries))
+	}
+	if n := c.dispatchOutbox(); n != 1 || len(handled) != 1 || len(outbox.retryAt) != 1 || outbox.retryAt[0].IsZero() {
+		t.Fatalf("Expected a failed delivery to be retried later, got %d claimed and retries %v", n, outbox.retryAt)
+	}
+
+	// Due for the last attempt
+	outbox.entries[0].availableAt = time.Now()
+	c.dispatchOutbox()
+	if len(outbox.retryAt) != 2 || !outbox.retryAt[1].IsZero() {
+		t.Fatalf("Expected the entry to be given up on after 2 attempts, got retries %v", outbox.retryAt)
+	}
+
+	fail = false
+	c.Handler(httptest.NewRecorder(), signedRequest("b", messageTypeNotification, chatMessageBody))
+	c.dispatchOutbox()
+	if len(outbox.completed) != 1 || outbox.completed[0] != 2 {
+		t.Fatalf("Expected the second entry to be completed, got %v", outbox.completed)
+	}
+}
+
+func TestOutboxLease(t *testing.T) {
+	outbox := &memoryOutbox{}
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, Outbox: outbox})
+	c.outboxLease = 100 * time.Millisecond
+	handled := 0
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		handled++
+		time.Sleep(60 * time.Millisecond)
+		return nil
+	})
+	for _, id := range []string{"a", "b", "c"} {
+		c.Handler(httptest.NewRecorder(), signedRequest(id, messageTypeNotification, chatMessageBody))
+	}
+
+	if n := c.dispatchOutbox(); n != 3 {
+		t.Fatalf("Expected 3 claimed entries, got %d", n)
+	}
+	if handled != 1 || len(outbox.completed) != 1 {
+		t.Fatalf("Expected the batch to stop once half of the lease was used, handled %d", handled)
+	}
+	// The skipped entries are claimable once the lease expired
+	if n := c.dispatchOutbox(); n != 0 {
+		t.Fatalf("Expected the skipped entries to stay leased, claimed %d", n)
+	}
+	time.Sleep(c.outboxLease)
+	if n := c.dispatchOutbox(); n != 2 || handled != 2 {
+		t.Fatalf("Expected the skipped entries to be claimed again, claimed %d and handled %d", n, handled)
+	}
+}
@@ -0,0 +1,43 @@
+package twitchwh
+
+// OwnsSubscription reports whether a subscription belongs to this service rather than another one sharing the
+// Client-ID. A subscription is owned if this client created it, it is recorded in the Registry, or it is a webhook
+// subscription whose callback is the client's webhook URL, or a URL replaced by SetWebhookURL.
+//
+// Use it to guard cleanup, so subscriptions of other services are never deleted:
+//
+//	subs, _ := client.GetSubscriptions()
+//	for _, sub := range subs {
+//		if owned, _ := client.OwnsSubscription(sub); owned && sub.Status != "enabled" {
+//			client.RemoveSubscription(sub.ID)
+//		}
+//	}
+func (c *Client) OwnsSubscription(sub Subscription) (bool, error) {
+	if c.created.contains(sub.ID) {
+		return true, nil
+	}
+	if sub.Transport.Method == "webhook" && (sub.Transport.Callback == c.GetWebhookURL() || c.previousWebhookURL(sub.Transport.Callback)) {
+		return true, nil
+	}
+	_, ok, err := c.registry.Owner(sub.ID)
+	return ok, err
+}
+
+// GetOwnedSubscriptions retrieves all subscriptions owned by this service, see OwnsSubscription.
+func (c *Client) GetOwnedSubscriptions() ([]Subscription, error) {
+	subs, err := c.GetSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	owned := subs[:0:0]
+	for _, sub := range subs {
+		ok, err := c.OwnsSubscription(sub)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			owned = append(owned, sub)
+		}
+	}
+	return owned, nil
+}
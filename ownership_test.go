@@ -0,0 +1,35 @@
+package twitchwh
+
+import "testing"
+
+func TestOwnsSubscription(t *testing.T) {
+	c := newClient(ClientConfig{WebhookURL: "https://previous.example.com/eventsub"})
+	c.SetWebhookURL("https://example.com/eventsub")
+	c.created.add("created")
+	c.registry.Assign("tenant", "tenant-a")
+
+	sub := func(id, callback string) Subscription {
+		s := Subscription{ID: id}
+		s.Transport.Method = "webhook"
+		s.Transport.Callback = callback
+		return s
+	}
+	for _, tt := range []struct {
+		sub   Subscription
+		owned bool
+	}{
+		{sub("created", "https://old.example.com/eventsub"), true},
+		{sub("tenant", "https://old.example.com/eventsub"), true},
+		{sub("callback", "https://example.com/eventsub"), true},
+		{sub("previous", "https://previous.example.com/eventsub"), true},
+		{sub("other", "https://other-service.example.com/eventsub"), false},
+	} {
+		owned, err := c.OwnsSubscription(tt.sub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if owned != tt.owned {
+			t.Errorf("Expected %s to be owned: %v, got %v", tt.sub.ID, tt.owned, owned)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package twitchwh
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// Transform modifies a notification before it is published to a sink, eg: to strip PII.
+// Transforms run in the order they were added, after all filters matched.
+// If a transform returns an error, the notification is not published to the sink and the error is reported.
+//
+// Transforms receive a copy of the notification, but Event is shared with other sinks and handlers.
+// Replace it instead of modifying it in place.
+type Transform func(n Notification) (Notification, error)
+
+func (t Transform) applySink(s *sinkEntry) {
+	s.transforms = append(s.transforms, t)
+}
+
+// transform applies all transforms of the sink to n.
+func (s *sinkEntry) transform(n Notification) (Notification, error) {
+	for _, t := range s.transforms {
+		var err error
+		n, err = t(n)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// BroadcasterFilter matches notifications for any of the given broadcasters, see Notification.BroadcasterUserID.
+func BroadcasterFilter(broadcasterUserIDs ...string) Filter {
+	return func(n Notification) bool {
+		return slices.Contains(broadcasterUserIDs, n.BroadcasterUserID())
+	}
+}
+
+// FieldFilter matches notifications whose event has a field at path (dot separated, eg: "message.text"),
+// for which match returns true. match receives the raw JSON value of the field.
+// Notifications without the field don't match.
+//
+//	// Only forward cheers of at least 1000 bits
+//	twitchwh.FieldFilter("bits", func(v json.RawMessage) bool {
+//		var bits int
+//		return json.Unmarshal(v, &bits) == nil && bits >= 1000
+//	})
+func FieldFilter(path string, match func(value json.RawMessage) bool) Filter {
+	keys := strings.Split(path, ".")
+	return func(n Notification) bool {
+		value, ok := lookupField(n.Event, keys)
+		return ok && match(value)
+	}
+}
+
+// FieldEquals matches notifications whose event has a field at path equal to value, compared after JSON encoding value.
+//
+//	twitchwh.FieldEquals("tier", "3000")
+func FieldEquals(path string, value any) Filter {
+	expected, err := json.Marshal(value)
+	if err != nil {
+		return func(Notification) bool { return false }
+	}
+	return FieldFilter(path, func(v json.RawMessage) bool {
+		return bytes.Equal(bytes.TrimSpace(v), expected)
+	})
+}
+
+func lookupField(event json.RawMessage, keys []string) (json.RawMessage, bool) {
+	value := event
+	for _, key := range keys {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(value, &object); err != nil {
+			return nil, false
+		}
+		var ok bool
+		value, ok = object[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// RemoveFields returns a Transform that deletes fields from the event, eg: RemoveFields("user_name", "message.text").
+// Paths are dot separated. Missing fields are ignored.
+func RemoveFields(paths ...string) Transform {
+	return func(n Notification) (Notification, error) {
+		decoder := json.NewDecoder(bytes.NewReader(n.Event))
+		decoder.UseNumber()
+		var event map[string]any
+		if err := decoder.Decode(&event); err != nil {
+			return n, err
+		}
+		for _, path := range paths {
+			removeField(event, strings.Split(path, "."))
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return n, err
+		}
+		n.Event = data
+		return n, nil
+	}
+}
+
+func removeField(object map[string]any, keys []string) {
+	if len(keys) == 1 {
+		delete(object, keys[0])
+		return
+	}
+	if child, ok := object[keys[0]].(map[string]any); ok {
+		removeField(child, keys[1:])
+	}
+}
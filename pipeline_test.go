@@ -0,0 +1,36 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	n := Notification{
+		Subscription: Subscription{Type: "channel.chat.message", Condition: Condition{BroadcasterUserID: "1"}},
+		Event:        json.RawMessage(`{"chatter_user_name":"someone","message":{"text":"hi","fragments":[]},"tier":"3000"}`),
+	}
+	entry := &sinkEntry{}
+	for _, option := range []SinkOption{
+		TypeFilter("channel.chat.message"),
+		BroadcasterFilter("1"),
+		FieldEquals("message.text", "hi"),
+		RemoveFields("chatter_user_name", "message.text"),
+	} {
+		option.applySink(entry)
+	}
+	if !entry.matches(n) {
+		t.Fatal("Expected notification to match")
+	}
+	transformed, err := entry.transform(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"message":{"fragments":[]},"tier":"3000"}`; string(transformed.Event) != expected {
+		t.Fatalf("Expected %s, got %s", expected, transformed.Event)
+	}
+
+	if FieldEquals("message.text", "bye")(n) {
+		t.Fatal("Expected different field value not to match")
+	}
+}
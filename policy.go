@@ -0,0 +1,70 @@
+package twitchwh
+
+// ResponsePolicy configures the delivery guarantees of an event type, see Client.SetResponsePolicy.
+// The zero value is the default behavior: handlers run after the response, and duplicates are dropped.
+type ResponsePolicy struct {
+	// Run the handler before responding. If it fails, FailureStatus is responded and the notification is not marked
+	// as handled, so Twitch redelivers it. Use it for events that must not be lost, like channel.cheer.
+	// Ignored with ClientConfig.Outbox, which already persists notifications before responding.
+	// Handlers must finish well within Twitch's response timeout of a few seconds.
+	HandleBeforeResponse bool
+	// Status code responded with when a handler run before the response fails. A 2xx code acknowledges the
+	// notification anyway. Defaults to 500.
+	FailureStatus int
+	// Deliver notifications whose message ID was already handled instead of dropping them,
+	// eg: for handlers that are idempotent and would rather see a redelivery twice than miss it.
+	DeliverDuplicates bool
+	// Retry failed handlers in-process with exponential backoff, independent of Twitch's redelivery, so transient
+	// failures like a database restart heal themselves. Notifications are passed to ClientConfig.HandlerDeadLetter
+	// once all attempts failed, and only the last error is passed to ClientConfig.ErrorHandler. Retries block the
+	// handler's worker with DispatchWorkers, delay the response with HandleBeforeResponse, and stop when the client is
+	// closed.
+	Retry RetryPolicy
+}
+
+// SetResponsePolicy sets the delivery guarantees for an event type, so eg: financial events can be handled before
+// acknowledging them while chat messages are acknowledged immediately:
+//
+//	client.SetResponsePolicy("channel.cheer", twitchwh.ResponsePolicy{HandleBeforeResponse: true})
+func (c *Client) SetResponsePolicy(eventType string, policy ResponsePolicy) {
+	if policy.FailureStatus == 0 {
+		policy.FailureStatus = 500
+	}
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	if c.responsePolicies == nil {
+		c.responsePolicies = make(map[string]ResponsePolicy)
+	}
+	c.responsePolicies[eventType] = policy
+}
+
+func (c *Client) responsePolicy(eventType string) ResponsePolicy {
+	c.handlersMu.RLock()
+	defer c.handlersMu.RUnlock()
+	return c.responsePolicies[eventType]
+}
+
+// handleNotificationNow runs the handler for a notification synchronously and publishes it to sinks. Notifications of
+// types registered with OnBatch are added to the batch, which is handled after the response.
+// Returns the status code to respond with.
+func (c *Client) handleNotificationNow(n Notification, policy ResponsePolicy) int {
+	c.publish(n)
+	if b, ok := c.batcher(n.Subscription.Type); ok {
+		b.add(n, nil)
+		c.handledEventsChecker.MarkHandled(n.MessageID)
+		return 204
+	}
+	handler, ok := c.notificationHandler(n)
+	if !ok {
+		c.handledEventsChecker.MarkHandled(n.MessageID)
+		return 204
+	}
+	c.runningHandlers.Add(1)
+	err := c.dispatch(n, handler)
+	c.runningHandlers.Add(-1)
+	if err != nil {
+		return policy.FailureStatus
+	}
+	c.handledEventsChecker.MarkHandled(n.MessageID)
+	return 204
+}
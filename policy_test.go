@@ -0,0 +1,131 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleBeforeResponse(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	c.SetResponsePolicy("channel.chat.message", ResponsePolicy{HandleBeforeResponse: true})
+	calls := 0
+	c.OnContext("channel.chat.message", func(ctx context.Context, event json.RawMessage) error {
+		calls++
+		if calls == 1 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500 for failed handler, got %d", w.Code)
+	}
+	// Twitch redelivers with the same message ID
+	w = httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent || calls != 2 {
+		t.Fatalf("Expected redelivery to be handled, got status %d after %d calls", w.Code, calls)
+	}
+	w = httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if calls != 2 {
+		t.Fatalf("Expected duplicate to be dropped, got %d calls", calls)
+	}
+}
+
+func TestDeliverDuplicates(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	c.SetResponsePolicy("channel.chat.message", ResponsePolicy{DeliverDuplicates: true})
+	received := make(chan struct{}, 2)
+	c.On("channel.chat.message", func(event json.RawMessage) {
+		received <- struct{}{}
+	})
+
+	for i := 0; i < 2; i++ {
+		c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected duplicate to be delivered, got %d deliveries", i)
+		}
+	}
+}
+
+func TestHandlerRetry(t *testing.T) {
+	var deadLettered, reported []error
+	c := newClient(ClientConfig{
+		HandlerDeadLetter: func(n Notification, err error) {
+			deadLettered = append(deadLettered, err)
+		},
+		ErrorHandler: func(err error) {
+			reported = append(reported, err)
+		},
+	})
+	c.SetResponsePolicy("channel.chat.message", ResponsePolicy{
+		Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	calls := 0
+	flaky := func(ctx context.Context, event json.RawMessage) error {
+		calls++
+		if calls < 3 {
+			return errors.New("database restarting")
+		}
+		return nil
+	}
+
+	n := Notification{Subscription: Subscription{Type: "channel.chat.message"}}
+	if err := c.dispatch(n, flaky); err != nil || calls != 3 {
+		t.Fatalf("Expected handler to succeed on the third attempt, got %v after %d calls", err, calls)
+	}
+	if len(reported) != 0 {
+		t.Fatalf("Expected failed attempts of a succeeding handler not to be reported, got %v", reported)
+	}
+	broken := func(ctx context.Context, event json.RawMessage) error {
+		return errors.New("broken")
+	}
+	if err := c.dispatch(n, broken); err == nil {
+		t.Fatal("Expected error after all attempts failed")
+	}
+	if len(deadLettered) != 1 {
+		t.Fatalf("Expected notification to be dead-lettered once, got %d", len(deadLettered))
+	}
+	if len(reported) != 1 {
+		t.Fatalf("Expected the error to be reported once after the last attempt, got %v", reported)
+	}
+}
+
+func TestHandlerRetryClose(t *testing.T) {
+	c := newClient(ClientConfig{})
+	c.SetResponsePolicy("channel.chat.message", ResponsePolicy{
+		Retry: RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour},
+	})
+	calls := 0
+	broken := func(ctx context.Context, event json.RawMessage) error {
+		calls++
+		return errors.New("broken")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.dispatch(Notification{Subscription: Subscription{Type: "channel.chat.message"}}, broken)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	c.Close()
+	select {
+	case err := <-done:
+		if err == nil || calls != 1 {
+			t.Fatalf("Expected the first attempt's error, got %v after %d calls", err, calls)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to stop waiting for the next attempt")
+	}
+}
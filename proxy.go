@@ -0,0 +1,70 @@
+package twitchwh
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// parseTrustedProxies parses ClientConfig.TrustedProxies. Entries are IP addresses or CIDR ranges,
+// invalid entries are logged and skipped.
+func (c *Client) parseTrustedProxies(proxies []string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				c.logger.Error("Invalid trusted proxy", "proxy", proxy, "error", err)
+				continue
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			c.logger.Error("Invalid trusted proxy", "proxy", proxy, "error", err)
+			continue
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes
+}
+
+func (c *Client) trustedProxy(addr netip.Addr) bool {
+	for _, prefix := range c.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address a request came from, for logging and auditing. X-Forwarded-For is only honored if
+// the request came from a trusted proxy, in which case the rightmost address that isn't a trusted proxy is returned,
+// since anything left of it could have been set by the client.
+func (c *Client) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !c.trustedProxy(addr.Unmap()) {
+		return host
+	}
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		hopAddr, err := netip.ParseAddr(hop)
+		if err != nil {
+			// Can't tell whether anything further left is trustworthy
+			return host
+		}
+		host = hop
+		if !c.trustedProxy(hopAddr.Unmap()) {
+			return host
+		}
+	}
+	return host
+}
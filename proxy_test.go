@@ -0,0 +1,33 @@
+package twitchwh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	c := newClient(ClientConfig{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1", "invalid"}})
+	for _, tt := range []struct {
+		remoteAddr string
+		forwarded  string
+		expected   string
+	}{
+		{"203.0.113.7:1234", "", "203.0.113.7"},
+		// Not from a trusted proxy, the header could be spoofed
+		{"203.0.113.7:1234", "198.51.100.1", "203.0.113.7"},
+		{"10.1.2.3:1234", "198.51.100.1", "198.51.100.1"},
+		{"10.1.2.3:1234", "1.1.1.1, 198.51.100.1, 192.168.1.1", "198.51.100.1"},
+		{"10.1.2.3:1234", "", "10.1.2.3"},
+		{"10.1.2.3:1234", "garbage", "10.1.2.3"},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/eventsub", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		if ip := c.clientIP(r); ip != tt.expected {
+			t.Errorf("Expected %s for %s with X-Forwarded-For %q, got %s", tt.expected, tt.remoteAddr, tt.forwarded, ip)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package twitchwh
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Channel is the channel information of a broadcaster returned by Helix, see HelixAPI.GetChannels.
+type Channel struct {
+	BroadcasterID               string   `json:"broadcaster_id"`
+	BroadcasterLogin            string   `json:"broadcaster_login"`
+	BroadcasterName             string   `json:"broadcaster_name"`
+	BroadcasterLanguage         string   `json:"broadcaster_language"`
+	GameID                      string   `json:"game_id"`
+	GameName                    string   `json:"game_name"`
+	Title                       string   `json:"title"`
+	ContentClassificationLabels []string `json:"content_classification_labels"`
+}
+
+// reconciledPrefix starts the message ID of notifications synthesized by a Reconciler.
+const reconciledPrefix = "twitchwh-reconciled-"
+
+// IsReconciledFromContext reports whether the notification being handled was synthesized by a Reconciler instead of
+// sent by Twitch.
+func IsReconciledFromContext(ctx context.Context) bool {
+	return strings.HasPrefix(MessageIDFromContext(ctx), reconciledPrefix)
+}
+
+// ReconcilerState is the state a Reconciler compares Helix against. Persist it with Reconciler.State before shutting
+// down and pass it to Reconciler.Restore after starting, so changes during a restart are detected.
+type ReconcilerState struct {
+	// Stream ID per broadcaster ID, empty while offline.
+	Streams map[string]string `json:"streams"`
+	// Channel information per broadcaster ID.
+	Channels map[string]Channel `json:"channels"`
+	// Status per subscription ID.
+	Subscriptions map[string]string `json:"subscriptions"`
+}
+
+// Reconciler detects state changes that happened while the webhook endpoint was down, which Twitch only retries
+// briefly, by polling Helix, and synthesizes the notifications that were missed:
+//
+//   - stream.online and stream.offline, from Get Streams
+//   - channel.update, from Get Channel Information
+//   - revocations, from the subscription statuses
+//
+// Only broadcasters and subscriptions with a known previous state are compared, the first Reconcile just records
+// the current state. Synthesized notifications are passed to the handlers and sinks of their type, and the
+// subscription the change belongs to. Handlers can tell them apart with IsReconciledFromContext.
+//
+//	reconciler := twitchwh.NewReconciler(client)
+//	reconciler.Restore(loadState())
+//	go func() {
+//		for range time.Tick(5 * time.Minute) {
+//			reconciler.Reconcile(ctx)
+//		}
+//	}()
+type Reconciler struct {
+	c     *Client
+	mu    sync.Mutex
+	state ReconcilerState
+}
+
+// NewReconciler creates a reconciler for the client. It is added as a sink for stream.online, stream.offline, and
+// channel.update, so notifications keep its state current between calls to Reconcile.
+func NewReconciler(c *Client) *Reconciler {
+	r := &Reconciler{c: c, state: ReconcilerState{
+		Streams:       make(map[string]string),
+		Channels:      make(map[string]Channel),
+		Subscriptions: make(map[string]string),
+	}}
+	c.AddSink(r, TypeFilter(TypeStreamOnline, TypeStreamOffline, TypeChannelUpdate))
+	return r
+}
+
+// State returns a copy of the current state.
+func (r *Reconciler) State() ReconcilerState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ReconcilerState{
+		Streams:       cloneMap(r.state.Streams),
+		Channels:      cloneMap(r.state.Channels),
+		Subscriptions: cloneMap(r.state.Subscriptions),
+	}
+}
+
+// Restore replaces the current state, eg: with one persisted before a restart.
+func (r *Reconciler) Restore(state ReconcilerState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state = ReconcilerState{
+		Streams:       cloneMap(state.Streams),
+		Channels:      cloneMap(state.Channels),
+		Subscriptions: cloneMap(state.Subscriptions),
+	}
+}
+
+func cloneMap[V any](m map[string]V) map[string]V {
+	clone := make(map[string]V, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// Publish implements Sink.
+func (r *Reconciler) Publish(ctx context.Context, n Notification) error {
+	var event struct {
+		ID                          string   `json:"id"`
+		BroadcasterUserID           string   `json:"broadcaster_user_id"`
+		BroadcasterUserLogin        string   `json:"broadcaster_user_login"`
+		BroadcasterUserName         string   `json:"broadcaster_user_name"`
+		Title                       string   `json:"title"`
+		Language                    string   `json:"language"`
+		CategoryID                  string   `json:"category_id"`
+		CategoryName                string   `json:"category_name"`
+		ContentClassificationLabels []string `json:"content_classification_labels"`
+	}
+	if err := json.Unmarshal(n.Event, &event); err != nil {
+		return &InternalError{"Could not parse event", err}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch n.Subscription.Type {
+	case TypeStreamOnline:
+		r.state.Streams[event.BroadcasterUserID] = event.ID
+	case TypeStreamOffline:
+		r.state.Streams[event.BroadcasterUserID] = ""
+	case TypeChannelUpdate:
+		r.state.Channels[event.BroadcasterUserID] = Channel{
+			BroadcasterID:               event.BroadcasterUserID,
+			BroadcasterLogin:            event.BroadcasterUserLogin,
+			BroadcasterName:             event.BroadcasterUserName,
+			BroadcasterLanguage:         event.Language,
+			GameID:                      event.CategoryID,
+			GameName:                    event.CategoryName,
+			Title:                       event.Title,
+			ContentClassificationLabels: event.ContentClassificationLabels,
+		}
+	}
+	return nil
+}
+
+// Reconcile compares Helix against the last known state and synthesizes the notifications for the differences.
+// Its Helix requests have PriorityBackground. Handler errors are reported like regular handler errors.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	ctx = withPriority(ctx, PriorityBackground)
+	subs, err := r.c.fetchSubscriptions(ctx, SubscriptionQuery{})
+	if err != nil {
+		return err
+	}
+	r.reconcileSubscriptions(subs)
+
+	// The subscriptions missed notifications would have been sent for, per type and broadcaster
+	targets := make(map[string]map[string]Subscription)
+	for _, sub := range subs {
+		if sub.Status != "enabled" || sub.Condition.BroadcasterUserID == "" {
+			continue
+		}
+		if targets[sub.Type] == nil {
+			targets[sub.Type] = make(map[string]Subscription)
+		}
+		targets[sub.Type][sub.Condition.BroadcasterUserID] = sub
+	}
+	if err := r.reconcileStreams(ctx, targets[TypeStreamOnline], targets[TypeStreamOffline]); err != nil {
+		return err
+	}
+	return r.reconcileChannels(ctx, targets[TypeChannelUpdate])
+}
+
+// reconcileSubscriptions calls the revocation handlers for subscriptions that were revoked since they were last seen.
+func (r *Reconciler) reconcileSubscriptions(subs []Subscription) {
+	var revocations []Subscription
+	r.mu.Lock()
+	for _, sub := range subs {
+		previous, known := r.state.Subscriptions[sub.ID]
+		if _, handled := r.c.revokedIDs.Load(sub.ID); known && !handled && !revoked(previous) && revoked(sub.Status) {
+			revocations = append(revocations, sub)
+		}
+		r.state.Subscriptions[sub.ID] = sub.Status
+	}
+	r.mu.Unlock()
+
+	for _, sub := range revocations {
+		r.c.logger.Warn("Subscription was revoked while the endpoint was down", "subscription_id", sub.ID, "type", sub.Type, "status", sub.Status)
+		r.c.audit(AuditActionRevoked, sub, sub.Status)
+		r.c.handleRevocation(sub)
+	}
+}
+
+func (r *Reconciler) reconcileStreams(ctx context.Context, online, offline map[string]Subscription) error {
+	var broadcasterIDs []string
+	for id := range online {
+		broadcasterIDs = append(broadcasterIDs, id)
+	}
+	for id := range offline {
+		if _, ok := online[id]; !ok {
+			broadcasterIDs = append(broadcasterIDs, id)
+		}
+	}
+	slices.Sort(broadcasterIDs)
+	for start := 0; start < len(broadcasterIDs); start += maxIDsPerRequest {
+		batch := broadcasterIDs[start:min(start+maxIDsPerRequest, len(broadcasterIDs))]
+		streams, err := r.c.helix.GetStreams(ctx, batch)
+		if err != nil {
+			return err
+		}
+		live := make(map[string]Stream, len(streams))
+		for _, stream := range streams {
+			live[stream.UserID] = stream
+		}
+		for _, id := range batch {
+			stream, isLive := live[id]
+			r.mu.Lock()
+			previous, known := r.state.Streams[id]
+			r.state.Streams[id] = stream.ID
+			r.mu.Unlock()
+			if !known || previous == stream.ID {
+				continue
+			}
+			if previous != "" {
+				// The previous stream ended, possibly followed by a new one
+				if sub, ok := offline[id]; ok {
+					r.synthesize(sub, map[string]any{
+						"broadcaster_user_id":    id,
+						"broadcaster_user_login": stream.UserLogin,
+						"broadcaster_user_name":  stream.UserName,
+					})
+				}
+			}
+			if sub, ok := online[id]; ok && isLive {
+				r.synthesize(sub, map[string]any{
+					"id":                     stream.ID,
+					"broadcaster_user_id":    id,
+					"broadcaster_user_login": stream.UserLogin,
+					"broadcaster_user_name":  stream.UserName,
+					"type":                   stream.Type,
+					"started_at":             stream.StartedAt,
+				})
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) reconcileChannels(ctx context.Context, updates map[string]Subscription) error {
+	var broadcasterIDs []string
+	for id := range updates {
+		broadcasterIDs = append(broadcasterIDs, id)
+	}
+	slices.Sort(broadcasterIDs)
+	for start := 0; start < len(broadcasterIDs); start += maxIDsPerRequest {
+		batch := broadcasterIDs[start:min(start+maxIDsPerRequest, len(broadcasterIDs))]
+		channels, err := r.c.helix.GetChannels(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, channel := range channels {
+			r.mu.Lock()
+			previous, known := r.state.Channels[channel.BroadcasterID]
+			r.state.Channels[channel.BroadcasterID] = channel
+			r.mu.Unlock()
+			if !known || (previous.Title == channel.Title && previous.GameID == channel.GameID &&
+				previous.BroadcasterLanguage == channel.BroadcasterLanguage &&
+				slices.Equal(previous.ContentClassificationLabels, channel.ContentClassificationLabels)) {
+				continue
+			}
+			labels := channel.ContentClassificationLabels
+			if labels == nil {
+				labels = []string{}
+			}
+			r.synthesize(updates[channel.BroadcasterID], map[string]any{
+				"broadcaster_user_id":           channel.BroadcasterID,
+				"broadcaster_user_login":        channel.BroadcasterLogin,
+				"broadcaster_user_name":         channel.BroadcasterName,
+				"title":                         channel.Title,
+				"language":                      channel.BroadcasterLanguage,
+				"category_id":                   channel.GameID,
+				"category_name":                 channel.GameName,
+				"content_classification_labels": labels,
+			})
+		}
+	}
+	return nil
+}
+
+// synthesize passes a notification for a missed event to the handlers and sinks of the subscription's type.
+func (r *Reconciler) synthesize(sub Subscription, event map[string]any) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		r.c.reportError("Could not serialize reconciled event", err, "type", sub.Type)
+		return
+	}
+	random := make([]byte, 8)
+	rand.Read(random)
+	n := Notification{
+		MessageID:    reconciledPrefix + hex.EncodeToString(random),
+		Timestamp:    time.Now(),
+		Subscription: sub,
+		Event:        body,
+	}
+	r.c.logger.Info("Synthesizing missed notification", "type", sub.Type, "subscription_id", sub.ID)
+	if err := r.c.processNotification(n); err != nil {
+		r.c.reportError("Could not handle reconciled notification", err, "type", sub.Type, "subscription_id", sub.ID)
+	}
+}
@@ -0,0 +1,93 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReconciler(t *testing.T) {
+	c := newClient(ClientConfig{})
+	live := false
+	status := "enabled"
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/streams"):
+			if live {
+				return jsonResponse(200, `{"data":[{"id":"9","user_id":"1","user_login":"streamer","type":"live"}]}`), nil
+			}
+			return jsonResponse(200, `{"data":[]}`), nil
+		case strings.HasSuffix(r.URL.Path, "/channels"):
+			title := "Offline"
+			if live {
+				title = "Live now"
+			}
+			return jsonResponse(200, `{"data":[{"broadcaster_id":"1","broadcaster_login":"streamer","title":"`+title+`"}]}`), nil
+		}
+		return jsonResponse(200, `{"data":[`+
+			`{"id":"a","type":"stream.online","status":"enabled","condition":{"broadcaster_user_id":"1"}},`+
+			`{"id":"b","type":"stream.offline","status":"enabled","condition":{"broadcaster_user_id":"1"}},`+
+			`{"id":"c","type":"channel.update","status":"enabled","condition":{"broadcaster_user_id":"1"}},`+
+			`{"id":"d","type":"channel.follow","status":"`+status+`","condition":{"broadcaster_user_id":"1"}}`+
+			`],"pagination":{}}`), nil
+	})}
+
+	var mu sync.Mutex
+	var received []string
+	handler := func(ctx context.Context, event json.RawMessage) error {
+		if !IsReconciledFromContext(ctx) {
+			t.Error("Expected notification to be marked as reconciled")
+		}
+		mu.Lock()
+		received = append(received, EventTypeFromContext(ctx))
+		mu.Unlock()
+		return nil
+	}
+	c.OnContext(TypeStreamOnline, handler)
+	c.OnContext(TypeStreamOffline, handler)
+	c.OnContext(TypeChannelUpdate, handler)
+	var revoked []string
+	c.OnRevocation = func(sub Subscription) {
+		revoked = append(revoked, sub.ID)
+	}
+
+	reconciler := NewReconciler(c)
+	if err := reconciler.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 0 {
+		t.Fatalf("Expected the first reconcile to only record the state, got %v", received)
+	}
+
+	// Changes while the endpoint was down
+	live = true
+	status = string(RevocationAuthorizationRevoked)
+	if err := reconciler.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 || received[0] != TypeStreamOnline || received[1] != TypeChannelUpdate {
+		t.Fatalf("Expected stream.online and channel.update to be synthesized, got %v", received)
+	}
+	if len(revoked) != 1 || revoked[0] != "d" {
+		t.Fatalf("Expected the revocation to be reported, got %v", revoked)
+	}
+
+	// Restored after a restart
+	state := reconciler.State()
+	reconciler = NewReconciler(c)
+	reconciler.Restore(state)
+	live = false
+	received = nil
+	if err := reconciler.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 || received[0] != TypeStreamOffline || received[1] != TypeChannelUpdate {
+		t.Fatalf("Expected stream.offline and channel.update to be synthesized, got %v", received)
+	}
+	if len(revoked) != 1 {
+		t.Fatalf("Expected the revocation to be reported once, got %v", revoked)
+	}
+}
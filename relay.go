@@ -0,0 +1,107 @@
+package twitchwh
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// How long the receiver tries to publish a verification before giving up.
+const relayPublishTimeout = 5 * time.Second
+
+// How long the client waits before subscribing to the relay again after Subscribe failed.
+const relayRetryInterval = 5 * time.Second
+
+// VerificationRelay carries the IDs of verified subscriptions from the service answering challenges to the services
+// creating subscriptions, when they are separate, see ModeReceiver and ModeAPI. Implement it with a broadcast
+// channel every instance receives, eg: Redis pub/sub or an HTTP callback to each manager.
+//
+//	type redisRelay struct{ rdb *redis.Client }
+//
+//	func (r redisRelay) Publish(ctx context.Context, subscriptionID string) error {
+//		return r.rdb.Publish(ctx, "twitchwh:verified", subscriptionID).Err()
+//	}
+//
+//	func (r redisRelay) Subscribe(ctx context.Context, verified func(subscriptionID string)) error {
+//		sub := r.rdb.Subscribe(ctx, "twitchwh:verified")
+//		defer sub.Close()
+//		for msg := range sub.Channel() {
+//			verified(msg.Payload)
+//		}
+//		return ctx.Err()
+//	}
+type VerificationRelay interface {
+	// Publish announces that the challenge of a subscription was answered. Called by clients that receive
+	// notifications.
+	Publish(ctx context.Context, subscriptionID string) error
+	// Subscribe calls verified with every published subscription ID until ctx is done or it fails. Called by
+	// clients that create subscriptions, it is called again if it returns before ctx is done.
+	Subscribe(ctx context.Context, verified func(subscriptionID string)) error
+}
+
+// MemoryVerificationRelay is a VerificationRelay within a single process, eg: for tests of a receiver and a manager
+// client.
+type MemoryVerificationRelay struct {
+	mu          sync.Mutex
+	subscribers map[int]func(string)
+	next        int
+}
+
+// NewMemoryVerificationRelay creates an empty MemoryVerificationRelay.
+func NewMemoryVerificationRelay() *MemoryVerificationRelay {
+	return &MemoryVerificationRelay{subscribers: make(map[int]func(string))}
+}
+
+func (r *MemoryVerificationRelay) Publish(ctx context.Context, subscriptionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, verified := range r.subscribers {
+		verified(subscriptionID)
+	}
+	return nil
+}
+
+func (r *MemoryVerificationRelay) Subscribe(ctx context.Context, verified func(subscriptionID string)) error {
+	r.mu.Lock()
+	id := r.next
+	r.next++
+	r.subscribers[id] = verified
+	r.mu.Unlock()
+
+	<-ctx.Done()
+	r.mu.Lock()
+	delete(r.subscribers, id)
+	r.mu.Unlock()
+	return ctx.Err()
+}
+
+// publishVerification announces a verified subscription to the relay in the background, so answering the challenge
+// isn't delayed.
+func (c *Client) publishVerification(subscriptionID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), relayPublishTimeout)
+		defer cancel()
+		if err := c.verificationRelay.Publish(ctx, subscriptionID); err != nil {
+			c.reportError("Could not publish verification", err, "subscription_id", subscriptionID)
+		}
+	}()
+}
+
+// runVerificationRelay passes the subscriptions verified by other instances to AddSubscription calls waiting for
+// them, until ctx is done.
+func (c *Client) runVerificationRelay(ctx context.Context) {
+	for {
+		err := c.verificationRelay.Subscribe(ctx, c.verifications.signal)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.reportError("Could not receive verifications from relay", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(relayRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package twitchwh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerificationRelay(t *testing.T) {
+	relay := NewMemoryVerificationRelay()
+	receiver := newClient(ClientConfig{Mode: ModeReceiver, WebhookSecret: testWebhookSecret, VerificationRelay: relay})
+	manager := newClient(ClientConfig{Mode: ModeAPI, WebhookSecret: testWebhookSecret, WebhookURL: "https://example.com/eventsub", VerificationRelay: relay})
+	manager.goBackground(manager.runVerificationRelay)
+	defer manager.Close()
+	for subscribed := false; !subscribed; time.Sleep(time.Millisecond) {
+		relay.mu.Lock()
+		subscribed = len(relay.subscribers) == 1
+		relay.mu.Unlock()
+	}
+
+	manager.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		// Twitch sends the challenge to the receiver
+		go func() {
+			w := httptest.NewRecorder()
+			receiver.Handler(w, signedRequest("challenge", messageTypeVerification, `{"challenge":"abc","subscription":{"id":"1"}}`))
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected 200, got %d", w.Code)
+			}
+		}()
+		return jsonResponse(202, `{"data":[{"id":"1","type":"stream.online","version":"1","status":"webhook_callback_verification_pending"}]}`), nil
+	})}
+
+	id, err := manager.AddSubscription("stream.online", "1", Condition{BroadcasterUserID: "1"})
+	if err != nil || id != "1" {
+		t.Fatalf("Expected subscription 1 to be verified through the relay, got %q, %v", id, err)
+	}
+	if answered := manager.verifications.answered.Load(); answered != 0 {
+		t.Fatalf("Expected relayed verifications not to count as answered by the manager, got %d", answered)
+	}
+}
+
+func TestVerificationRelayStops(t *testing.T) {
+	relay := NewMemoryVerificationRelay()
+	c := newClient(ClientConfig{Mode: ModeAPI, WebhookURL: "https://example.com/eventsub", VerificationRelay: relay})
+	c.goBackground(c.runVerificationRelay)
+	for subscribed := false; !subscribed; time.Sleep(time.Millisecond) {
+		relay.mu.Lock()
+		subscribed = len(relay.subscribers) == 1
+		relay.mu.Unlock()
+	}
+
+	c.Close()
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+	if len(relay.subscribers) != 0 {
+		t.Fatalf("Expected Close to unsubscribe from the relay, got %d subscribers", len(relay.subscribers))
+	}
+}
@@ -0,0 +1,59 @@
+package twitchwh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// EventSource yields persisted notifications for Client.Replay, oldest first.
+// See jsonlsink.NewReader for a source reading the files written by the jsonlsink package.
+type EventSource interface {
+	// Next returns the next notification, or io.EOF once there are none left.
+	Next(ctx context.Context) (Notification, error)
+}
+
+// Replay re-dispatches the notifications from source that pass filter (or all of them if filter is nil) to the
+// registered handlers, one at a time and in order. Use it to backfill a new consumer or to test a handler against
+// real traffic. Sinks, deduplication, and the outbox are bypassed. Handlers can tell replays apart with IsReplayFromContext.
+//
+// speed scales the original gaps between notifications, eg: 1 replays in real time and 10 ten times as fast.
+// If speed is zero or negative, notifications are replayed as fast as the handlers allow.
+//
+// Handler errors are reported like regular handler errors and don't stop the replay. Replay returns once the source
+// is exhausted, with the error of the source or ctx if it stopped early.
+func (c *Client) Replay(ctx context.Context, source EventSource, filter Filter, speed float64) error {
+	var previous time.Time
+	for {
+		n, err := source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if filter != nil && !filter(n) {
+			continue
+		}
+		if speed > 0 && !previous.IsZero() && n.Timestamp.After(previous) {
+			timer := time.NewTimer(time.Duration(float64(n.Timestamp.Sub(previous)) / speed))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		previous = n.Timestamp
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		handler, ok := c.notificationHandler(n)
+		if !ok {
+			continue
+		}
+		c.dispatchWithMetadata(n, handler, true)
+	}
+}
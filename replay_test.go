@@ -0,0 +1,45 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+type sliceSource []Notification
+
+func (s *sliceSource) Next(ctx context.Context) (Notification, error) {
+	if len(*s) == 0 {
+		return Notification{}, io.EOF
+	}
+	n := (*s)[0]
+	*s = (*s)[1:]
+	return n, nil
+}
+
+func TestReplay(t *testing.T) {
+	c := newClient(ClientConfig{})
+	var replayed []string
+	c.OnContext("stream.online", func(ctx context.Context, event json.RawMessage) error {
+		if !IsReplayFromContext(ctx) {
+			t.Error("Expected replay metadata")
+		}
+		replayed = append(replayed, MessageIDFromContext(ctx))
+		return nil
+	})
+
+	source := &sliceSource{
+		{MessageID: "a", Subscription: Subscription{Type: "stream.online"}},
+		{MessageID: "b", Subscription: Subscription{Type: "stream.offline"}},
+		{MessageID: "c", Subscription: Subscription{Type: "stream.online"}},
+		{MessageID: "d", Subscription: Subscription{Type: "stream.online"}},
+	}
+	skipD := func(n Notification) bool { return n.MessageID != "d" }
+	if err := c.Replay(context.Background(), source, skipD, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != 2 || replayed[0] != "a" || replayed[1] != "c" {
+		t.Fatalf("Expected [a c], got %v", replayed)
+	}
+}
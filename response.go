@@ -0,0 +1,32 @@
+package twitchwh
+
+import "net/http"
+
+// ResponseWrapper wraps the ResponseWriter of a webhook request, see Client.WrapResponseWriter.
+type ResponseWrapper func(w http.ResponseWriter, r *http.Request) http.ResponseWriter
+
+// WrapResponseWriter registers a wrapper for the ResponseWriter of every request Handler serves, eg: to add tracing
+// or Cache-Control headers, or to record the status code for existing HTTP observability middleware:
+//
+//	client.WrapResponseWriter(func(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+//		w.Header().Set("Cache-Control", "no-store")
+//		return &statusRecorder{ResponseWriter: w, route: "eventsub"}
+//	})
+//
+// Wrappers are applied in the order they were registered, so the last one sees the response first.
+func (c *Client) WrapResponseWriter(wrapper ResponseWrapper) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.responseWrappers = append(c.responseWrappers, wrapper)
+}
+
+// wrapResponseWriter applies the registered wrappers to w.
+func (c *Client) wrapResponseWriter(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+	c.handlersMu.RLock()
+	wrappers := c.responseWrappers
+	c.handlersMu.RUnlock()
+	for _, wrap := range wrappers {
+		w = wrap(w, r)
+	}
+	return w
+}
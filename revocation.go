@@ -0,0 +1,62 @@
+package twitchwh
+
+// RevocationReason is the status of a revoked subscription.
+// See: https://dev.twitch.tv/docs/eventsub/handling-webhook-events/#revoking-your-subscription
+type RevocationReason string
+
+const (
+	// The user in the condition no longer exists.
+	RevocationUserRemoved RevocationReason = "user_removed"
+	// The user revoked the authorization token the subscription relied on.
+	RevocationAuthorizationRevoked RevocationReason = "authorization_revoked"
+	// The callback failed to respond in a timely manner too many times.
+	RevocationNotificationFailuresExceeded RevocationReason = "notification_failures_exceeded"
+	// The subscribed type and version is no longer supported.
+	RevocationVersionRemoved RevocationReason = "version_removed"
+)
+
+// RevocationReason returns the subscription status as a RevocationReason.
+// Only meaningful for subscriptions passed to revocation handlers.
+func (s Subscription) RevocationReason() RevocationReason {
+	return RevocationReason(s.Status)
+}
+
+// OnRevocationReason assigns a handler that is fired whenever a subscription is revoked for a particular reason.
+// It is called in addition to Client.OnRevocation.
+//
+//	client.OnRevocationReason(twitchwh.RevocationNotificationFailuresExceeded, func(sub twitchwh.Subscription) {
+//		// The endpoint was unreachable, recreate the subscription once it is back up
+//	})
+func (c *Client) OnRevocationReason(reason RevocationReason, handler func(Subscription)) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.revocationHandlers[reason] = handler
+}
+
+// handleRevocation forgets a revoked subscription and calls the revocation handlers.
+func (c *Client) handleRevocation(subscription Subscription) {
+	c.revokedIDs.Store(subscription.ID, struct{}{})
+	c.InvalidateSubscriptionCache()
+	if err := c.registry.Forget(subscription.ID); err != nil {
+		c.reportError("Could not remove revoked subscription from registry", err, "subscription_id", subscription.ID)
+	}
+	c.Unwatch(subscription.ID)
+	if c.OnRevocation != nil {
+		c.OnRevocation(subscription)
+	}
+	c.handlersMu.RLock()
+	handler, ok := c.revocationHandlers[subscription.RevocationReason()]
+	c.handlersMu.RUnlock()
+	if ok {
+		handler(subscription)
+	}
+}
+
+// revoked reports whether a subscription status is one of the revocation reasons.
+func revoked(status string) bool {
+	switch RevocationReason(status) {
+	case RevocationUserRemoved, RevocationAuthorizationRevoked, RevocationNotificationFailuresExceeded, RevocationVersionRemoved:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package twitchwh
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func revocationBody(id string, reason RevocationReason) string {
+	return fmt.Sprintf(`{"subscription":{"id":%q,"type":"stream.online","version":"1","status":%q,"cost":0,`+
+		`"condition":{"broadcaster_user_id":"1971641"},"transport":{"method":"webhook","callback":"https://example.com/webhooks/callback"},`+
+		`"created_at":"2023-11-06T18:11:47.492253549Z"}}`, id, reason)
+}
+
+func TestOnRevocationReason(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	var revoked []string
+	c.OnRevocation = func(sub Subscription) {
+		revoked = append(revoked, sub.ID)
+	}
+	called := make(map[RevocationReason][]string)
+	for _, reason := range []RevocationReason{RevocationUserRemoved, RevocationAuthorizationRevoked, RevocationNotificationFailuresExceeded} {
+		c.OnRevocationReason(reason, func(sub Subscription) {
+			called[reason] = append(called[reason], sub.ID)
+		})
+	}
+
+	reasons := []RevocationReason{RevocationUserRemoved, RevocationAuthorizationRevoked, RevocationNotificationFailuresExceeded, RevocationVersionRemoved}
+	for i, reason := range reasons {
+		id := fmt.Sprint(i)
+		w := httptest.NewRecorder()
+		c.Handler(w, signedRequest(id, messageTypeRevocation, revocationBody(id, reason)))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204 for %s, got %d", reason, w.Code)
+		}
+	}
+
+	if len(revoked) != len(reasons) {
+		t.Fatalf("Expected OnRevocation for every revocation, got %v", revoked)
+	}
+	for i, reason := range reasons[:3] {
+		if ids := called[reason]; len(ids) != 1 || ids[0] != fmt.Sprint(i) {
+			t.Errorf("Expected the %s handler to run once for subscription %d, got %v", reason, i, ids)
+		}
+	}
+	if ids := called[RevocationVersionRemoved]; len(ids) != 0 {
+		t.Errorf("Expected no handler for %s, got %v", RevocationVersionRemoved, ids)
+	}
+}
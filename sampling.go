@@ -0,0 +1,37 @@
+package twitchwh
+
+import "hash/fnv"
+
+// SetSampleRate forwards only a fraction (0 to 1) of the notifications of an event type to handlers and sinks.
+// The rest are still acknowledged and deduplicated, but dropped. Use it when pointing production traffic at a staging
+// consumer:
+//
+//	client.SetSampleRate("channel.chat.message", 0.01)
+//
+// Whether a notification is kept depends only on its message ID, so redeliveries and other replicas make the same
+// decision. A rate of 1 or more removes sampling for the type.
+func (c *Client) SetSampleRate(eventType string, rate float64) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	if rate >= 1 {
+		delete(c.sampleRates, eventType)
+		return
+	}
+	if c.sampleRates == nil {
+		c.sampleRates = make(map[string]float64)
+	}
+	c.sampleRates[eventType] = max(rate, 0)
+}
+
+// sampledOut reports whether a notification is dropped by the sample rate of its type.
+func (c *Client) sampledOut(n Notification) bool {
+	c.handlersMu.RLock()
+	rate, ok := c.sampleRates[n.Subscription.Type]
+	c.handlersMu.RUnlock()
+	if !ok {
+		return false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(n.MessageID))
+	return float64(h.Sum64()) >= rate*float64(^uint64(0))
+}
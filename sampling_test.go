@@ -0,0 +1,32 @@
+package twitchwh
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSampleRate(t *testing.T) {
+	c := newClient(ClientConfig{})
+	c.SetSampleRate("channel.chat.message", 0.25)
+
+	kept := 0
+	for i := 0; i < 10000; i++ {
+		n := Notification{MessageID: strconv.Itoa(i), Subscription: Subscription{Type: "channel.chat.message"}}
+		if !c.sampledOut(n) {
+			kept++
+		}
+		if c.sampledOut(n) != c.sampledOut(n) {
+			t.Fatal("Expected the same decision for the same message ID")
+		}
+	}
+	if kept < 2250 || kept > 2750 {
+		t.Fatalf("Expected about 2500 notifications to be kept, got %d", kept)
+	}
+	if c.sampledOut(Notification{MessageID: "a", Subscription: Subscription{Type: "stream.online"}}) {
+		t.Fatal("Expected other types not to be sampled")
+	}
+	c.SetSampleRate("channel.chat.message", 1)
+	if c.sampledOut(Notification{MessageID: "a", Subscription: Subscription{Type: "channel.chat.message"}}) {
+		t.Fatal("Expected sampling to be removed")
+	}
+}
@@ -0,0 +1,295 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSON kinds used in a Schema.
+const (
+	SchemaString = "string"
+	SchemaNumber = "number"
+	SchemaBool   = "bool"
+	SchemaObject = "object"
+	SchemaArray  = "array"
+	// Any value is accepted, and nested fields are not checked.
+	SchemaAny = "any"
+)
+
+// Schema describes the fields of an event payload, mapping each field path to its JSON kind.
+// Nested fields are separated by dots and array elements are marked with [], eg: "badges[].set_id".
+// Fields whose path ends with "?" are optional and not reported as missing, eg: "message.fragments[].emote?".
+type Schema map[string]string
+
+// SchemaOf builds a Schema from the json tags of a struct, eg: an event type from the events package.
+// Pointer fields and fields tagged omitempty are optional.
+func SchemaOf(v any) Schema {
+	schema := make(Schema)
+	addTypeSchema(schema, "", reflect.TypeOf(v))
+	return schema
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func addTypeSchema(schema Schema, prefix string, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			addTypeSchema(schema, prefix, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		path := prefix + name
+		kind := schemaKind(field.Type)
+		if field.Type.Kind() == reflect.Pointer || strings.Contains(opts, "omitempty") {
+			schema[path+"?"] = kind
+		} else {
+			schema[path] = kind
+		}
+		switch kind {
+		case SchemaObject:
+			addTypeSchema(schema, path+".", field.Type)
+		case SchemaArray:
+			element := field.Type
+			for element.Kind() == reflect.Pointer {
+				element = element.Elem()
+			}
+			addTypeSchema(schema, path+"[].", element.Elem())
+		}
+	}
+}
+
+func schemaKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return SchemaString
+	}
+	if t == rawMessageType {
+		return SchemaAny
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return SchemaString
+	case reflect.Bool:
+		return SchemaBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return SchemaNumber
+	case reflect.Slice, reflect.Array:
+		return SchemaArray
+	case reflect.Struct:
+		return SchemaObject
+	}
+	return SchemaAny
+}
+
+// SchemaFromSample builds a Schema from a sample payload, eg: one copied from the Twitch documentation.
+// Fields that are null in the sample accept any value.
+func SchemaFromSample(sample json.RawMessage) (Schema, error) {
+	var value any
+	if err := json.Unmarshal(sample, &value); err != nil {
+		return nil, err
+	}
+	schema := make(Schema)
+	addValueSchema(schema, "", value)
+	return schema, nil
+}
+
+func addValueSchema(schema Schema, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for name, field := range v {
+			path := prefix + name
+			schema[path] = valueKind(field)
+			addValueSchema(schema, path+".", field)
+		}
+	case []any:
+		// Fields of array elements, the prefix already ends with "."
+		for _, element := range v {
+			addValueSchema(schema, strings.TrimSuffix(prefix, ".")+"[].", element)
+		}
+	}
+}
+
+func valueKind(value any) string {
+	switch value.(type) {
+	case string:
+		return SchemaString
+	case float64:
+		return SchemaNumber
+	case bool:
+		return SchemaBool
+	case map[string]any:
+		return SchemaObject
+	case []any:
+		return SchemaArray
+	}
+	return SchemaAny
+}
+
+// SchemaDrift describes how an event payload differs from the registered Schema of its type.
+type SchemaDrift struct {
+	EventType string
+	// Message ID of the notification the drift was first seen in.
+	MessageID string
+	// Fields in the payload that are not in the schema.
+	NewFields []string
+	// Required fields of the schema that are missing from the payload.
+	MissingFields []string
+	// Fields whose JSON kind differs from the schema, mapped to "expected -> actual", eg: "number -> string".
+	TypeChanges map[string]string
+}
+
+// schemas holds the registered schemas and the drifts already reported, so each drift is only reported once.
+type schemas struct {
+	mu       sync.RWMutex
+	byType   map[string]Schema
+	reported map[string]struct{}
+}
+
+// SetSchema registers the expected payload schema of an event type. Notifications of the type are compared against
+// it, and differences are reported once each to OnSchemaDrift, so Twitch payload changes show up from production
+// traffic:
+//
+//	client.SetSchema("channel.cheer", twitchwh.SchemaOf(events.ChannelCheer{}))
+//	client.OnSchemaDrift = func(drift twitchwh.SchemaDrift) {
+//		log.Printf("%s payload changed: %+v", drift.EventType, drift)
+//	}
+func (c *Client) SetSchema(eventType string, schema Schema) {
+	c.schemas.mu.Lock()
+	defer c.schemas.mu.Unlock()
+	if c.schemas.byType == nil {
+		c.schemas.byType = make(map[string]Schema)
+		c.schemas.reported = make(map[string]struct{})
+	}
+	c.schemas.byType[eventType] = schema
+}
+
+// checkSchema compares a notification against the schema of its type and reports new drift.
+func (c *Client) checkSchema(n Notification) {
+	c.schemas.mu.RLock()
+	schema, ok := c.schemas.byType[n.Subscription.Type]
+	c.schemas.mu.RUnlock()
+	if !ok || c.OnSchemaDrift == nil {
+		return
+	}
+	var value any
+	if err := json.Unmarshal(n.Event, &value); err != nil {
+		return
+	}
+	fields := make(Schema)
+	addValueSchema(fields, "", value)
+
+	drift := c.schemas.diff(n, schema, fields)
+	if len(drift.NewFields) > 0 || len(drift.MissingFields) > 0 || len(drift.TypeChanges) > 0 {
+		c.OnSchemaDrift(drift)
+	}
+}
+
+// diff compares the fields of a payload against a schema, leaving out differences that were already reported.
+func (s *schemas) diff(n Notification, schema Schema, fields Schema) SchemaDrift {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := func(kind, path string) bool {
+		key := n.Subscription.Type + "\x00" + kind + "\x00" + path
+		if _, ok := s.reported[key]; ok {
+			return false
+		}
+		s.reported[key] = struct{}{}
+		return true
+	}
+
+	drift := SchemaDrift{EventType: n.Subscription.Type, MessageID: n.MessageID, TypeChanges: make(map[string]string)}
+	for path, actual := range fields {
+		expected, ok := schema[path]
+		if !ok {
+			expected, ok = schema[path+"?"]
+		}
+		if !ok {
+			if !underAny(schema, path) && report("new", path) {
+				drift.NewFields = append(drift.NewFields, path)
+			}
+			continue
+		}
+		if expected != SchemaAny && actual != SchemaAny && expected != actual && report("type:"+actual, path) {
+			drift.TypeChanges[path] = expected + " -> " + actual
+		}
+	}
+	for path := range schema {
+		if strings.HasSuffix(path, "?") || underAny(schema, path) || !parentPresent(fields, path) {
+			continue
+		}
+		if _, ok := fields[path]; !ok && report("missing", path) {
+			drift.MissingFields = append(drift.MissingFields, path)
+		}
+	}
+	sort.Strings(drift.NewFields)
+	sort.Strings(drift.MissingFields)
+	return drift
+}
+
+// underAny reports whether a field is nested in a field the schema accepts any value for.
+func underAny(schema Schema, path string) bool {
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i] != '.' {
+			continue
+		}
+		parent := strings.TrimSuffix(path[:i], "[]")
+		for _, p := range []string{parent, parent + "?"} {
+			if schema[p] == SchemaAny {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parentPresent reports whether the object containing a field is in the payload. Fields of optional objects and
+// array elements are only missing if their parent is present.
+func parentPresent(fields Schema, path string) bool {
+	i := strings.LastIndexByte(path, '.')
+	if i < 0 {
+		return true
+	}
+	parent := strings.TrimSuffix(path[:i], "[]")
+	kind, ok := fields[parent]
+	if !ok {
+		return false
+	}
+	if strings.HasSuffix(path[:i], "[]") {
+		// Only known to be present if an element had fields
+		prefix := path[:i] + "."
+		for field := range fields {
+			if strings.HasPrefix(field, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return kind == SchemaObject
+}
@@ -0,0 +1,63 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testCheer struct {
+	UserID      *string `json:"user_id"`
+	IsAnonymous bool    `json:"is_anonymous"`
+	Bits        int     `json:"bits"`
+	Message     string  `json:"message"`
+	Badges      []struct {
+		SetID string `json:"set_id"`
+	} `json:"badges"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	expected := Schema{
+		"user_id?":        SchemaString,
+		"is_anonymous":    SchemaBool,
+		"bits":            SchemaNumber,
+		"message":         SchemaString,
+		"badges":          SchemaArray,
+		"badges[].set_id": SchemaString,
+	}
+	if schema := SchemaOf(testCheer{}); !reflect.DeepEqual(schema, expected) {
+		t.Fatalf("Unexpected schema %v", schema)
+	}
+}
+
+func TestSchemaDrift(t *testing.T) {
+	c := newClient(ClientConfig{})
+	var drifts []SchemaDrift
+	c.OnSchemaDrift = func(drift SchemaDrift) {
+		drifts = append(drifts, drift)
+	}
+	c.SetSchema("channel.cheer", SchemaOf(testCheer{}))
+
+	event := json.RawMessage(`{"user_id":null,"is_anonymous":true,"bits":"100","badges":[{"set_id":"a","id":"1"}],"power_up":{}}`)
+	n := Notification{MessageID: "a", Subscription: Subscription{Type: "channel.cheer"}, Event: event}
+	c.checkSchema(n)
+	if len(drifts) != 1 {
+		t.Fatalf("Expected one drift report, got %d", len(drifts))
+	}
+	drift := drifts[0]
+	if !reflect.DeepEqual(drift.NewFields, []string{"badges[].id", "power_up"}) {
+		t.Errorf("Unexpected new fields %v", drift.NewFields)
+	}
+	if !reflect.DeepEqual(drift.MissingFields, []string{"message"}) {
+		t.Errorf("Unexpected missing fields %v", drift.MissingFields)
+	}
+	if !reflect.DeepEqual(drift.TypeChanges, map[string]string{"bits": "number -> string"}) {
+		t.Errorf("Unexpected type changes %v", drift.TypeChanges)
+	}
+
+	// Already reported
+	c.checkSchema(n)
+	if len(drifts) != 1 {
+		t.Fatalf("Expected drift to be reported once, got %d reports", len(drifts))
+	}
+}
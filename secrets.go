@@ -0,0 +1,84 @@
+package twitchwh
+
+import (
+	"context"
+	"time"
+)
+
+// How long requests signed with the previous webhook secret are accepted after RefreshSecrets rotated it, if
+// ClientConfig.SecretRotationWindow is not set.
+const defaultSecretRotationWindow = 1 * time.Hour
+
+// SecretProvider supplies the webhook secret and client secret at runtime, eg: from Vault or a cloud secret manager,
+// so they don't have to be kept in plaintext config. See ClientConfig.SecretProvider.
+//
+//	type vaultSecrets struct{ kv *vault.KVv2 }
+//
+//	func (v vaultSecrets) WebhookSecret(ctx context.Context) (string, error) {
+//		secret, err := v.kv.Get(ctx, "twitch")
+//		if err != nil {
+//			return "", err
+//		}
+//		return secret.Data["webhook_secret"].(string), nil
+//	}
+type SecretProvider interface {
+	// WebhookSecret returns the current webhook secret. An empty secret keeps the current one.
+	WebhookSecret(ctx context.Context) (string, error)
+	// ClientSecret returns the current client secret. An empty secret keeps the current one.
+	ClientSecret(ctx context.Context) (string, error)
+}
+
+// RefreshSecrets pulls the secrets from ClientConfig.SecretProvider. A changed webhook secret is rotated in with
+// RotateWebhookSecret, so requests signed with the previous one are accepted for ClientConfig.SecretRotationWindow;
+// call MigrateSubscriptions within it. A changed client secret is used from the next token generation on.
+// Does nothing if no provider is set.
+func (c *Client) RefreshSecrets(ctx context.Context) error {
+	if c.secretProvider == nil {
+		return nil
+	}
+	webhookSecret, err := c.secretProvider.WebhookSecret(ctx)
+	if err != nil {
+		return &InternalError{"Could not get webhook secret", err}
+	}
+	clientSecret, err := c.secretProvider.ClientSecret(ctx)
+	if err != nil {
+		return &InternalError{"Could not get client secret", err}
+	}
+	if current := c.GetWebhookSecret(); webhookSecret != "" && webhookSecret != current {
+		if current == "" {
+			c.SetWebhookSecret(webhookSecret)
+		} else {
+			c.logger.Info("Webhook secret changed, rotating it", "window", c.secretRotationWindow)
+			c.RotateWebhookSecret(webhookSecret, c.secretRotationWindow)
+		}
+	}
+	if clientSecret != "" {
+		c.tokenMu.Lock()
+		c.clientSecret = clientSecret
+		c.tokenMu.Unlock()
+	}
+	return nil
+}
+
+// getClientSecret returns the client secret, which tokenMu guards since RefreshSecrets may replace it.
+func (c *Client) getClientSecret() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.clientSecret
+}
+
+// runSecretRefresh calls RefreshSecrets every ClientConfig.SecretRefreshInterval.
+func (c *Client) runSecretRefresh(ctx context.Context) {
+	ticker := time.NewTicker(c.secretRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := c.RefreshSecrets(ctx); err != nil && ctx.Err() == nil {
+			c.reportError("Could not refresh secrets", err)
+		}
+	}
+}
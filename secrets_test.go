@@ -0,0 +1,55 @@
+package twitchwh
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+type fakeSecrets struct {
+	webhook string
+	client  string
+}
+
+func (f *fakeSecrets) WebhookSecret(ctx context.Context) (string, error) {
+	return f.webhook, nil
+}
+
+func (f *fakeSecrets) ClientSecret(ctx context.Context) (string, error) {
+	return f.client, nil
+}
+
+func TestSecretProvider(t *testing.T) {
+	secrets := &fakeSecrets{webhook: "webhook-1", client: "client-1"}
+	var usedSecrets []string
+	httpClient := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r.ParseForm()
+		usedSecrets = append(usedSecrets, r.PostForm.Get("client_secret"))
+		return jsonResponse(200, `{"access_token":"token"}`), nil
+	})}
+	c, err := NewClient("id", "", WithHTTPClient(httpClient), WithConfig(ClientConfig{SecretProvider: secrets}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.GetWebhookSecret() != "webhook-1" || len(usedSecrets) != 1 || usedSecrets[0] != "client-1" {
+		t.Fatalf("Expected the secrets of the provider, got %q and %v", c.GetWebhookSecret(), usedSecrets)
+	}
+
+	secrets.webhook = "webhook-2"
+	secrets.client = "client-2"
+	if err := c.RefreshSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetWebhookSecret() != "webhook-2" {
+		t.Fatalf("Expected the new webhook secret, got %q", c.GetWebhookSecret())
+	}
+	if previous, ok := c.previousSecret(); !ok || previous != "webhook-1" {
+		t.Fatalf("Expected the previous webhook secret to be accepted during the rotation window, got %q", previous)
+	}
+	if err := c.refreshToken(); err != nil {
+		t.Fatal(err)
+	}
+	if len(usedSecrets) != 2 || usedSecrets[1] != "client-2" {
+		t.Fatalf("Expected the new client secret to be used, got %v", usedSecrets)
+	}
+}
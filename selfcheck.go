@@ -0,0 +1,133 @@
+package twitchwh
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Subscription ID prefix of the challenges sent by CheckEndpoint. Handler answers them without treating them as
+// a verification.
+const selfCheckPrefix = "twitchwh-self-check-"
+
+const statusVerificationFailed = "webhook_callback_verification_failed"
+
+// CheckEndpoint sends a signed verification challenge to the webhook URL, like Twitch does when a subscription is
+// created, and checks that it is answered. It fails if the URL is unreachable, the handler isn't mounted at it, or
+// the instance answering it uses a different webhook secret.
+func (c *Client) CheckEndpoint(ctx context.Context) error {
+	random := make([]byte, 16)
+	rand.Read(random)
+	challenge := hex.EncodeToString(random)
+	var payload webhookPayload
+	payload.Challenge = challenge
+	payload.Subscription.ID = selfCheckPrefix + challenge
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return &InternalError{"Could not serialize request body to JSON", err}
+	}
+
+	messageID := selfCheckPrefix + challenge
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.GetWebhookURL(), bytes.NewReader(body))
+	if err != nil {
+		return &InternalError{"Could not create request", err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(twitchMessageID, messageID)
+	req.Header.Set(twitchMessageTimestamp, timestamp)
+	req.Header.Set(twitchMessageSignature, Signature(c.GetWebhookSecret(), messageID, timestamp, body))
+	req.Header.Set(twitchMessageType, messageTypeVerification)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return &InternalError{"Could not send request", err}
+	}
+	defer res.Body.Close()
+	response, err := io.ReadAll(io.LimitReader(res.Body, maxChallengeLength))
+	if err != nil {
+		return &InternalError{"Could not read response body", err}
+	}
+	if res.StatusCode != 200 {
+		return &UnhandledStatusError{res.StatusCode, response}
+	}
+	if string(response) != challenge {
+		return &InternalError{"Endpoint did not echo the challenge", fmt.Errorf("got %q", response)}
+	}
+	return nil
+}
+
+// RecreateFailedSubscriptions deletes and recreates every subscription with the current webhook URL whose status is
+// webhook_callback_verification_failed, eg: because the endpoint wasn't up yet when they were created.
+// Nothing is recreated unless CheckEndpoint passes, since the new subscriptions would fail the same way. A new
+// subscription is assigned to the tenant of the one it replaces (see Client.Tenant).
+// Returns the IDs of the new subscriptions, and stops at the first error. Its Helix requests have PriorityBackground.
+func (c *Client) RecreateFailedSubscriptions(ctx context.Context) (ids []string, err error) {
+	background := c.Background()
+	subs, err := background.GetSubscriptionsByStatus(statusVerificationFailed)
+	if err != nil {
+		return nil, err
+	}
+	url := c.GetWebhookURL()
+	var failed []Subscription
+	for _, sub := range subs {
+		if sub.Status == statusVerificationFailed && sub.Transport.Callback == url {
+			failed = append(failed, sub)
+		}
+	}
+	if len(failed) == 0 {
+		return nil, nil
+	}
+	if err := c.CheckEndpoint(ctx); err != nil {
+		return nil, err
+	}
+	for _, sub := range failed {
+		c.logger.Info("Recreating subscription that failed verification", "subscription_id", sub.ID, "type", sub.Type)
+		var nfErr *SubscriptionNotFoundError
+		if err := background.RemoveSubscription(sub.ID); err != nil && !errors.As(err, &nfErr) {
+			return ids, err
+		}
+		id, err := background.AddSubscription(sub.Type, sub.Version, sub.Condition)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+		if err := c.reassignTenant(sub.ID, id); err != nil {
+			return ids, err
+		}
+	}
+	return ids, nil
+}
+
+// RetryFailedVerifications calls RecreateFailedSubscriptions every interval until ctx is done. Errors are reported
+// to the ErrorHandler, NotLeaderErrors are ignored. Run it in a goroutine after the handler is being served:
+//
+//	go client.RetryFailedVerifications(ctx, time.Minute)
+func (c *Client) RetryFailedVerifications(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_, err := c.RecreateFailedSubscriptions(ctx)
+		var notLeader *NotLeaderError
+		if err != nil && !errors.As(err, &notLeader) {
+			c.reportError("Could not recreate subscriptions that failed verification", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func isSelfCheck(sub Subscription) bool {
+	return strings.HasPrefix(sub.ID, selfCheckPrefix)
+}
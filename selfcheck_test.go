@@ -0,0 +1,66 @@
+package twitchwh
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecreateFailedSubscriptions(t *testing.T) {
+	c := newClient(ClientConfig{WebhookURL: "https://example.com/eventsub", WebhookSecret: testWebhookSecret})
+	var removed []string
+	endpointUp := false
+	challenges := 0
+	c.OnChallenge = func(sub Subscription, challenge string) {
+		challenges++
+	}
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host == "example.com" {
+			if !endpointUp {
+				return jsonResponse(502, ""), nil
+			}
+			w := httptest.NewRecorder()
+			c.Handler(w, r)
+			return w.Result(), nil
+		}
+		switch r.Method {
+		case http.MethodGet:
+			return jsonResponse(200, `{"data":[`+
+				`{"id":"1","type":"stream.online","version":"1","status":"webhook_callback_verification_failed","transport":{"method":"webhook","callback":"https://example.com/eventsub"}},`+
+				`{"id":"2","type":"stream.online","version":"1","status":"webhook_callback_verification_failed","transport":{"method":"webhook","callback":"https://other.example.com/eventsub"}},`+
+				`{"id":"3","type":"stream.offline","version":"1","status":"enabled","transport":{"method":"webhook","callback":"https://example.com/eventsub"}}`+
+				`],"pagination":{}}`), nil
+		case http.MethodDelete:
+			removed = append(removed, r.URL.Query().Get("id"))
+			return jsonResponse(204, ""), nil
+		case http.MethodPost:
+			c.verifications.verified("4")
+			return jsonResponse(202, `{"data":[{"id":"4","type":"stream.online","version":"1","status":"webhook_callback_verification_pending"}]}`), nil
+		}
+		return jsonResponse(500, ""), nil
+	})}
+
+	if _, err := c.RecreateFailedSubscriptions(context.Background()); err == nil {
+		t.Fatal("Expected an error while the endpoint is down")
+	}
+	if len(removed) != 0 {
+		t.Fatalf("Expected nothing to be removed while the endpoint is down, got %v", removed)
+	}
+
+	c.registry.Assign("1", "tenant")
+	endpointUp = true
+	ids, err := c.RecreateFailedSubscriptions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != "4" || len(removed) != 1 || removed[0] != "1" {
+		t.Fatalf("Expected subscription 1 to be recreated as 4, got %v, removed %v", ids, removed)
+	}
+	if subs, _ := c.Tenant("tenant").Subscriptions(); len(subs) != 1 || subs[0] != "4" {
+		t.Fatalf("Expected the tenant to own the new subscription, got %v", subs)
+	}
+	if challenges != 0 {
+		t.Errorf("Expected the self check not to be treated as a verification, got %d challenges", challenges)
+	}
+}
@@ -0,0 +1,295 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Notification is a verified, non-duplicate EventSub notification.
+type Notification struct {
+	// Value of the Twitch-Eventsub-Message-Id header. Unique per notification, but the same across redeliveries.
+	MessageID string `json:"message_id"`
+	// Value of the Twitch-Eventsub-Message-Timestamp header.
+	Timestamp time.Time `json:"timestamp"`
+	// Subscription the notification belongs to.
+	Subscription Subscription `json:"subscription"`
+	// Raw event body.
+	Event json.RawMessage `json:"event"`
+}
+
+// BroadcasterUserID returns the ID of the user the notification is about, taken from the subscription condition.
+// It prefers broadcaster_user_id, then to_broadcaster_user_id, from_broadcaster_user_id, and user_id.
+// Returns an empty string if the condition has none of them.
+func (n Notification) BroadcasterUserID() string {
+	condition := n.Subscription.Condition
+	for _, id := range []string{condition.BroadcasterUserID, condition.ToBroadcasterUserID, condition.FromBroadcasterUserID, condition.UserID} {
+		if id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// Sink receives every notification that passes its filters, in addition to (or instead of) the handlers
+// registered with On. Use it to forward events to external systems like message brokers.
+//
+// ctx carries the message metadata, see MessageIDFromContext, and is cancelled when the client is closed.
+type Sink interface {
+	Publish(ctx context.Context, n Notification) error
+}
+
+// SinkOption configures a sink added with Client.AddSink.
+// Filter, Transform, RetryPolicy, DeadLetter, SinkQueueSize, and SinkName are sink options.
+type SinkOption interface {
+	applySink(s *sinkEntry)
+}
+
+// Filter selects the notifications published to a sink. A notification is published if all filters return true.
+type Filter func(n Notification) bool
+
+func (f Filter) applySink(s *sinkEntry) {
+	s.filters = append(s.filters, f)
+}
+
+// TypeFilter matches notifications of any of the given event types.
+func TypeFilter(types ...string) Filter {
+	return func(n Notification) bool {
+		return slices.Contains(types, n.Subscription.Type)
+	}
+}
+
+// RetryPolicy controls how failed Publish calls, or handlers with ResponsePolicy.Retry, are retried.
+// The zero value does not retry.
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one.
+	MaxAttempts int
+	// Delay before the first retry, doubled after every attempt. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// Upper bound for the delay between attempts. Defaults to 10s.
+	MaxBackoff time.Duration
+}
+
+func (p RetryPolicy) applySink(s *sinkEntry) {
+	s.retry = p
+}
+
+// backoff returns the delay before the given retry, starting at 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = 10 * time.Second
+	}
+	delay := initial
+	for i := 1; i < retry && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}
+
+// DeadLetter is called with notifications a sink could not publish after all retries, or that were dropped because
+// the sink's queue was full (the error is then a *SinkQueueFullError). It replaces the default, which reports the error.
+type DeadLetter func(n Notification, err error)
+
+func (d DeadLetter) applySink(s *sinkEntry) {
+	s.deadLetter = d
+}
+
+// SinkQueueSize is the number of notifications buffered for a sink. Notifications are dead-lettered when the queue is
+// full, so a slow or broken sink can't block the others. Defaults to 1000.
+type SinkQueueSize int
+
+func (q SinkQueueSize) applySink(s *sinkEntry) {
+	s.queueSize = int(q)
+}
+
+const defaultSinkQueueSize = 1000
+
+// SinkName identifies a sink in the HandledEventsChecker. If the checker implements SinkDeliveryChecker, named sinks
+// receive every notification at most once. Names must be unique per client and stable across restarts.
+type SinkName string
+
+func (n SinkName) applySink(s *sinkEntry) {
+	s.name = string(n)
+}
+
+type sinkEntry struct {
+	name       string
+	sink       Sink
+	filters    []Filter
+	transforms []Transform
+	retry      RetryPolicy
+	deadLetter DeadLetter
+	queueSize  int
+	queue      chan Notification
+	// Notifications queued or being published
+	pending atomic.Int64
+}
+
+func (s *sinkEntry) matches(n Notification) bool {
+	for _, filter := range s.filters {
+		if !filter(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// sinks is the list of sinks added with Client.AddSink.
+type sinks struct {
+	mu      sync.RWMutex
+	entries []*sinkEntry
+}
+
+// AddSink forwards every notification that passes the given filters to sink.
+//
+//	client.AddSink(mySink, twitchwh.TypeFilter("channel.follow"), twitchwh.RetryPolicy{MaxAttempts: 3})
+//
+// Filters and transforms form a pipeline per sink, eg: to forward chat messages of a single broadcaster without user names:
+//
+//	client.AddSink(mySink,
+//		twitchwh.TypeFilter("channel.chat.message"),
+//		twitchwh.BroadcasterFilter("215185844"),
+//		twitchwh.RemoveFields("chatter_user_name", "chatter_user_login"),
+//	)
+//
+// Every sink has its own queue and worker, so sinks are published to concurrently, retry independently,
+// and a failing sink doesn't delay the others. Notifications are published to a sink in the order they were received.
+func (c *Client) AddSink(sink Sink, options ...SinkOption) {
+	entry := &sinkEntry{sink: sink, queueSize: defaultSinkQueueSize}
+	for _, option := range options {
+		option.applySink(entry)
+	}
+	entry.queue = make(chan Notification, entry.queueSize)
+	c.goBackground(func(ctx context.Context) {
+		c.runSink(ctx, entry)
+	})
+
+	c.sinks.mu.Lock()
+	defer c.sinks.mu.Unlock()
+	c.sinks.entries = append(c.sinks.entries, entry)
+}
+
+func (s *sinks) len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+// publish queues a notification for all matching sinks.
+func (c *Client) publish(n Notification) {
+	c.sinks.mu.RLock()
+	entries := c.sinks.entries
+	c.sinks.mu.RUnlock()
+	for _, entry := range entries {
+		if !entry.matches(n) {
+			continue
+		}
+		entry.pending.Add(1)
+		select {
+		case entry.queue <- n:
+		default:
+			entry.pending.Add(-1)
+			c.deadLetter(entry, n, &SinkQueueFullError{})
+		}
+	}
+}
+
+// runSink publishes queued notifications to a sink until ctx is done.
+func (c *Client) runSink(ctx context.Context, entry *sinkEntry) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-entry.queue:
+			if err := c.publishToSink(entry, n); err != nil {
+				c.deadLetter(entry, n, err)
+			}
+			entry.pending.Add(-1)
+		}
+	}
+}
+
+func (c *Client) deadLetter(entry *sinkEntry, n Notification, err error) {
+	if entry.deadLetter != nil {
+		entry.deadLetter(n, err)
+		return
+	}
+	c.reportError("Could not publish notification to sink", err, "type", n.Subscription.Type, "message_id", n.MessageID)
+}
+
+// deliver publishes a notification to all matching sinks concurrently, bypassing their queues,
+// and returns the errors of sinks that failed after retries.
+func (c *Client) deliver(entries []*sinkEntry, n Notification) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(entries))
+	for i, entry := range entries {
+		if !entry.matches(n) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.publishToSink(entry, n)
+			if errs[i] != nil {
+				c.reportError("Could not publish notification to sink", errs[i], "type", n.Subscription.Type, "message_id", n.MessageID)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// publishToSink transforms and publishes a notification to a single sink, with retries.
+func (c *Client) publishToSink(entry *sinkEntry, n Notification) error {
+	ctx := withMessageMetadata(c.background.ctx, messageMetadata{
+		messageID:      n.MessageID,
+		subscriptionID: n.Subscription.ID,
+		eventType:      n.Subscription.Type,
+	})
+	checker, exactlyOnce := c.handledEventsChecker.(SinkDeliveryChecker)
+	exactlyOnce = exactlyOnce && entry.name != ""
+	if exactlyOnce && checker.IsDelivered(n.MessageID, entry.name) {
+		c.logger.Debug("Skipping notification already delivered to sink", "sink", entry.name, "message_id", n.MessageID)
+		return nil
+	}
+	messageID := n.MessageID
+	n, err := entry.transform(n)
+	if err != nil {
+		return err
+	}
+	if err := c.publishWithRetry(ctx, entry, n); err != nil {
+		return err
+	}
+	if exactlyOnce {
+		checker.MarkDelivered(messageID, entry.name)
+	}
+	return nil
+}
+
+func (c *Client) publishWithRetry(ctx context.Context, entry *sinkEntry, n Notification) error {
+	attempts := max(entry.retry.MaxAttempts, 1)
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			// Given up once the client is closed, the notification is dead-lettered with the last error
+			if waitErr := sleepContext(ctx, entry.retry.backoff(attempt-1)); waitErr != nil {
+				return errors.Join(err, waitErr)
+			}
+		}
+		err = entry.sink.Publish(ctx, n)
+		if err == nil {
+			return nil
+		}
+		c.logger.Debug("Sink publish failed", "type", n.Subscription.Type, "attempt", attempt, "error", err)
+	}
+	return err
+}
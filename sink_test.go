@@ -0,0 +1,130 @@
+package twitchwh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type funcSink func(ctx context.Context, n Notification) error
+
+func (f funcSink) Publish(ctx context.Context, n Notification) error {
+	return f(ctx, n)
+}
+
+func TestBlockedSinkDoesNotBlockOthers(t *testing.T) {
+	c := newClient(ClientConfig{})
+	blocked := make(chan struct{})
+	defer close(blocked)
+	received := make(chan string, 3)
+	deadLettered := make(chan string, 3)
+
+	c.AddSink(funcSink(func(ctx context.Context, n Notification) error {
+		<-blocked
+		return nil
+	}), SinkQueueSize(1), DeadLetter(func(n Notification, err error) {
+		deadLettered <- n.MessageID
+	}))
+	c.AddSink(funcSink(func(ctx context.Context, n Notification) error {
+		received <- n.MessageID
+		return nil
+	}), TypeFilter("stream.online"))
+
+	for _, id := range []string{"a", "b", "c"} {
+		c.publish(Notification{MessageID: id, Subscription: Subscription{Type: "stream.online"}})
+	}
+
+	for _, id := range []string{"a", "b", "c"} {
+		select {
+		case got := <-received:
+			if got != id {
+				t.Fatalf("Expected %s, got %s", id, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Healthy sink did not receive %s", id)
+		}
+	}
+	select {
+	case <-deadLettered:
+	case <-time.After(time.Second):
+		t.Fatal("Expected blocked sink to dead-letter a notification")
+	}
+}
+
+func TestNamedSinkDeliveredOnce(t *testing.T) {
+	c := newClient(ClientConfig{})
+	var published []string
+	failing := true
+	sink := funcSink(func(ctx context.Context, n Notification) error {
+		published = append(published, n.MessageID)
+		return nil
+	})
+	failingSink := funcSink(func(ctx context.Context, n Notification) error {
+		if failing {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	entries := []*sinkEntry{{name: "sink", sink: sink}, {name: "failing", sink: failingSink}}
+
+	n := Notification{MessageID: "a"}
+	if err := c.deliver(entries, n); err == nil {
+		t.Fatal("Expected an error from the failing sink")
+	}
+	failing = false
+	if err := c.deliver(entries, n); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(published) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d", len(published))
+	}
+}
+
+func TestSinkContextCancelledOnClose(t *testing.T) {
+	c := newClient(ClientConfig{})
+	publishing := make(chan struct{})
+	c.AddSink(funcSink(func(ctx context.Context, n Notification) error {
+		close(publishing)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	c.publish(Notification{MessageID: "a", Subscription: Subscription{Type: "stream.online"}})
+	<-publishing
+
+	closed := make(chan struct{})
+	go func() {
+		c.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to cancel the context of a running Publish")
+	}
+}
+
+func TestSinkRetryStopsOnClose(t *testing.T) {
+	c := newClient(ClientConfig{})
+	failure := errors.New("broker down")
+	failed := make(chan struct{}, 1)
+	deadLettered := make(chan error, 1)
+	c.AddSink(funcSink(func(ctx context.Context, n Notification) error {
+		failed <- struct{}{}
+		return failure
+	}), RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour}, DeadLetter(func(n Notification, err error) {
+		deadLettered <- err
+	}))
+	c.publish(Notification{MessageID: "a", Subscription: Subscription{Type: "stream.online"}})
+	<-failed
+
+	c.Close()
+	select {
+	case err := <-deadLettered:
+		if !errors.Is(err, failure) || !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected the publish error and context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to stop waiting for the next attempt")
+	}
+}
@@ -0,0 +1,102 @@
+// Package awssink provides a twitchwh.Sink that forwards notifications to an SQS queue or SNS topic,
+// with message attributes for the event type and broadcaster.
+//
+// The package does not depend on the AWS SDK. Adapt your SQS or SNS client with a SenderFunc:
+//
+//	sqsClient := sqs.NewFromConfig(cfg)
+//	sink := awssink.New(awssink.SenderFunc(func(ctx context.Context, m awssink.Message) error {
+//		attributes := map[string]sqstypes.MessageAttributeValue{}
+//		for k, v := range m.Attributes {
+//			attributes[k] = sqstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+//		}
+//		_, err := sqsClient.SendMessage(ctx, &sqs.SendMessageInput{
+//			QueueUrl:          aws.String(queueURL),
+//			MessageBody:       aws.String(m.Body),
+//			MessageAttributes: attributes,
+//			// Only for FIFO queues
+//			MessageGroupId:         aws.String(m.GroupID),
+//			MessageDeduplicationId: aws.String(m.DeduplicationID),
+//		})
+//		return err
+//	}))
+//	client.AddSink(sink, twitchwh.RetryPolicy{MaxAttempts: 3})
+//
+// For SNS, call snsClient.Publish with the same fields (TopicArn instead of QueueUrl).
+package awssink
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Names of the message attributes set on every message.
+const (
+	AttributeType          = "twitch_type"
+	AttributeBroadcasterID = "twitch_broadcaster_user_id"
+	AttributeMessageID     = "twitch_message_id"
+)
+
+// Message is an SQS/SNS message built from a notification.
+type Message struct {
+	// JSON encoded twitchwh.Notification.
+	Body string
+	// Event type, broadcaster ID, and message ID. Attributes with empty values are omitted.
+	Attributes map[string]string
+	// Broadcaster ID (or subscription ID if the condition has none), for FIFO queues and topics.
+	// Keeps per-channel ordering.
+	GroupID string
+	// Twitch message ID, for FIFO queues and topics. Deduplicates Twitch redeliveries.
+	DeduplicationID string
+}
+
+// Sender sends a message to SQS or SNS.
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// SenderFunc adapts a function to a Sender.
+type SenderFunc func(ctx context.Context, m Message) error
+
+func (f SenderFunc) Send(ctx context.Context, m Message) error {
+	return f(ctx, m)
+}
+
+// Sink forwards notifications to SQS or SNS.
+type Sink struct {
+	sender Sender
+}
+
+// New creates a new SQS/SNS sink.
+func New(sender Sender) *Sink {
+	return &Sink{sender: sender}
+}
+
+// Publish implements twitchwh.Sink.
+func (s *Sink) Publish(ctx context.Context, n twitchwh.Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	broadcasterID := n.BroadcasterUserID()
+	groupID := broadcasterID
+	if groupID == "" {
+		groupID = n.Subscription.ID
+	}
+
+	attributes := map[string]string{
+		AttributeType:      n.Subscription.Type,
+		AttributeMessageID: n.MessageID,
+	}
+	if broadcasterID != "" {
+		attributes[AttributeBroadcasterID] = broadcasterID
+	}
+
+	return s.sender.Send(ctx, Message{
+		Body:            string(body),
+		Attributes:      attributes,
+		GroupID:         groupID,
+		DeduplicationID: n.MessageID,
+	})
+}
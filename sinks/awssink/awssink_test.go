@@ -0,0 +1,57 @@
+package awssink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestSend(t *testing.T) {
+	var messages []Message
+	sink := New(SenderFunc(func(ctx context.Context, m Message) error {
+		messages = append(messages, m)
+		return nil
+	}))
+
+	ctx := context.Background()
+	sink.Publish(ctx, twitchwh.Notification{MessageID: "a", Subscription: twitchwh.Subscription{ID: "sub",
+		Type: twitchwh.TypeChannelFollow, Condition: twitchwh.Condition{BroadcasterUserID: "1"}}})
+	sink.Publish(ctx, twitchwh.Notification{MessageID: "b", Subscription: twitchwh.Subscription{ID: "sub",
+		Type: twitchwh.TypeUserAuthorizationRevoke}})
+
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(messages))
+	}
+	follow := messages[0]
+	if follow.GroupID != "1" || follow.DeduplicationID != "a" {
+		t.Errorf("Expected the broadcaster as group and the message ID as deduplication ID, got %q and %q", follow.GroupID, follow.DeduplicationID)
+	}
+	if follow.Attributes[AttributeType] != twitchwh.TypeChannelFollow || follow.Attributes[AttributeBroadcasterID] != "1" || follow.Attributes[AttributeMessageID] != "a" {
+		t.Errorf("Unexpected attributes %v", follow.Attributes)
+	}
+	var decoded twitchwh.Notification
+	if err := json.Unmarshal([]byte(follow.Body), &decoded); err != nil || decoded.MessageID != "a" {
+		t.Errorf("Expected the notification as body, got %s", follow.Body)
+	}
+
+	revoke := messages[1]
+	if revoke.GroupID != "sub" {
+		t.Errorf("Expected the subscription as group without a broadcaster, got %q", revoke.GroupID)
+	}
+	if _, ok := revoke.Attributes[AttributeBroadcasterID]; ok {
+		t.Errorf("Expected no broadcaster attribute, got %v", revoke.Attributes)
+	}
+}
+
+func TestSendError(t *testing.T) {
+	errSend := errors.New("throttled")
+	sink := New(SenderFunc(func(ctx context.Context, m Message) error {
+		return errSend
+	}))
+	if err := sink.Publish(context.Background(), twitchwh.Notification{}); !errors.Is(err, errSend) {
+		t.Fatalf("Expected the sender error, got %v", err)
+	}
+}
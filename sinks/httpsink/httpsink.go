@@ -0,0 +1,95 @@
+// Package httpsink provides a twitchwh.Sink that forwards notifications to a downstream HTTP endpoint,
+// re-signed with a per-destination secret using the same HMAC scheme as Twitch. This lets TwitchWH act as a
+// verified fan-out proxy in front of existing EventSub consumers, which keep verifying requests as if they came from Twitch.
+//
+// Add a sink per destination, so a failing destination is retried independently of the others:
+//
+//	client.AddSink(httpsink.New(httpsink.Destination{
+//		URL:    "http://legacy-service/eventsub",
+//		Secret: "legacy service secret",
+//	}), twitchwh.RetryPolicy{MaxAttempts: 3})
+//	client.AddSink(httpsink.New(httpsink.Destination{
+//		URL:    "http://analytics/eventsub",
+//		Secret: "analytics secret",
+//	}))
+package httpsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Destination is a downstream EventSub consumer.
+type Destination struct {
+	// Callback URL of the consumer.
+	URL string
+	// Secret the consumer verifies signatures with.
+	Secret string
+	// HTTP client used for requests. Defaults to a client with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// Sink forwards notifications to a destination.
+type Sink struct {
+	destination Destination
+}
+
+// New creates a new HTTP forwarding sink.
+func New(destination Destination) *Sink {
+	if destination.HTTPClient == nil {
+		destination.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sink{destination: destination}
+}
+
+// StatusError is returned when the destination responds with a non-2xx status code.
+type StatusError struct {
+	URL    string
+	Status int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s responded with %d", e.URL, e.Status)
+}
+
+// Publish implements twitchwh.Sink.
+func (s *Sink) Publish(ctx context.Context, n twitchwh.Notification) error {
+	body, err := json.Marshal(struct {
+		Subscription twitchwh.Subscription `json:"subscription"`
+		Event        json.RawMessage       `json:"event"`
+	}{n.Subscription, n.Event})
+	if err != nil {
+		return err
+	}
+
+	timestamp := n.Timestamp.UTC().Format(time.RFC3339Nano)
+	req, err := http.NewRequestWithContext(ctx, "POST", s.destination.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Twitch-Eventsub-Message-Id", n.MessageID)
+	req.Header.Set("Twitch-Eventsub-Message-Timestamp", timestamp)
+	req.Header.Set("Twitch-Eventsub-Message-Signature", twitchwh.Signature(s.destination.Secret, n.MessageID, timestamp, body))
+	req.Header.Set("Twitch-Eventsub-Message-Type", "notification")
+	req.Header.Set("Twitch-Eventsub-Subscription-Type", n.Subscription.Type)
+	req.Header.Set("Twitch-Eventsub-Subscription-Version", n.Subscription.Version)
+
+	res, err := s.destination.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return &StatusError{URL: s.destination.URL, Status: res.StatusCode}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package httpsink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+	"github.com/macluxHD/twitchwh/twitchwhtest"
+)
+
+func TestForwardedSignature(t *testing.T) {
+	// The downstream consumer is another client, which verifies the signature against its own secret
+	downstream, err := twitchwh.NewReceiver("downstream secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan json.RawMessage, 1)
+	downstream.On(twitchwh.TypeChannelCheer, func(event json.RawMessage) {
+		received <- event
+	})
+	server := httptest.NewServer(http.HandlerFunc(downstream.Handler))
+	defer server.Close()
+
+	n := twitchwh.Notification{
+		MessageID:    "a",
+		Timestamp:    time.Now(),
+		Subscription: twitchwh.Subscription{ID: "1", Type: twitchwh.TypeChannelCheer, Version: "1"},
+		Event:        twitchwhtest.Sample(twitchwh.TypeChannelCheer),
+	}
+	if err := New(Destination{URL: server.URL, Secret: "downstream secret"}).Publish(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-received:
+		var cheer struct {
+			Bits int `json:"bits"`
+		}
+		json.Unmarshal(event, &cheer)
+		if cheer.Bits != 1000 {
+			t.Fatalf("Unexpected event %s", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Downstream handler was not called")
+	}
+
+	err = New(Destination{URL: server.URL, Secret: "wrong secret"}).Publish(context.Background(), n)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Status != http.StatusForbidden {
+		t.Fatalf("Expected the downstream to reject a signature with the wrong secret, got %v", err)
+	}
+}
+
+func TestRetryOnServerError(t *testing.T) {
+	var attempts atomic.Int32
+	delivered := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		delivered <- r.Header.Get("Twitch-Eventsub-Message-Id")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := twitchwh.NewReceiver("0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.AddSink(New(Destination{URL: server.URL, Secret: "downstream secret"}),
+		twitchwh.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	status, err := twitchwhtest.Notify(client, twitchwh.Subscription{ID: "1", Type: twitchwh.TypeChannelCheer},
+		twitchwhtest.Sample(twitchwh.TypeChannelCheer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", status)
+	}
+	select {
+	case id := <-delivered:
+		if id == "" || attempts.Load() != 3 {
+			t.Fatalf("Expected delivery on the third attempt, got %d attempts", attempts.Load())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the notification to be delivered after retries, got %d attempts", attempts.Load())
+	}
+}
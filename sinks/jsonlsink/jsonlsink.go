@@ -0,0 +1,143 @@
+// Package jsonlsink provides a twitchwh.Sink that appends each notification as a JSON line to a file,
+// rotating it by size. It gives small deployments a zero-dependency way to retain and grep raw event history.
+//
+//	sink, err := jsonlsink.Open(jsonlsink.Options{Path: "/var/log/myapp/events.jsonl"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer sink.Close()
+//	client.AddSink(sink)
+//
+// Every line is a Line: the notification and the time it was written.
+// Rotated files are renamed to include the rotation time, eg: events-20240607T120000.000000000.jsonl.
+package jsonlsink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Line is a single line in the file.
+type Line struct {
+	ReceivedAt   time.Time             `json:"received_at"`
+	Notification twitchwh.Notification `json:"notification"`
+}
+
+// Options configures a Sink.
+type Options struct {
+	// Path of the current file.
+	Path string
+	// Rotate the file once it is larger than this many bytes. Defaults to 100 MiB.
+	MaxSize int64
+	// Number of rotated files to keep, the oldest are deleted. Keeps all files if zero.
+	MaxBackups int
+}
+
+// Sink appends notifications to a file.
+type Sink struct {
+	options Options
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+}
+
+// Open opens (or creates) the file at options.Path for appending.
+func Open(options Options) (*Sink, error) {
+	if options.MaxSize <= 0 {
+		options.MaxSize = 100 << 20
+	}
+	s := &Sink{options: options}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Sink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.options.Path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.options.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// Publish implements twitchwh.Sink.
+func (s *Sink) Publish(ctx context.Context, n twitchwh.Notification) error {
+	data, err := json.Marshal(Line{ReceivedAt: time.Now().UTC(), Notification: n})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size > 0 && s.size+int64(len(data)) > s.options.MaxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	written, err := s.file.Write(data)
+	s.size += int64(written)
+	return err
+}
+
+// rotate renames the current file and opens a new one.
+func (s *Sink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	ext := filepath.Ext(s.options.Path)
+	base := strings.TrimSuffix(s.options.Path, ext)
+	rotated := base + "-" + time.Now().UTC().Format("20060102T150405.000000000") + ext
+	if err := os.Rename(s.options.Path, rotated); err != nil {
+		return err
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	return s.removeOldBackups(base, ext)
+}
+
+func (s *Sink) removeOldBackups(base string, ext string) error {
+	if s.options.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(base + "-*" + ext)
+	if err != nil {
+		return err
+	}
+	// The timestamp format sorts chronologically
+	slices.Sort(backups)
+	for len(backups) > s.options.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Close closes the file.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
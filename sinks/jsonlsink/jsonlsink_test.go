@@ -0,0 +1,72 @@
+package jsonlsink
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := Open(Options{Path: filepath.Join(dir, "events.jsonl"), MaxSize: 1, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := sink.Publish(context.Background(), twitchwh.Notification{MessageID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, _ := filepath.Glob(filepath.Join(dir, "events-*.jsonl"))
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 backups, got %v", backups)
+	}
+}
+
+func TestReadRotatedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink, err := Open(Options{Path: path, MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	ids := []string{"a", "b", "c"}
+	for _, id := range ids {
+		if err := sink.Publish(context.Background(), twitchwh.Notification{MessageID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := Files(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var readers []io.Reader
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+	reader := NewReader(io.MultiReader(readers...))
+	for _, id := range ids {
+		n, err := reader.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.MessageID != id {
+			t.Fatalf("Expected %s, got %s", id, n.MessageID)
+		}
+	}
+	if _, err := reader.Next(context.Background()); err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package jsonlsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Reader reads the lines written by a Sink. It implements twitchwh.EventSource, so a file can be replayed into
+// the handlers of a client:
+//
+//	files, err := jsonlsink.Files("/var/log/myapp/events.jsonl")
+//	...
+//	readers := make([]io.Reader, len(files))
+//	for i, file := range files {
+//		readers[i], err = os.Open(file)
+//		...
+//	}
+//	err = client.Replay(ctx, jsonlsink.NewReader(io.MultiReader(readers...)), nil, 0)
+type Reader struct {
+	scanner *bufio.Scanner
+}
+
+// NewReader returns a Reader reading lines from r.
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	return &Reader{scanner: scanner}
+}
+
+// ReadLine returns the next line, or io.EOF at the end of the input. Empty lines are skipped.
+func (r *Reader) ReadLine() (Line, error) {
+	for r.scanner.Scan() {
+		data := r.scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var line Line
+		err := json.Unmarshal(data, &line)
+		return line, err
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Line{}, err
+	}
+	return Line{}, io.EOF
+}
+
+// Next implements twitchwh.EventSource.
+func (r *Reader) Next(ctx context.Context) (twitchwh.Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return twitchwh.Notification{}, err
+	}
+	line, err := r.ReadLine()
+	return line.Notification, err
+}
+
+// Files returns the rotated files of the file at path, oldest first, followed by path itself if it exists.
+func Files(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	files, err := filepath.Glob(base + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+	// The timestamp format sorts chronologically
+	slices.Sort(files)
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return files, nil
+}
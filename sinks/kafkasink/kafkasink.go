@@ -0,0 +1,120 @@
+// Package kafkasink provides a twitchwh.Sink that produces notifications to Kafka, keyed by broadcaster ID so events
+// of a single channel keep their order within a partition.
+//
+// The package does not depend on a Kafka client. Adapt your producer with a ProducerFunc, eg: for franz-go:
+//
+//	kc, _ := kgo.NewClient(kgo.SeedBrokers("localhost:9092"), kgo.RequiredAcks(kgo.AllISRAcks()))
+//	sink := kafkasink.New(kafkasink.ProducerFunc(func(ctx context.Context, r kafkasink.Record) error {
+//		record := &kgo.Record{Topic: r.Topic, Key: r.Key, Value: r.Value}
+//		for k, v := range r.Headers {
+//			record.Headers = append(record.Headers, kgo.RecordHeader{Key: k, Value: []byte(v)})
+//		}
+//		return kc.ProduceSync(ctx, record).FirstErr()
+//	}), kafkasink.Options{Topic: "twitch-events"})
+//	client.AddSink(sink, twitchwh.RetryPolicy{MaxAttempts: 5})
+package kafkasink
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Record is a Kafka record built from a notification.
+type Record struct {
+	Topic string
+	// Broadcaster ID, see twitchwh.Notification.BroadcasterUserID. Falls back to the subscription ID.
+	Key []byte
+	// JSON encoded twitchwh.Notification.
+	Value []byte
+	// Twitch-Eventsub-Message-Id and Twitch-Eventsub-Subscription-Type headers.
+	Headers map[string]string
+}
+
+// Producer sends a record to Kafka.
+type Producer interface {
+	Produce(ctx context.Context, r Record) error
+}
+
+// ProducerFunc adapts a function to a Producer.
+type ProducerFunc func(ctx context.Context, r Record) error
+
+func (f ProducerFunc) Produce(ctx context.Context, r Record) error {
+	return f(ctx, r)
+}
+
+// Guarantee is the delivery guarantee of the sink.
+type Guarantee int
+
+const (
+	// Publish waits for the producer and returns its error, so failed records are retried according to the
+	// sink's twitchwh.RetryPolicy. Use a producer that waits for acknowledgement from all in-sync replicas.
+	AtLeastOnce Guarantee = iota
+	// Publish returns immediately and the record is produced in the background. Errors are passed to Options.OnError.
+	AtMostOnce
+)
+
+// Options configures a Sink.
+type Options struct {
+	// Default topic. Defaults to "twitch-events".
+	Topic string
+	// Topic per event type, eg: {"channel.chat.message": "twitch-chat"}. Types not in the map use Topic.
+	Topics map[string]string
+	// Delivery guarantee. Defaults to AtLeastOnce.
+	Guarantee Guarantee
+	// Called with errors of AtMostOnce deliveries.
+	OnError func(n twitchwh.Notification, err error)
+}
+
+// Sink produces notifications to Kafka.
+type Sink struct {
+	producer Producer
+	options  Options
+}
+
+// New creates a new Kafka sink.
+func New(producer Producer, options Options) *Sink {
+	if options.Topic == "" {
+		options.Topic = "twitch-events"
+	}
+	return &Sink{producer: producer, options: options}
+}
+
+func (s *Sink) topic(eventType string) string {
+	if topic, ok := s.options.Topics[eventType]; ok {
+		return topic
+	}
+	return s.options.Topic
+}
+
+// Publish implements twitchwh.Sink.
+func (s *Sink) Publish(ctx context.Context, n twitchwh.Notification) error {
+	value, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	key := n.BroadcasterUserID()
+	if key == "" {
+		key = n.Subscription.ID
+	}
+	record := Record{
+		Topic: s.topic(n.Subscription.Type),
+		Key:   []byte(key),
+		Value: value,
+		Headers: map[string]string{
+			"Twitch-Eventsub-Message-Id":        n.MessageID,
+			"Twitch-Eventsub-Subscription-Type": n.Subscription.Type,
+		},
+	}
+
+	if s.options.Guarantee == AtMostOnce {
+		go func() {
+			if err := s.producer.Produce(context.WithoutCancel(ctx), record); err != nil && s.options.OnError != nil {
+				s.options.OnError(n, err)
+			}
+		}()
+		return nil
+	}
+	return s.producer.Produce(ctx, record)
+}
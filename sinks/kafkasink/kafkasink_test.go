@@ -0,0 +1,67 @@
+package kafkasink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestProduce(t *testing.T) {
+	var records []Record
+	sink := New(ProducerFunc(func(ctx context.Context, r Record) error {
+		records = append(records, r)
+		return nil
+	}), Options{Topics: map[string]string{twitchwh.TypeChannelChatMessage: "twitch-chat"}})
+
+	ctx := context.Background()
+	sink.Publish(ctx, twitchwh.Notification{MessageID: "a", Subscription: twitchwh.Subscription{ID: "sub",
+		Type: twitchwh.TypeChannelChatMessage, Condition: twitchwh.Condition{BroadcasterUserID: "1", UserID: "2"}}})
+	sink.Publish(ctx, twitchwh.Notification{MessageID: "b", Subscription: twitchwh.Subscription{ID: "sub",
+		Type: twitchwh.TypeChannelRaid, Condition: twitchwh.Condition{ToBroadcasterUserID: "3"}}})
+	sink.Publish(ctx, twitchwh.Notification{MessageID: "c", Subscription: twitchwh.Subscription{ID: "sub",
+		Type: twitchwh.TypeUserAuthorizationRevoke}})
+
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	for i, want := range []struct{ topic, key string }{{"twitch-chat", "1"}, {"twitch-events", "3"}, {"twitch-events", "sub"}} {
+		if records[i].Topic != want.topic || string(records[i].Key) != want.key {
+			t.Errorf("Expected record %d to have topic %q and key %q, got %q and %q", i, want.topic, want.key, records[i].Topic, records[i].Key)
+		}
+	}
+	if records[0].Headers["Twitch-Eventsub-Message-Id"] != "a" || records[0].Headers["Twitch-Eventsub-Subscription-Type"] != twitchwh.TypeChannelChatMessage {
+		t.Errorf("Expected the message ID and type headers, got %v", records[0].Headers)
+	}
+}
+
+func TestProduceError(t *testing.T) {
+	errProduce := errors.New("not enough replicas")
+	producer := ProducerFunc(func(ctx context.Context, r Record) error {
+		return errProduce
+	})
+	n := twitchwh.Notification{MessageID: "a"}
+
+	if err := New(producer, Options{}).Publish(context.Background(), n); !errors.Is(err, errProduce) {
+		t.Fatalf("Expected the producer error, got %v", err)
+	}
+
+	// AtMostOnce reports errors to OnError instead
+	failed := make(chan error, 1)
+	sink := New(producer, Options{Guarantee: AtMostOnce, OnError: func(n twitchwh.Notification, err error) {
+		failed <- err
+	}})
+	if err := sink.Publish(context.Background(), n); err != nil {
+		t.Fatalf("Expected AtMostOnce to return immediately, got %v", err)
+	}
+	select {
+	case err := <-failed:
+		if !errors.Is(err, errProduce) {
+			t.Fatalf("Expected the producer error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnError was not called")
+	}
+}
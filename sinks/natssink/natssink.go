@@ -0,0 +1,95 @@
+// Package natssink provides a twitchwh.Sink that publishes notifications to NATS subjects derived from the event type,
+// eg: "twitch.channel.follow".
+//
+// The package does not depend on a NATS client. Adapt your connection with a PublisherFunc:
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	sink := natssink.New(natssink.PublisherFunc(func(ctx context.Context, m natssink.Message) error {
+//		return nc.PublishMsg(&nats.Msg{Subject: m.Subject, Data: m.Data, Header: nats.Header(m.Header)})
+//	}), natssink.Options{})
+//	client.AddSink(sink)
+//
+// For JetStream persistence, publish through a JetStream context instead.
+// The Nats-Msg-Id header is set to the Twitch message ID, so JetStream deduplicates Twitch redeliveries:
+//
+//	js, _ := jetstream.New(nc)
+//	sink := natssink.New(natssink.PublisherFunc(func(ctx context.Context, m natssink.Message) error {
+//		_, err := js.PublishMsg(ctx, &nats.Msg{Subject: m.Subject, Data: m.Data, Header: nats.Header(m.Header)})
+//		return err
+//	}), natssink.Options{})
+package natssink
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Message is a NATS message built from a notification.
+type Message struct {
+	Subject string
+	// JSON encoded twitchwh.Notification.
+	Data []byte
+	// Nats-Msg-Id, Twitch-Eventsub-Message-Id, and Twitch-Eventsub-Subscription-Type headers.
+	// Compatible with nats.Header.
+	Header map[string][]string
+}
+
+// Publisher sends a message to NATS.
+type Publisher interface {
+	Publish(ctx context.Context, m Message) error
+}
+
+// PublisherFunc adapts a function to a Publisher.
+type PublisherFunc func(ctx context.Context, m Message) error
+
+func (f PublisherFunc) Publish(ctx context.Context, m Message) error {
+	return f(ctx, m)
+}
+
+// Options configures a Sink.
+type Options struct {
+	// Prefix prepended to the event type to build the subject. Defaults to "twitch".
+	SubjectPrefix string
+	// Overrides the subject for a notification. SubjectPrefix is ignored if set.
+	Subject func(n twitchwh.Notification) string
+}
+
+// Sink publishes notifications to NATS.
+type Sink struct {
+	publisher Publisher
+	options   Options
+}
+
+// New creates a new NATS sink.
+func New(publisher Publisher, options Options) *Sink {
+	if options.SubjectPrefix == "" {
+		options.SubjectPrefix = "twitch"
+	}
+	return &Sink{publisher: publisher, options: options}
+}
+
+func (s *Sink) subject(n twitchwh.Notification) string {
+	if s.options.Subject != nil {
+		return s.options.Subject(n)
+	}
+	return s.options.SubjectPrefix + "." + n.Subscription.Type
+}
+
+// Publish implements twitchwh.Sink.
+func (s *Sink) Publish(ctx context.Context, n twitchwh.Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return s.publisher.Publish(ctx, Message{
+		Subject: s.subject(n),
+		Data:    data,
+		Header: map[string][]string{
+			"Nats-Msg-Id":                       {n.MessageID},
+			"Twitch-Eventsub-Message-Id":        {n.MessageID},
+			"Twitch-Eventsub-Subscription-Type": {n.Subscription.Type},
+		},
+	})
+}
@@ -0,0 +1,62 @@
+package natssink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestPublish(t *testing.T) {
+	var got Message
+	sink := New(PublisherFunc(func(ctx context.Context, m Message) error {
+		got = m
+		return nil
+	}), Options{})
+	n := twitchwh.Notification{MessageID: "a", Subscription: twitchwh.Subscription{Type: twitchwh.TypeChannelFollow}}
+	if err := sink.Publish(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Subject != "twitch.channel.follow" {
+		t.Errorf("Expected the subject to be the prefixed event type, got %q", got.Subject)
+	}
+	if got.Header["Nats-Msg-Id"][0] != "a" || got.Header["Twitch-Eventsub-Subscription-Type"][0] != twitchwh.TypeChannelFollow {
+		t.Errorf("Expected the message ID and type headers, got %v", got.Header)
+	}
+	var decoded twitchwh.Notification
+	if err := json.Unmarshal(got.Data, &decoded); err != nil || decoded.MessageID != "a" {
+		t.Errorf("Expected the notification as data, got %s", got.Data)
+	}
+}
+
+func TestSubject(t *testing.T) {
+	var subjects []string
+	publisher := PublisherFunc(func(ctx context.Context, m Message) error {
+		subjects = append(subjects, m.Subject)
+		return nil
+	})
+	n := twitchwh.Notification{Subscription: twitchwh.Subscription{Type: twitchwh.TypeChannelFollow,
+		Condition: twitchwh.Condition{BroadcasterUserID: "1"}}}
+
+	New(publisher, Options{SubjectPrefix: "events"}).Publish(context.Background(), n)
+	New(publisher, Options{SubjectPrefix: "events", Subject: func(n twitchwh.Notification) string {
+		return "channel." + n.Subscription.Condition.BroadcasterUserID
+	}}).Publish(context.Background(), n)
+
+	if len(subjects) != 2 || subjects[0] != "events.channel.follow" || subjects[1] != "channel.1" {
+		t.Fatalf("Unexpected subjects %v", subjects)
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	errPublish := errors.New("no responders")
+	sink := New(PublisherFunc(func(ctx context.Context, m Message) error {
+		return errPublish
+	}), Options{})
+	if err := sink.Publish(context.Background(), twitchwh.Notification{}); !errors.Is(err, errPublish) {
+		t.Fatalf("Expected the publisher error, got %v", err)
+	}
+}
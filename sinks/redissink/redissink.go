@@ -0,0 +1,99 @@
+// Package redissink provides a twitchwh.Sink that appends notifications to a Redis Stream with XADD,
+// trimming it to a maximum length. This gives small deployments a durable, replayable event history
+// without running a full message broker.
+//
+// The package does not depend on a Redis client. Adapt your client with an AdderFunc, eg: for go-redis:
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	sink := redissink.New(redissink.AdderFunc(func(ctx context.Context, e redissink.Entry) error {
+//		return rdb.XAdd(ctx, &redis.XAddArgs{
+//			Stream: e.Stream,
+//			MaxLen: e.MaxLen,
+//			Approx: e.Approx,
+//			Values: e.Values,
+//		}).Err()
+//	}), redissink.Options{MaxLen: 100000})
+//	client.AddSink(sink)
+package redissink
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Entry is a stream entry built from a notification.
+type Entry struct {
+	Stream string
+	// Maximum length of the stream, 0 disables trimming.
+	MaxLen int64
+	// Trim with "MAXLEN ~", which is faster but may keep slightly more entries than MaxLen.
+	Approx bool
+	// Fields of the entry: message_id, type, broadcaster_user_id, and notification (the JSON encoded twitchwh.Notification).
+	Values map[string]any
+}
+
+// Adder runs XADD.
+type Adder interface {
+	XAdd(ctx context.Context, e Entry) error
+}
+
+// AdderFunc adapts a function to an Adder.
+type AdderFunc func(ctx context.Context, e Entry) error
+
+func (f AdderFunc) XAdd(ctx context.Context, e Entry) error {
+	return f(ctx, e)
+}
+
+// Options configures a Sink.
+type Options struct {
+	// Stream key. Defaults to "twitch:events".
+	Stream string
+	// Overrides the stream key for a notification, eg: to use a stream per event type.
+	StreamFor func(n twitchwh.Notification) string
+	// Maximum length of the stream. Defaults to 10000, set to a negative value to disable trimming.
+	MaxLen int64
+	// Trim to exactly MaxLen instead of approximately.
+	ExactTrim bool
+}
+
+// Sink appends notifications to a Redis Stream.
+type Sink struct {
+	adder   Adder
+	options Options
+}
+
+// New creates a new Redis Streams sink.
+func New(adder Adder, options Options) *Sink {
+	if options.Stream == "" {
+		options.Stream = "twitch:events"
+	}
+	if options.MaxLen == 0 {
+		options.MaxLen = 10000
+	}
+	return &Sink{adder: adder, options: options}
+}
+
+// Publish implements twitchwh.Sink.
+func (s *Sink) Publish(ctx context.Context, n twitchwh.Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	stream := s.options.Stream
+	if s.options.StreamFor != nil {
+		stream = s.options.StreamFor(n)
+	}
+	return s.adder.XAdd(ctx, Entry{
+		Stream: stream,
+		MaxLen: max(s.options.MaxLen, 0),
+		Approx: !s.options.ExactTrim,
+		Values: map[string]any{
+			"message_id":          n.MessageID,
+			"type":                n.Subscription.Type,
+			"broadcaster_user_id": n.BroadcasterUserID(),
+			"notification":        string(data),
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package redissink
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestXAdd(t *testing.T) {
+	var entries []Entry
+	adder := AdderFunc(func(ctx context.Context, e Entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	n := twitchwh.Notification{MessageID: "a", Subscription: twitchwh.Subscription{Type: twitchwh.TypeChannelFollow,
+		Condition: twitchwh.Condition{BroadcasterUserID: "1"}}}
+
+	ctx := context.Background()
+	New(adder, Options{}).Publish(ctx, n)
+	New(adder, Options{MaxLen: -1, ExactTrim: true, StreamFor: func(n twitchwh.Notification) string {
+		return "twitch:" + n.Subscription.Type
+	}}).Publish(ctx, n)
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Stream != "twitch:events" || e.MaxLen != 10000 || !e.Approx {
+		t.Errorf("Expected the default stream trimmed approximately to 10000 entries, got %+v", e)
+	}
+	if e := entries[1]; e.Stream != "twitch:channel.follow" || e.MaxLen != 0 || e.Approx {
+		t.Errorf("Expected the overridden stream without trimming, got %+v", e)
+	}
+	values := entries[0].Values
+	if values["message_id"] != "a" || values["type"] != twitchwh.TypeChannelFollow || values["broadcaster_user_id"] != "1" || values["notification"] == "" {
+		t.Errorf("Unexpected values %v", values)
+	}
+}
+
+func TestXAddError(t *testing.T) {
+	errAdd := errors.New("OOM command not allowed")
+	sink := New(AdderFunc(func(ctx context.Context, e Entry) error {
+		return errAdd
+	}), Options{})
+	if err := sink.Publish(context.Background(), twitchwh.Notification{}); !errors.Is(err, errAdd) {
+		t.Fatalf("Expected the XADD error, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+// Package ssesink provides a Server-Sent Events bridge that streams notifications to connected clients,
+// making it easy to build live overlays and dashboards directly off the webhook receiver.
+//
+// Broker implements twitchwh.Sink and http.Handler:
+//
+//	broker := ssesink.New(ssesink.Options{})
+//	client.AddSink(broker)
+//	http.Handle("/events", broker)
+//
+// Clients filter by event type and broadcaster with query parameters, which can be repeated:
+//
+//	const source = new EventSource("/events?type=channel.follow&type=channel.cheer&broadcaster_user_id=215185844");
+//	source.addEventListener("channel.follow", (e) => console.log(JSON.parse(e.data).event));
+//
+// Every SSE event is named after the event type, has the Twitch message ID as its ID, and the JSON encoded
+// twitchwh.Notification as data.
+package ssesink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Options configures a Broker.
+type Options struct {
+	// Number of events buffered per client. Events are dropped for clients whose buffer is full,
+	// so a slow client can't block the others. Defaults to 64.
+	BufferSize int
+	// Interval of keep-alive comments, which stop proxies from closing idle connections. Defaults to 15 seconds.
+	KeepAlive time.Duration
+}
+
+type client struct {
+	types          []string
+	broadcasterIDs []string
+	events         chan []byte
+}
+
+func (c *client) matches(n twitchwh.Notification) bool {
+	if len(c.types) > 0 && !slices.Contains(c.types, n.Subscription.Type) {
+		return false
+	}
+	if len(c.broadcasterIDs) > 0 && !slices.Contains(c.broadcasterIDs, n.BroadcasterUserID()) {
+		return false
+	}
+	return true
+}
+
+// Broker streams notifications to SSE clients.
+type Broker struct {
+	options Options
+	mu      sync.RWMutex
+	clients map[*client]struct{}
+}
+
+// New creates a new SSE broker.
+func New(options Options) *Broker {
+	if options.BufferSize <= 0 {
+		options.BufferSize = 64
+	}
+	if options.KeepAlive <= 0 {
+		options.KeepAlive = 15 * time.Second
+	}
+	return &Broker{
+		options: options,
+		clients: make(map[*client]struct{}),
+	}
+}
+
+// Clients returns the number of connected clients.
+func (b *Broker) Clients() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.clients)
+}
+
+// Publish implements twitchwh.Sink. It never blocks on slow clients.
+func (b *Broker) Publish(ctx context.Context, n twitchwh.Notification) error {
+	var event []byte
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for c := range b.clients {
+		if !c.matches(n) {
+			continue
+		}
+		if event == nil {
+			data, err := json.Marshal(n)
+			if err != nil {
+				return err
+			}
+			event = []byte(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", n.MessageID, n.Subscription.Type, data))
+		}
+		select {
+		case c.events <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// ServeHTTP streams events to the client until it disconnects.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	c := &client{
+		types:          query["type"],
+		broadcasterIDs: query["broadcaster_user_id"],
+		events:         make(chan []byte, b.options.BufferSize),
+	}
+	b.mu.Lock()
+	b.clients[c] = struct{}{}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.clients, c)
+		b.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(b.options.KeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-c.events:
+			if _, err := w.Write(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ssesink
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestStream(t *testing.T) {
+	broker := New(Options{})
+	server := httptest.NewServer(broker)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?type=channel.follow&type=channel.cheer&broadcaster_user_id=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" || broker.Clients() != 1 {
+		t.Fatalf("Expected an event stream, got %q with %d clients", resp.Header.Get("Content-Type"), broker.Clients())
+	}
+
+	ctx := context.Background()
+	notification := func(id string, Type string, broadcasterID string) twitchwh.Notification {
+		return twitchwh.Notification{MessageID: id, Subscription: twitchwh.Subscription{Type: Type,
+			Condition: twitchwh.Condition{BroadcasterUserID: broadcasterID}}}
+	}
+	broker.Publish(ctx, notification("a", twitchwh.TypeChannelRaid, "1"))
+	broker.Publish(ctx, notification("b", twitchwh.TypeChannelFollow, "2"))
+	broker.Publish(ctx, notification("c", twitchwh.TypeChannelFollow, "1"))
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	var event []string
+	for len(event) < 3 {
+		select {
+		case line := <-lines:
+			if line != "" {
+				event = append(event, line)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected an event, got %q", event)
+		}
+	}
+	if event[0] != "id: c" || event[1] != "event: channel.follow" || !strings.HasPrefix(event[2], "data: {") {
+		t.Fatalf("Expected only the matching event, got %q", event)
+	}
+}
+
+func TestPublishDoesNotBlock(t *testing.T) {
+	broker := New(Options{BufferSize: 1})
+	// A client that never reads its events
+	broker.clients[&client{events: make(chan []byte, 1)}] = struct{}{}
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			broker.Publish(context.Background(), twitchwh.Notification{MessageID: "a"})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a client with a full buffer")
+	}
+}
+
+func TestStreamingNotSupported(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	// Only exposes the http.ResponseWriter methods of the recorder
+	w := struct{ http.ResponseWriter }{recorder}
+	New(Options{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500 for writers that can't flush, got %d", recorder.Code)
+	}
+}
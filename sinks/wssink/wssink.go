@@ -0,0 +1,162 @@
+// Package wssink provides a WebSocket hub that pushes notifications to connected browser clients,
+// for stream overlays and moderator dashboards.
+//
+// Hub implements twitchwh.Sink and http.Handler:
+//
+//	hub := wssink.New(wssink.Options{
+//		Authorize: func(r *http.Request) bool {
+//			return r.URL.Query().Get("token") == overlayToken
+//		},
+//	})
+//	client.AddSink(hub)
+//	http.Handle("/ws", hub)
+//
+// Clients filter by event type and broadcaster with query parameters, which can be repeated:
+//
+//	const ws = new WebSocket("wss://mydomain.com/ws?token=...&type=channel.follow&type=channel.raid");
+//	ws.onmessage = (e) => console.log(JSON.parse(e.data));
+//
+// Every message is a JSON encoded twitchwh.Notification. Messages sent by clients are ignored.
+package wssink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Options configures a Hub.
+type Options struct {
+	// Called before upgrading a connection. Connections are rejected with 403 if it returns false.
+	// All connections are accepted if nil.
+	Authorize func(r *http.Request) bool
+	// Number of messages buffered per client. Messages are dropped for clients whose buffer is full,
+	// so a slow client can't block the others. Defaults to 64.
+	BufferSize int
+}
+
+type client struct {
+	types          []string
+	broadcasterIDs []string
+	messages       chan []byte
+}
+
+func (c *client) matches(n twitchwh.Notification) bool {
+	if len(c.types) > 0 && !slices.Contains(c.types, n.Subscription.Type) {
+		return false
+	}
+	if len(c.broadcasterIDs) > 0 && !slices.Contains(c.broadcasterIDs, n.BroadcasterUserID()) {
+		return false
+	}
+	return true
+}
+
+// Hub broadcasts notifications to WebSocket clients.
+type Hub struct {
+	options Options
+	mu      sync.RWMutex
+	clients map[*client]struct{}
+}
+
+// New creates a new WebSocket hub.
+func New(options Options) *Hub {
+	if options.BufferSize <= 0 {
+		options.BufferSize = 64
+	}
+	return &Hub{
+		options: options,
+		clients: make(map[*client]struct{}),
+	}
+}
+
+// Clients returns the number of connected clients.
+func (h *Hub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// Publish implements twitchwh.Sink. It never blocks on slow clients.
+func (h *Hub) Publish(ctx context.Context, n twitchwh.Notification) error {
+	var message []byte
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients {
+		if !c.matches(n) {
+			continue
+		}
+		if message == nil {
+			var err error
+			message, err = json.Marshal(n)
+			if err != nil {
+				return err
+			}
+		}
+		select {
+		case c.messages <- message:
+		default:
+		}
+	}
+	return nil
+}
+
+// ServeHTTP upgrades the connection and pushes messages until the client disconnects.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.options.Authorize != nil && !h.options.Authorize(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	ws, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.close()
+
+	query := r.URL.Query()
+	c := &client{
+		types:          query["type"],
+		broadcasterIDs: query["broadcaster_user_id"],
+		messages:       make(chan []byte, h.options.BufferSize),
+	}
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+	}()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			opcode, payload, err := ws.readFrame()
+			if err != nil {
+				return
+			}
+			switch opcode {
+			case opPing:
+				ws.writeFrame(opPong, payload)
+			case opClose:
+				ws.writeFrame(opClose, payload)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case message := <-c.messages:
+			if err := ws.writeFrame(opText, message); err != nil {
+				return
+			}
+		}
+	}
+}
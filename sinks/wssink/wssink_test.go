@@ -0,0 +1,71 @@
+package wssink
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestHubPushesMatchingNotifications(t *testing.T) {
+	hub := New(Options{})
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	netConn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer netConn.Close()
+	netConn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	request := "GET /?type=stream.online HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"
+	if _, err := netConn.Write([]byte(request)); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(netConn)
+	res, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected 101, got %d", res.StatusCode)
+	}
+	// Example from RFC 6455 section 1.3
+	if accept := res.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Unexpected Sec-WebSocket-Accept %q", accept)
+	}
+
+	for hub.Clients() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	hub.Publish(context.Background(), twitchwh.Notification{MessageID: "ignored", Subscription: twitchwh.Subscription{Type: "stream.offline"}})
+	hub.Publish(context.Background(), twitchwh.Notification{MessageID: "abc", Subscription: twitchwh.Subscription{Type: "stream.online"}})
+
+	var head [2]byte
+	if _, err := io.ReadFull(reader, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	if head[0] != 0x80|opText {
+		t.Fatalf("Expected final text frame, got %x", head[0])
+	}
+	payload := make([]byte, head[1])
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(payload), `"message_id":"abc"`) {
+		t.Fatalf("Unexpected message %s", payload)
+	}
+}
@@ -0,0 +1,177 @@
+// Package sqloutbox provides a twitchwh.Outbox backed by a SQLite or Postgres table, using database/sql.
+//
+// Every notification is inserted before it is acknowledged to Twitch, and claimed by the client's dispatcher with a
+// lease, so each row is delivered by a single dispatcher at a time, even with multiple replicas sharing a Postgres database.
+//
+//	db, _ := sql.Open("sqlite", "events.db")
+//	outbox := sqloutbox.New(db, sqloutbox.SQLite)
+//	if err := outbox.CreateTable(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+//	client, _ := twitchwh.New(twitchwh.ClientConfig{
+//		// ...
+//		Outbox: outbox,
+//	})
+//
+// The SQL driver is up to you. SQLite 3.35 or newer is required for RETURNING.
+package sqloutbox
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Dialect contains the SQL differences between databases.
+type Dialect struct {
+	// Returns the placeholder for the nth (1-based) argument.
+	placeholder func(n int) string
+	idColumn    string
+	// Appended to the claim subquery to lock the selected rows.
+	lockClause string
+}
+
+var (
+	// SQLite 3.35 or newer.
+	SQLite = Dialect{
+		placeholder: func(int) string { return "?" },
+		idColumn:    "INTEGER PRIMARY KEY AUTOINCREMENT",
+	}
+	// Postgres 9.5 or newer.
+	Postgres = Dialect{
+		placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+		idColumn:    "BIGSERIAL PRIMARY KEY",
+		lockClause:  " FOR UPDATE SKIP LOCKED",
+	}
+)
+
+// DefaultTable is the table name used if Outbox.Table is empty.
+const DefaultTable = "twitchwh_outbox"
+
+// Outbox stores notifications in a SQL table.
+type Outbox struct {
+	db      *sql.DB
+	dialect Dialect
+	// Name of the table, defaults to DefaultTable.
+	Table string
+}
+
+// New creates a new SQL outbox. Call CreateTable to create the table if it doesn't exist.
+func New(db *sql.DB, dialect Dialect) *Outbox {
+	return &Outbox{db: db, dialect: dialect, Table: DefaultTable}
+}
+
+// query replaces every "?" in q with the dialect's placeholders, and "{table}" with the table name.
+func (o *Outbox) query(q string) string {
+	q = strings.ReplaceAll(q, "{table}", o.Table)
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString(o.dialect.placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// CreateTable creates the outbox table and its index if they don't exist.
+// Times are stored as unix milliseconds. available_at is NULL for entries that were given up on.
+func (o *Outbox) CreateTable(ctx context.Context) error {
+	_, err := o.db.ExecContext(ctx, o.query(`CREATE TABLE IF NOT EXISTS {table} (
+		id `+o.dialect.idColumn+`,
+		message_id TEXT NOT NULL UNIQUE,
+		notification TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		available_at BIGINT,
+		delivered_at BIGINT,
+		last_error TEXT
+	)`))
+	if err != nil {
+		return err
+	}
+	_, err = o.db.ExecContext(ctx, o.query(`CREATE INDEX IF NOT EXISTS {table}_pending ON {table} (available_at) WHERE delivered_at IS NULL`))
+	return err
+}
+
+// Store implements twitchwh.Outbox. Notifications with an existing message ID are ignored.
+func (o *Outbox) Store(ctx context.Context, n twitchwh.Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	_, err = o.db.ExecContext(ctx,
+		o.query(`INSERT INTO {table} (message_id, notification, available_at) VALUES (?, ?, ?) ON CONFLICT (message_id) DO NOTHING`),
+		n.MessageID, string(data), time.Now().UnixMilli())
+	return err
+}
+
+// Claim implements twitchwh.Outbox.
+func (o *Outbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]twitchwh.OutboxEntry, error) {
+	now := time.Now()
+	rows, err := o.db.QueryContext(ctx, o.query(`UPDATE {table} SET available_at = ?, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM {table} WHERE delivered_at IS NULL AND available_at <= ? ORDER BY id LIMIT ?`+o.dialect.lockClause+`
+		)
+		RETURNING id, attempts, notification`),
+		now.Add(lease).UnixMilli(), now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []twitchwh.OutboxEntry
+	for rows.Next() {
+		var entry twitchwh.OutboxEntry
+		var data string
+		if err := rows.Scan(&entry.ID, &entry.Attempts, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &entry.Notification); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING does not guarantee the order of the subquery
+	slices.SortFunc(entries, func(a, b twitchwh.OutboxEntry) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return entries, nil
+}
+
+// Complete implements twitchwh.Outbox.
+func (o *Outbox) Complete(ctx context.Context, id int64) error {
+	_, err := o.db.ExecContext(ctx, o.query(`UPDATE {table} SET delivered_at = ?, last_error = NULL WHERE id = ?`), time.Now().UnixMilli(), id)
+	return err
+}
+
+// Fail implements twitchwh.Outbox.
+func (o *Outbox) Fail(ctx context.Context, id int64, cause error, retryAt time.Time) error {
+	var availableAt sql.NullInt64
+	if !retryAt.IsZero() {
+		availableAt = sql.NullInt64{Int64: retryAt.UnixMilli(), Valid: true}
+	}
+	_, err := o.db.ExecContext(ctx, o.query(`UPDATE {table} SET available_at = ?, last_error = ? WHERE id = ?`), availableAt, cause.Error(), id)
+	return err
+}
+
+// Purge deletes entries delivered before the given time, and returns the number of deleted entries.
+func (o *Outbox) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res, err := o.db.ExecContext(ctx, o.query(`DELETE FROM {table} WHERE delivered_at < ?`), before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,142 @@
+package sqloutbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// fakeDriver records the statements it's sent and answers queries with rows.
+type fakeDriver struct {
+	mu    sync.Mutex
+	execs []fakeStatement
+	rows  [][]driver.Value
+}
+
+type fakeStatement struct {
+	query string
+	args  []driver.NamedValue
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.execs = append(c.d.execs, fakeStatement{query, args})
+	return driver.RowsAffected(3), nil
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.execs = append(c.d.execs, fakeStatement{query, args})
+	return &fakeRows{rows: c.d.rows}, nil
+}
+
+type fakeRows struct{ rows [][]driver.Value }
+
+func (r *fakeRows) Columns() []string { return []string{"id", "attempts", "notification"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func newFakeOutbox(t *testing.T, dialect Dialect) (*Outbox, *fakeDriver) {
+	d := &fakeDriver{}
+	db := sql.OpenDB(connector{d})
+	t.Cleanup(func() { db.Close() })
+	return New(db, dialect), d
+}
+
+type connector struct{ d *fakeDriver }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return fakeConn{c.d}, nil }
+func (c connector) Driver() driver.Driver                        { return c.d }
+
+func TestPostgresPlaceholders(t *testing.T) {
+	outbox, d := newFakeOutbox(t, Postgres)
+	outbox.Table = "events"
+	if err := outbox.Store(context.Background(), twitchwh.Notification{MessageID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	query := d.execs[0].query
+	if !strings.Contains(query, "INSERT INTO events ") || !strings.Contains(query, "VALUES ($1, $2, $3)") {
+		t.Errorf("Expected the table name and numbered placeholders, got %q", query)
+	}
+	if args := d.execs[0].args; len(args) != 3 || args[0].Value != "a" {
+		t.Errorf("Expected the message ID as first argument, got %v", args)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	outbox, d := newFakeOutbox(t, Postgres)
+	notification := func(id string) driver.Value {
+		data, _ := json.Marshal(twitchwh.Notification{MessageID: id, Subscription: twitchwh.Subscription{Type: "channel.follow"}})
+		return string(data)
+	}
+	d.rows = [][]driver.Value{{int64(2), int64(1), notification("b")}, {int64(1), int64(3), notification("a")}}
+
+	entries, err := outbox.Claim(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != 1 || entries[1].ID != 2 {
+		t.Fatalf("Expected the entries sorted by ID, got %+v", entries)
+	}
+	if entries[0].Attempts != 3 || entries[0].Notification.MessageID != "a" || entries[0].Notification.Subscription.Type != "channel.follow" {
+		t.Errorf("Expected the entry to be decoded, got %+v", entries[0])
+	}
+	if query := d.execs[0].query; !strings.Contains(query, "FOR UPDATE SKIP LOCKED") || !strings.Contains(query, "LIMIT $3") {
+		t.Errorf("Expected the claimed rows to be locked, got %q", query)
+	}
+}
+
+func TestFail(t *testing.T) {
+	outbox, d := newFakeOutbox(t, SQLite)
+	ctx := context.Background()
+	retryAt := time.Now().Add(time.Minute)
+	outbox.Fail(ctx, 1, errors.New("broken"), retryAt)
+	outbox.Fail(ctx, 2, errors.New("broken"), time.Time{})
+
+	if got := d.execs[0].args[0].Value; got != retryAt.UnixMilli() {
+		t.Errorf("Expected available_at to be the retry time, got %v", got)
+	}
+	if got := d.execs[1].args[0].Value; got != nil {
+		t.Errorf("Expected available_at to be NULL for entries given up on, got %v", got)
+	}
+	if got := d.execs[1].args[1].Value; got != "broken" {
+		t.Errorf("Expected the error to be stored, got %v", got)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	outbox, _ := newFakeOutbox(t, SQLite)
+	n, err := outbox.Purge(context.Background(), time.Now())
+	if err != nil || n != 3 {
+		t.Fatalf("Expected the number of deleted rows, got %d, %v", n, err)
+	}
+}
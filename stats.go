@@ -0,0 +1,175 @@
+package twitchwh
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats are in-memory counters since the client was created, returned by Client.Stats.
+type Stats struct {
+	// Counters per event type.
+	Types map[string]TypeStats `json:"types"`
+	// Notifications ignored because they were already handled.
+	Duplicates int64 `json:"duplicates"`
+	// Handler invocations that returned an error or panicked.
+	Failures int64 `json:"failures"`
+	// Requests rejected because of an invalid signature.
+	SignatureFailures int64 `json:"signature_failures"`
+	// Number of times the app access token was regenerated.
+	TokenRefreshes int64 `json:"token_refreshes"`
+	// Notifications rejected with 503, see MetricsHook.NotificationShed.
+	Shed int64 `json:"shed"`
+	// Notifications acknowledged but not handled, see MetricsHook.NotificationDropped.
+	Dropped int64 `json:"dropped"`
+	// Time of the last non-duplicate notification of any type. Zero if none were received.
+	LastEvent time.Time `json:"last_event"`
+	// Response latencies per message type: "notification", "webhook_callback_verification", or "revocation".
+	// Requests with another or no message type, like probes, are not counted.
+	Responses map[string]ResponseStats `json:"responses"`
+}
+
+// TypeStats are the counters for a single event type.
+type TypeStats struct {
+	Events     int64     `json:"events"`
+	Duplicates int64     `json:"duplicates"`
+	Failures   int64     `json:"failures"`
+	Shed       int64     `json:"shed"`
+	Dropped    int64     `json:"dropped"`
+	LastEvent  time.Time `json:"last_event"`
+}
+
+// ResponseStats are the latencies of the webhook responses to a message type, from receiving the request to writing
+// the status code. See MetricsHook.ResponseWritten.
+type ResponseStats struct {
+	Responses int64 `json:"responses"`
+	// Sum of the latencies, see MeanLatency.
+	TotalLatency time.Duration `json:"total_latency"`
+	MaxLatency   time.Duration `json:"max_latency"`
+	// Responses slower than ClientConfig.SlowResponseThreshold.
+	Slow int64 `json:"slow"`
+}
+
+// MeanLatency returns the average latency of the responses, zero if there were none.
+func (r ResponseStats) MeanLatency() time.Duration {
+	if r.Responses == 0 {
+		return 0
+	}
+	return r.TotalLatency / time.Duration(r.Responses)
+}
+
+// statsCollector is the MetricsHook backing Client.Stats. It is always installed.
+type statsCollector struct {
+	NoopMetricsHook
+	mu    sync.Mutex
+	stats Stats
+	// Set by newClient, disabled if not positive
+	slowResponseThreshold time.Duration
+}
+
+func newStatsCollector() *statsCollector {
+	return &statsCollector{stats: Stats{Types: make(map[string]TypeStats), Responses: make(map[string]ResponseStats)}}
+}
+
+func (s *statsCollector) EventReceived(eventType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	t := s.stats.Types[eventType]
+	t.Events++
+	t.LastEvent = now
+	s.stats.Types[eventType] = t
+	s.stats.LastEvent = now
+}
+
+func (s *statsCollector) DuplicateEvent(eventType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.stats.Types[eventType]
+	t.Duplicates++
+	s.stats.Types[eventType] = t
+	s.stats.Duplicates++
+}
+
+func (s *statsCollector) HandlerFinished(eventType string, _ time.Duration, err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.stats.Types[eventType]
+	t.Failures++
+	s.stats.Types[eventType] = t
+	s.stats.Failures++
+}
+
+func (s *statsCollector) SignatureRejected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.SignatureFailures++
+}
+
+func (s *statsCollector) TokenRefreshed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.TokenRefreshes++
+}
+
+func (s *statsCollector) NotificationShed(eventType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.stats.Types[eventType]
+	t.Shed++
+	s.stats.Types[eventType] = t
+	s.stats.Shed++
+}
+
+func (s *statsCollector) ResponseWritten(messageType string, _ int, latency time.Duration) {
+	if !isMessageType(messageType) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.stats.Responses[messageType]
+	r.Responses++
+	r.TotalLatency += latency
+	r.MaxLatency = max(r.MaxLatency, latency)
+	if s.slowResponseThreshold > 0 && latency > s.slowResponseThreshold {
+		r.Slow++
+	}
+	s.stats.Responses[messageType] = r
+}
+
+func (s *statsCollector) NotificationDropped(eventType string, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.stats.Types[eventType]
+	t.Dropped++
+	s.stats.Types[eventType] = t
+	s.stats.Dropped++
+}
+
+// isMessageType reports whether t is a message type Twitch sends. The header is only trusted this far, since
+// unsigned requests are counted too.
+func isMessageType(t string) bool {
+	return t == messageTypeNotification || t == messageTypeVerification || t == messageTypeRevocation
+}
+
+func (s *statsCollector) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := s.stats
+	snapshot.Types = make(map[string]TypeStats, len(s.stats.Types))
+	for eventType, t := range s.stats.Types {
+		snapshot.Types[eventType] = t
+	}
+	snapshot.Responses = make(map[string]ResponseStats, len(s.stats.Responses))
+	for messageType, r := range s.stats.Responses {
+		snapshot.Responses[messageType] = r
+	}
+	return snapshot
+}
+
+// Stats returns a snapshot of the client's in-memory counters, eg: for rendering a status page.
+func (c *Client) Stats() Stats {
+	return c.stats.snapshot()
+}
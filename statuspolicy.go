@@ -0,0 +1,79 @@
+package twitchwh
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// StatusPolicy sets the status codes Handler responds to requests it doesn't process, eg: because a WAF or proxy in
+// front of the handler treats some codes specially. Zero fields use the status of DefaultStatusPolicy.
+//
+// The other statuses have a meaning to Twitch and are fixed: 204 for accepted notifications, 200 for challenges,
+// 500 for failures and 503 while shedding load, so Twitch redelivers the notification. See ClientConfig.ProbeStatus
+// for requests that aren't POST.
+type StatusPolicy struct {
+	// Notifications whose message ID was already handled. Must be 2xx, Twitch redelivers the notification otherwise.
+	Duplicate int
+	// Signed messages with a timestamp older than 10 minutes, likely a replay.
+	TooOld int
+	// Signed messages whose Twitch-Eventsub-Message-Type header isn't notification, webhook_callback_verification,
+	// or revocation.
+	UnknownMessageType int
+	// Messages with a missing or invalid signature.
+	InvalidSignature int
+}
+
+// DefaultStatusPolicy returns the status codes used if ClientConfig.StatusPolicy is not set: 204 for duplicates and
+// too old messages, 200 for unknown message types, and 403 for invalid signatures.
+func DefaultStatusPolicy() StatusPolicy {
+	return StatusPolicy{
+		Duplicate:          http.StatusNoContent,
+		TooOld:             http.StatusNoContent,
+		UnknownMessageType: http.StatusOK,
+		InvalidSignature:   http.StatusForbidden,
+	}
+}
+
+// StrictStatusPolicy returns status codes that don't report success for messages the handler rejected, as RFC 9110
+// intends: 204 for duplicates, which were processed before, 400 Bad Request for too old messages and unknown message
+// types, and 403 Forbidden for invalid signatures.
+func StrictStatusPolicy() StatusPolicy {
+	return StatusPolicy{
+		Duplicate:          http.StatusNoContent,
+		TooOld:             http.StatusBadRequest,
+		UnknownMessageType: http.StatusBadRequest,
+		InvalidSignature:   http.StatusForbidden,
+	}
+}
+
+// withDefaults returns the policy with zero fields set to the status of DefaultStatusPolicy.
+func (p StatusPolicy) withDefaults() StatusPolicy {
+	defaults := DefaultStatusPolicy()
+	if p.Duplicate == 0 {
+		p.Duplicate = defaults.Duplicate
+	}
+	if p.TooOld == 0 {
+		p.TooOld = defaults.TooOld
+	}
+	if p.UnknownMessageType == 0 {
+		p.UnknownMessageType = defaults.UnknownMessageType
+	}
+	if p.InvalidSignature == 0 {
+		p.InvalidSignature = defaults.InvalidSignature
+	}
+	return p
+}
+
+// validate returns an error for status codes that aren't valid or would make Twitch redeliver handled
+// notifications.
+func (p StatusPolicy) validate() error {
+	if p.Duplicate < 200 || p.Duplicate > 299 {
+		return fmt.Errorf("duplicate status must be 2xx, got %d", p.Duplicate)
+	}
+	for _, status := range []int{p.TooOld, p.UnknownMessageType, p.InvalidSignature} {
+		if status < 100 || status > 999 {
+			return fmt.Errorf("invalid status code %d", status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package twitchwh
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStatusPolicy(t *testing.T) {
+	oldRequest := func() *http.Request {
+		timestamp := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		r := httptest.NewRequest(http.MethodPost, "/eventsub", bytes.NewBufferString(chatMessageBody))
+		r.Header.Set(twitchMessageID, "old")
+		r.Header.Set(twitchMessageTimestamp, timestamp)
+		r.Header.Set(twitchMessageSignature, Signature(testWebhookSecret, "old", timestamp, []byte(chatMessageBody)))
+		r.Header.Set(twitchMessageType, messageTypeNotification)
+		return r
+	}
+	unsigned := func() *http.Request {
+		r := signedRequest("unsigned", messageTypeNotification, chatMessageBody)
+		r.Header.Set(twitchMessageSignature, "sha256=00")
+		return r
+	}
+
+	for _, test := range []struct {
+		name   string
+		policy StatusPolicy
+		want   [4]int
+	}{
+		{"default", StatusPolicy{}, [4]int{204, 204, 200, 403}},
+		{"strict", StrictStatusPolicy(), [4]int{204, 400, 400, 403}},
+		{"partial", StatusPolicy{InvalidSignature: 401, Duplicate: 200}, [4]int{200, 204, 200, 401}},
+	} {
+		c := newClient(ClientConfig{WebhookSecret: testWebhookSecret, StatusPolicy: test.policy})
+		c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+		var got [4]int
+		for i, r := range []*http.Request{
+			signedRequest("a", messageTypeNotification, chatMessageBody),
+			oldRequest(),
+			signedRequest("b", "unknown", chatMessageBody),
+			unsigned(),
+		} {
+			w := httptest.NewRecorder()
+			c.Handler(w, r)
+			got[i] = w.Code
+		}
+		if got != test.want {
+			t.Errorf("%s: expected statuses %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestStatusPolicyValidate(t *testing.T) {
+	if _, err := NewReceiver(testWebhookSecret, WithConfig(ClientConfig{
+		StatusPolicy: StatusPolicy{Duplicate: http.StatusConflict},
+	})); err == nil {
+		t.Fatal("Expected error for a duplicate status Twitch would redeliver")
+	}
+	if _, err := NewReceiver(testWebhookSecret, WithConfig(ClientConfig{
+		StatusPolicy: StatusPolicy{InvalidSignature: 42},
+	})); err == nil {
+		t.Fatal("Expected error for an invalid status code")
+	}
+}
@@ -0,0 +1,206 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stream is a live stream returned by Helix, see HelixAPI.GetStreams.
+type Stream struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	UserLogin   string    `json:"user_login"`
+	UserName    string    `json:"user_name"`
+	GameID      string    `json:"game_id"`
+	GameName    string    `json:"game_name"`
+	Type        string    `json:"type"`
+	Title       string    `json:"title"`
+	ViewerCount int       `json:"viewer_count"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// StreamState is the live state of a broadcaster tracked by a StreamTracker.
+type StreamState struct {
+	UserID    string
+	UserLogin string
+	Live      bool
+	// ID of the stream, empty while offline.
+	StreamID string
+	// Stream type, eg: "live" or "rerun". Empty while offline.
+	Type string
+	// When the stream started, zero while offline.
+	StartedAt time.Time
+	// Time of the notification or Helix response the state is from.
+	Updated time.Time
+}
+
+// StreamTracker maintains the live state of broadcasters from stream.online and stream.offline notifications, so the
+// application doesn't have to. Subscribe to both types for every broadcaster to track, and call Backfill at startup
+// to catch up on events missed while the application was down:
+//
+//	tracker := twitchwh.NewStreamTracker(client)
+//	tracker.OnChange(func(state twitchwh.StreamState) {
+//		log.Printf("%s live: %v", state.UserLogin, state.Live)
+//	})
+//	go tracker.Backfill(ctx)
+//
+//	if tracker.IsLive(broadcasterID) { ... }
+//
+// Notifications older than the current state of a broadcaster are ignored, so redelivered or reordered
+// notifications don't flip it back.
+type StreamTracker struct {
+	c        *Client
+	mu       sync.RWMutex
+	states   map[string]StreamState
+	onChange []func(StreamState)
+}
+
+// NewStreamTracker creates a tracker receiving the client's stream.online and stream.offline notifications. It is
+// added as a sink, so handlers registered for the types are still called.
+func NewStreamTracker(c *Client) *StreamTracker {
+	t := &StreamTracker{c: c, states: make(map[string]StreamState)}
+	c.AddSink(t, TypeFilter(TypeStreamOnline, TypeStreamOffline))
+	return t
+}
+
+// OnChange registers a function called whenever a broadcaster goes live or offline, including changes found by
+// Backfill. Functions are called in the order they were registered, and must not block.
+func (t *StreamTracker) OnChange(fn func(StreamState)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onChange = append(t.onChange, fn)
+}
+
+// IsLive reports whether the broadcaster is live. Returns false for broadcasters without a known state.
+func (t *StreamTracker) IsLive(userID string) bool {
+	state, _ := t.State(userID)
+	return state.Live
+}
+
+// State returns the state of a broadcaster, or false if it is not known yet.
+func (t *StreamTracker) State(userID string) (StreamState, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	state, ok := t.states[userID]
+	return state, ok
+}
+
+// Live returns the states of the broadcasters that are live, sorted by user ID.
+func (t *StreamTracker) Live() []StreamState {
+	t.mu.RLock()
+	var live []StreamState
+	for _, state := range t.states {
+		if state.Live {
+			live = append(live, state)
+		}
+	}
+	t.mu.RUnlock()
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].UserID < live[j].UserID
+	})
+	return live
+}
+
+// Publish implements Sink.
+func (t *StreamTracker) Publish(ctx context.Context, n Notification) error {
+	var event struct {
+		ID                   string    `json:"id"`
+		BroadcasterUserID    string    `json:"broadcaster_user_id"`
+		BroadcasterUserLogin string    `json:"broadcaster_user_login"`
+		Type                 string    `json:"type"`
+		StartedAt            time.Time `json:"started_at"`
+	}
+	if err := json.Unmarshal(n.Event, &event); err != nil {
+		return &InternalError{"Could not parse stream event", err}
+	}
+	state := StreamState{
+		UserID:    event.BroadcasterUserID,
+		UserLogin: event.BroadcasterUserLogin,
+		Updated:   n.Timestamp,
+	}
+	if n.Subscription.Type == TypeStreamOnline {
+		state.Live = true
+		state.StreamID = event.ID
+		state.Type = event.Type
+		state.StartedAt = event.StartedAt
+	}
+	t.update(state)
+	return nil
+}
+
+// Backfill sets the state of every broadcaster with a stream.online subscription from Helix, eg: at startup to catch
+// up on events missed while the application was down. Its Helix requests have PriorityBackground.
+func (t *StreamTracker) Backfill(ctx context.Context) error {
+	ctx = withPriority(ctx, PriorityBackground)
+	subs, err := t.c.fetchSubscriptions(ctx, SubscriptionQuery{Type: TypeStreamOnline})
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	var userIDs []string
+	for _, sub := range subs {
+		if id := sub.Condition.BroadcasterUserID; id != "" && !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	for start := 0; start < len(userIDs); start += maxIDsPerRequest {
+		batch := userIDs[start:min(start+maxIDsPerRequest, len(userIDs))]
+		now := time.Now()
+		streams, err := t.c.helix.GetStreams(ctx, batch)
+		if err != nil {
+			return err
+		}
+		live := make(map[string]Stream, len(streams))
+		for _, stream := range streams {
+			live[stream.UserID] = stream
+		}
+		for _, userID := range batch {
+			state := StreamState{UserID: userID, Updated: now}
+			if stream, ok := live[userID]; ok {
+				state = StreamState{
+					UserID:    userID,
+					UserLogin: stream.UserLogin,
+					Live:      true,
+					StreamID:  stream.ID,
+					Type:      stream.Type,
+					StartedAt: stream.StartedAt,
+					Updated:   now,
+				}
+			}
+			t.update(state)
+		}
+	}
+	return nil
+}
+
+// Maximum number of user IDs per Get Streams or Get Channel Information request.
+const maxIDsPerRequest = 100
+
+// update stores a state unless a newer one is known, and calls the change functions if the broadcaster went live or
+// offline.
+func (t *StreamTracker) update(state StreamState) {
+	t.mu.Lock()
+	previous, known := t.states[state.UserID]
+	if known && state.Updated.Before(previous.Updated) {
+		t.mu.Unlock()
+		return
+	}
+	if state.UserLogin == "" {
+		state.UserLogin = previous.UserLogin
+	}
+	t.states[state.UserID] = state
+	onChange := t.onChange
+	t.mu.Unlock()
+
+	if known && previous.Live == state.Live && previous.StreamID == state.StreamID {
+		return
+	}
+	for _, fn := range onChange {
+		fn(state)
+	}
+}
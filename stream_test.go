@@ -0,0 +1,79 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamTracker(t *testing.T) {
+	c := newClient(ClientConfig{})
+	tracker := NewStreamTracker(c)
+	var changes []StreamState
+	tracker.OnChange(func(state StreamState) {
+		changes = append(changes, state)
+	})
+
+	now := time.Now()
+	online := Notification{
+		Timestamp:    now,
+		Subscription: Subscription{Type: TypeStreamOnline},
+		Event:        json.RawMessage(`{"id":"9","broadcaster_user_id":"1","broadcaster_user_login":"streamer","type":"live","started_at":"2024-05-01T12:00:00Z"}`),
+	}
+	offline := Notification{
+		Timestamp:    now.Add(-time.Minute),
+		Subscription: Subscription{Type: TypeStreamOffline},
+		Event:        json.RawMessage(`{"broadcaster_user_id":"1","broadcaster_user_login":"streamer"}`),
+	}
+	if err := tracker.Publish(context.Background(), online); err != nil {
+		t.Fatal(err)
+	}
+	if !tracker.IsLive("1") || len(changes) != 1 {
+		t.Fatalf("Expected broadcaster to be live, got %+v", changes)
+	}
+	// Older than the current state, eg: redelivered
+	tracker.Publish(context.Background(), offline)
+	if !tracker.IsLive("1") {
+		t.Fatal("Expected older notification to be ignored")
+	}
+	offline.Timestamp = now.Add(time.Minute)
+	tracker.Publish(context.Background(), offline)
+	if tracker.IsLive("1") || len(changes) != 2 || changes[1].Live {
+		t.Fatalf("Expected broadcaster to be offline, got %+v", changes)
+	}
+	if tracker.IsLive("2") {
+		t.Fatal("Expected unknown broadcaster not to be live")
+	}
+}
+
+func TestStreamTrackerBackfill(t *testing.T) {
+	c := newClient(ClientConfig{})
+	var streamsQuery string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(r.URL.Path, "/streams") {
+			streamsQuery = r.URL.RawQuery
+			return jsonResponse(200, `{"data":[{"id":"9","user_id":"1","user_login":"streamer","type":"live"}],"pagination":{}}`), nil
+		}
+		return jsonResponse(200, `{"data":[`+
+			`{"id":"a","type":"stream.online","condition":{"broadcaster_user_id":"1"}},`+
+			`{"id":"b","type":"stream.online","condition":{"broadcaster_user_id":"2"}}`+
+			`],"pagination":{}}`), nil
+	})}
+	tracker := NewStreamTracker(c)
+
+	if err := tracker.Backfill(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if streamsQuery != "first=100&user_id=1&user_id=2" {
+		t.Fatalf("Unexpected query %s", streamsQuery)
+	}
+	if !tracker.IsLive("1") || tracker.IsLive("2") {
+		t.Fatalf("Unexpected states %+v", tracker.Live())
+	}
+	if _, ok := tracker.State("2"); !ok {
+		t.Fatal("Expected offline broadcaster to be known")
+	}
+}
@@ -0,0 +1,56 @@
+package twitchwh
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// subscriptionCache holds the subscription list for ClientConfig.SubscriptionCacheTTL.
+type subscriptionCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	subs      []Subscription
+	fetchedAt time.Time
+}
+
+// cachedSubscriptions returns all subscriptions, from the cache if it is still fresh.
+// Concurrent callers wait for a single fetch instead of all paging through Helix.
+func (c *Client) cachedSubscriptions(ctx context.Context) ([]Subscription, error) {
+	c.subscriptionCache.mu.Lock()
+	defer c.subscriptionCache.mu.Unlock()
+	if c.subscriptionCache.subs == nil || time.Since(c.subscriptionCache.fetchedAt) >= c.subscriptionCache.ttl {
+		subs, err := c.fetchSubscriptions(ctx, SubscriptionQuery{})
+		if err != nil {
+			return nil, err
+		}
+		if subs == nil {
+			subs = []Subscription{}
+		}
+		c.subscriptionCache.subs = subs
+		c.subscriptionCache.fetchedAt = time.Now()
+	}
+	return slices.Clone(c.subscriptionCache.subs), nil
+}
+
+// filterCachedSubscriptions returns the cached subscriptions that match keep.
+func (c *Client) filterCachedSubscriptions(ctx context.Context, keep func(Subscription) bool) ([]Subscription, error) {
+	subs, err := c.cachedSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(subs, func(sub Subscription) bool {
+		return !keep(sub)
+	}), nil
+}
+
+// InvalidateSubscriptionCache discards the cached subscription list, so the next call to GetSubscriptions (or its
+// variants) fetches it from Twitch. The client invalidates the cache itself whenever it creates or removes a
+// subscription, or Twitch revokes one. Call this after changing subscriptions outside the client.
+// Does nothing if ClientConfig.SubscriptionCacheTTL is not set.
+func (c *Client) InvalidateSubscriptionCache() {
+	c.subscriptionCache.mu.Lock()
+	defer c.subscriptionCache.mu.Unlock()
+	c.subscriptionCache.subs = nil
+}
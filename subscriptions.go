@@ -1,11 +1,11 @@
 package twitchwh
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"io"
-	"net/http"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -26,10 +26,8 @@ type Condition struct {
 	// to_broadcaster_id
 	ToBroadcasterUserID string `json:"to_broadcaster_user_id,omitempty"`
 
-	// reward_id
-	//
-	// This should be int/string depending on subscription type
-	RewardID any `json:"reward_id,omitempty"`
+	// reward_id, only for the channel points custom reward update and remove, and redemption types
+	RewardID RewardID `json:"reward_id,omitempty"`
 
 	// client_id
 	ClientID string `json:"client_id,omitempty"`
@@ -50,6 +48,102 @@ type Condition struct {
 	CampaignID string `json:"campaign_id,omitempty"`
 }
 
+// hasUser reports whether any user ID field of the condition is id, like the user_id filter of Helix.
+func (c Condition) hasUser(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, userID := range []string{c.BroadcasterUserID, c.ModeratorUserID, c.UserID, c.FromBroadcasterUserID, c.ToBroadcasterUserID} {
+		if userID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize returns the condition in canonical form: RewardID is lower-cased, since Twitch returns reward IDs as
+// lower-case UUIDs.
+func (c Condition) Normalize() Condition {
+	c.RewardID = RewardID(strings.ToLower(string(c.RewardID)))
+	return c
+}
+
+// RewardID is the ID of a channel points custom reward, a UUID. It is unmarshaled from a JSON string or number, and
+// always marshaled as a string, the type Twitch expects and returns.
+type RewardID string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (id *RewardID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = RewardID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("reward_id must be a string or number, got %s", data)
+	}
+	*id = RewardID(n.String())
+	return nil
+}
+
+// Equal reports whether every field of two conditions is equal, in their normalized form, see Normalize. Use EqualFor
+// to compare conditions of a subscription type, which ignores fields the type doesn't use.
+func (c Condition) Equal(other Condition) bool {
+	return c.Normalize() == other.Normalize()
+}
+
+// EqualFor reports whether two conditions select the same events of a subscription type. Only the condition fields
+// of the type are compared, in their normalized form, so a condition given to AddSubscription matches the one Twitch
+// returns for the subscription, even if it sets fields the type doesn't use. Unknown types compare every field, like
+// Equal.
+func (c Condition) EqualFor(Type string, other Condition) bool {
+	return c.forType(Type).Equal(other.forType(Type))
+}
+
+// forType returns the condition with only the fields of a subscription type set, see conditionFields. Returns the
+// condition unchanged for unknown types.
+func (c Condition) forType(Type string) Condition {
+	fields, ok := conditionFields[Type]
+	if !ok {
+		return c
+	}
+	var relevant Condition
+	for _, field := range fields {
+		switch field {
+		case "broadcaster_user_id":
+			relevant.BroadcasterUserID = c.BroadcasterUserID
+		case "moderator_user_id":
+			relevant.ModeratorUserID = c.ModeratorUserID
+		case "user_id":
+			relevant.UserID = c.UserID
+		case "from_broadcaster_user_id":
+			relevant.FromBroadcasterUserID = c.FromBroadcasterUserID
+		case "to_broadcaster_user_id":
+			relevant.ToBroadcasterUserID = c.ToBroadcasterUserID
+		case "reward_id":
+			relevant.RewardID = c.RewardID
+		case "client_id":
+			relevant.ClientID = c.ClientID
+		case "extension_client_id":
+			relevant.ExtensionClientID = c.ExtensionClientID
+		case "conduit_id":
+			relevant.ConduitID = c.ConduitID
+		case "organization_id":
+			relevant.OrganizationID = c.OrganizationID
+		case "category_id":
+			relevant.CategoryID = c.CategoryID
+		case "campaign_id":
+			relevant.CampaignID = c.CampaignID
+		}
+	}
+	return relevant
+}
+
 type Subscription struct {
 	ID      string `json:"id"`
 	Status  string `json:"status"`
@@ -65,19 +159,6 @@ type Subscription struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-type transport struct {
-	Method   string `json:"method"`
-	Callback string `json:"callback"`
-	Secret   string `json:"secret"`
-}
-
-type subscriptionRequest struct {
-	Type      string    `json:"type"`
-	Version   string    `json:"version"`
-	Condition Condition `json:"condition"`
-	Transport transport `json:"transport"`
-}
-
 // AddSubscription attemps to create a new subscription based on the type, version, and condition.
 // You can find all subscription types, versions, and conditions at: [EventSub subscription types].
 // It will block until Twitch sends the verification request, or timeout after 10 seconds.
@@ -92,160 +173,114 @@ type subscriptionRequest struct {
 //		BroadcasterUserID: "215185844",
 //	})
 //
+// Returns [NotLeaderError] if ClientConfig.Elector is set and this instance is not the leader.
+//
 // [EventSub subscription types]: https://dev.twitch.tv/docs/eventsub/eventsub-subscription-types/
 func (c *Client) AddSubscription(Type string, version string, condition Condition) (string, error) {
-	id, err := c.addSubscription(Type, version, condition)
-	if err != nil {
-		var uaErr *UnauthorizedError
-		if errors.As(err, &uaErr) {
-			c.logger.Println("Token invalid, generating a new one")
-			token, err := c.generateToken(c.clientID, c.clientSecret)
-			if err != nil {
-				return "", err
-			}
-			c.token = token
-			return c.addSubscription(Type, version, condition)
-		}
+	return c.createSubscription(context.Background(), Type, version, condition)
+}
 
+// createSubscription is AddSubscription with a context carrying the priority of its Helix requests.
+func (c *Client) createSubscription(ctx context.Context, Type string, version string, condition Condition) (string, error) {
+	if err := c.checkLeader(); err != nil {
+		return "", err
+	}
+	if condition.RewardID != "" && !rewardTypes[Type] {
+		return "", &InvalidConditionError{Type: Type, Field: "reward_id"}
+	}
+	if c.locker != nil {
+		unlock, err := c.lockSubscription(ctx, Type, condition)
+		if err != nil {
+			return "", err
+		}
+		defer unlock()
+	}
+	id, err := c.addSubscription(ctx, Type, version, condition)
+	if err != nil {
 		var usErr *UnhandledStatusError
 		if errors.As(err, &usErr) {
-			c.logger.Printf("Unhandled status code %d: %s", usErr.Status, string(usErr.Body))
-			return "", err
+			c.reportError("Unhandled status code", err, "status", usErr.Status, "body", string(usErr.Body))
 		}
-
 		return "", err
 	}
 	return id, nil
 }
 
-func (c *Client) addSubscription(Type string, version string, condition Condition) (string, error) {
-	reqBody, err := json.Marshal(subscriptionRequest{
+func (c *Client) addSubscription(ctx context.Context, Type string, version string, condition Condition) (string, error) {
+	journalCreated, journalDone, err := c.beginPending(Type, version, condition)
+	if err != nil {
+		return "", err
+	}
+	defer journalDone()
+
+	ctx = withHelixResponse(ctx)
+	subscription, err := c.helix.CreateSubscription(ctx, SubscriptionRequest{
 		Type:      Type,
 		Version:   version,
 		Condition: condition,
-		Transport: transport{
+		Transport: SubscriptionTransport{
 			Method:   "webhook",
-			Callback: c.webhookURL,
+			Callback: c.GetWebhookURL(),
 			Secret:   c.GetWebhookSecret(),
 		},
 	})
 	if err != nil {
-		return "", &InternalError{"Could not serialize request body to JSON", err}
-	}
-
-	request, err := http.NewRequest("POST", helixURL+"/eventsub/subscriptions", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return "", &InternalError{"Could not create request", err}
-	}
-
-	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("Client-ID", c.clientID)
-	request.Header.Set("Authorization", "Bearer "+c.token)
-
-	res, err := c.httpClient.Do(request)
-	if err != nil {
-		return "", &InternalError{"Could not send request", err}
-	}
-
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return "", &InternalError{"Could not read response body", err}
-	}
-
-	if res.StatusCode == 409 {
-		return "", &DuplicateSubscriptionError{
-			Condition: condition,
-			Type:      Type,
-		}
-	}
-
-	if res.StatusCode == 401 {
-		return "", &UnauthorizedError{}
-	}
-	if res.StatusCode != 202 {
-		return "", &UnhandledStatusError{res.StatusCode, body}
-	}
-
-	var responseBody struct {
-		Data []Subscription `json:"data"`
-	}
-
-	err = json.Unmarshal(body, &responseBody)
-	if err != nil {
-		return "", &InternalError{"Could not parse response body", err}
+		return "", err
 	}
-
-	// Returned body is an array that contains a single subscription
-	if len(responseBody.Data) < 1 {
-		return "", &InternalError{"Helix did not return the subscription they were supposed to", nil}
+	c.InvalidateSubscriptionCache()
+	journalCreated(subscription.ID)
+
+	c.auditMutation(ctx, AuditActionCreated, subscription, subscription.Status)
+	c.created.add(subscription.ID)
+	if c.mode == ModeAPI && c.verificationRelay == nil {
+		// The receiver answers the challenge, its result shows in the subscription status
+		c.logger.Info("Subscription created, verification pending", "subscription_id", subscription.ID, "type", subscription.Type)
+		return subscription.ID, nil
 	}
-	subscription := responseBody.Data[0]
+	c.pending.add(subscription)
+	defer c.pending.remove(subscription.ID)
 
 	// Await confirmation
-	for {
-		select {
-		case id := <-c.VerifiedSubscriptions:
-			if id == subscription.ID {
-				c.logger.Printf("Subscription created: %s", subscription.ID)
-				return id, nil
-			} else {
-				// Verified subscription was not for this subscription
-				c.logger.Println("Subscription confirmation did not match ID, ignoring...")
-				c.VerifiedSubscriptions <- id
-				continue
-			}
-		case <-time.After(10 * time.Second):
-			return "", &VerificationTimeoutError{subscription}
-		}
+	answeredBefore := c.verifications.answered.Load()
+	verified := c.verifications.wait(subscription.ID)
+	defer c.verifications.cancel(subscription.ID)
+	timer := time.NewTimer(verificationTimeout)
+	defer timer.Stop()
+	select {
+	case <-verified:
+		c.logger.Info("Subscription created", "subscription_id", subscription.ID, "type", subscription.Type)
+		return subscription.ID, nil
+	case <-timer.C:
+		c.logger.Warn("Subscription was not verified in time", "subscription_id", subscription.ID, "type", subscription.Type)
+		c.audit(AuditActionVerificationFailed, subscription, "verification timed out")
+		return "", c.verificationTimeoutError(ctx, subscription, answeredBefore)
 	}
 }
 
 // RemoveSubscription attempts to remove a subscription based on the ID.
-// Returns [SubscriptionNotFoundError] if the subscription does not exist.
+// Returns [SubscriptionNotFoundError] if the subscription does not exist,
+// or [NotLeaderError] if ClientConfig.Elector is set and this instance is not the leader.
 func (c *Client) RemoveSubscription(id string) error {
-	err := c.removeSubscription(id)
-	if err != nil {
-		var uaErr *UnauthorizedError
-		if errors.As(err, &uaErr) {
-			c.logger.Println("Token invalid, generating a new one")
-			token, err := c.generateToken(c.clientID, c.clientSecret)
-			if err != nil {
-				return err
-			}
-			c.token = token
-			return c.removeSubscription(id)
-		}
-	}
-	return err
+	return c.deleteSubscription(context.Background(), id)
 }
 
-func (c *Client) removeSubscription(id string) error {
-	url := "/eventsub/subscriptions?id=" + id
-	res, err := c.genericRequest("DELETE", url)
-	if err != nil {
-		return &InternalError{"Could not make request", err}
-	}
-
-	if res.StatusCode == 204 {
-		return nil
-	}
-	if res.StatusCode == 401 {
-		return &UnauthorizedError{}
-	}
-	if res.StatusCode == 404 {
-		return &SubscriptionNotFoundError{}
+// deleteSubscription is RemoveSubscription with a context carrying the priority of its Helix requests.
+func (c *Client) deleteSubscription(ctx context.Context, id string) error {
+	if err := c.checkLeader(); err != nil {
+		return err
 	}
+	return c.removeSubscription(ctx, id)
+}
 
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return &InternalError{"Could not read response body", err}
-	}
-	return &UnhandledStatusError{
-		Status: res.StatusCode,
-		Body:   body,
+func (c *Client) removeSubscription(ctx context.Context, id string) error {
+	ctx = withHelixResponse(ctx)
+	if err := c.helix.DeleteSubscription(ctx, id); err != nil {
+		return err
 	}
+	c.InvalidateSubscriptionCache()
+	c.created.remove(id)
+	c.auditMutation(ctx, AuditActionDeleted, Subscription{ID: id}, "removed by client")
+	return nil
 }
 
 // RemoveSubscriptionByType attempts to remove a subscription based on the type and condition.
@@ -259,9 +294,8 @@ func (c *Client) RemoveSubscriptionByType(Type string, condition Condition) erro
 		return err
 	}
 	for _, sub := range subs {
-		// Both of these conditions have unused fields, but since they are both defaulted and of the same type it should be fine
-		if sub.Condition == condition {
-			c.logger.Printf("Removing subscription %s", sub.ID)
+		if sub.Condition.EqualFor(Type, condition) {
+			c.logger.Info("Removing subscription", "subscription_id", sub.ID, "type", sub.Type)
 			err := c.RemoveSubscription(sub.ID)
 			if err != nil {
 				return err
@@ -271,71 +305,56 @@ func (c *Client) RemoveSubscriptionByType(Type string, condition Condition) erro
 	return nil
 }
 
-// Internal function to fetch subscriptions using the provided URL parameters.
-// Used by wrapper functions.
-// Automatically handles pagination.
-func (c *Client) fetchSubscriptions(urlParams string) (subscriptions []Subscription, err error) {
-	page := 1
-	cursor := ""
-	for {
-		c.logger.Printf("Fetching page %d of subscriptions", page)
-		page++
-
-		var params string
-		if cursor != "" {
-			if urlParams == "" {
-				params = urlParams + "?after=" + cursor
-			} else {
-				params = "&after=" + cursor
-			}
-		}
-		res, err := c.genericRequest("GET", "/eventsub/subscriptions"+params)
-		if err != nil {
-			return nil, &InternalError{"Could not make request", err}
-		}
-		if res.StatusCode == 401 {
-			c.logger.Println("Token invalid, generating a new one")
-			token, err := c.generateToken(c.clientID, c.clientSecret)
-			if err != nil {
-				return nil, err
-			}
-			c.token = token
-			res, err = c.genericRequest("GET", "/eventsub/subscriptions"+params)
-			if err != nil {
-				return nil, &InternalError{"Could not make request", err}
-			}
-		}
-
-		defer res.Body.Close()
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, &InternalError{"Could not read response body", err}
+// RemoveBroadcaster deletes every subscription whose condition references the user ID, as broadcaster, moderator,
+// user, or raid source or target, eg: when a broadcaster offboards or requests their data be deleted. The
+// subscriptions are also forgotten by the registry. Subscriptions that no longer exist are skipped.
+//
+// Returns the errors of subscriptions that could not be removed, so RemoveBroadcaster can be retried.
+func (c *Client) RemoveBroadcaster(userID string) error {
+	if err := c.checkLeader(); err != nil {
+		return err
+	}
+	if userID == "" {
+		return errors.New("empty user ID")
+	}
+	subs, err := c.fetchSubscriptions(context.Background(), SubscriptionQuery{UserID: userID})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, sub := range subs {
+		if !sub.Condition.hasUser(userID) {
+			continue
 		}
-
-		if res.StatusCode != 200 {
-			return nil, &UnhandledStatusError{res.StatusCode, body}
+		c.logger.Info("Removing subscription of broadcaster", "subscription_id", sub.ID, "type", sub.Type, "user_id", userID)
+		err := c.removeSubscription(context.Background(), sub.ID)
+		var nfErr *SubscriptionNotFoundError
+		if err != nil && !errors.As(err, &nfErr) {
+			errs = append(errs, err)
+			continue
 		}
+		errs = append(errs, c.registry.Forget(sub.ID))
+	}
+	return errors.Join(errs...)
+}
 
-		var responseStruct struct {
-			Data       []Subscription `json:"data"`
-			Pagination struct {
-				Cursor string `json:"cursor"`
-			} `json:"pagination"`
-		}
-		err = json.Unmarshal(body, &responseStruct)
+// Internal function to fetch subscriptions matching the query.
+// Used by wrapper functions.
+// Automatically handles pagination.
+func (c *Client) fetchSubscriptions(ctx context.Context, query SubscriptionQuery) (subscriptions []Subscription, err error) {
+	for page := 1; ; page++ {
+		c.logger.Debug("Fetching subscriptions", "page", page)
+		subs, cursor, err := c.helix.GetSubscriptions(ctx, query)
 		if err != nil {
-			return nil, &InternalError{"Could not parse response body", err}
+			return nil, err
 		}
-
-		subscriptions = append(subscriptions, responseStruct.Data...)
-
-		if responseStruct.Pagination.Cursor == "" {
+		subscriptions = append(subscriptions, subs...)
+		if cursor == "" {
 			// No more subscriptions to fetch
-			break
+			return subscriptions, nil
 		}
-		cursor = responseStruct.Pagination.Cursor
+		query.After = cursor
 	}
-	return subscriptions, nil
 }
 
 // GetSubscriptions retrieves all subscriptions, including revoked ones.
@@ -343,8 +362,19 @@ func (c *Client) fetchSubscriptions(urlParams string) (subscriptions []Subscript
 //
 // Returns subscriptions and an error (if any).
 func (c *Client) GetSubscriptions() (subscriptions []Subscription, err error) {
-	urlParams := ""
-	return c.fetchSubscriptions(urlParams)
+	return c.getSubscriptions(context.Background())
+}
+
+func (c *Client) getSubscriptions(ctx context.Context) (subscriptions []Subscription, err error) {
+	if c.subscriptionCache.ttl > 0 {
+		subscriptions, err = c.cachedSubscriptions(ctx)
+	} else {
+		subscriptions, err = c.fetchSubscriptions(ctx, SubscriptionQuery{})
+	}
+	if err == nil {
+		c.health.setSubscriptions(subscriptions)
+	}
+	return subscriptions, err
 }
 
 // Get all subscriptions that match the provided type (eg. "stream.online").
@@ -352,8 +382,16 @@ func (c *Client) GetSubscriptions() (subscriptions []Subscription, err error) {
 //
 // Returns subscriptions and an error (if any).
 func (c *Client) GetSubscriptionsByType(Type string) (subscriptions []Subscription, err error) {
-	urlParams := "?type=" + Type
-	return c.fetchSubscriptions(urlParams)
+	return c.getSubscriptionsByType(context.Background(), Type)
+}
+
+func (c *Client) getSubscriptionsByType(ctx context.Context, Type string) (subscriptions []Subscription, err error) {
+	if c.subscriptionCache.ttl > 0 {
+		return c.filterCachedSubscriptions(ctx, func(sub Subscription) bool {
+			return sub.Type == Type
+		})
+	}
+	return c.fetchSubscriptions(ctx, SubscriptionQuery{Type: Type})
 }
 
 // Get all subscriptions with the provided status.
@@ -362,6 +400,75 @@ func (c *Client) GetSubscriptionsByType(Type string) (subscriptions []Subscripti
 //
 // Returns subscriptions and an error (if any).
 func (c *Client) GetSubscriptionsByStatus(status string) (subscriptions []Subscription, err error) {
-	urlParams := "?status=" + status
-	return c.fetchSubscriptions(urlParams)
+	return c.getSubscriptionsByStatus(context.Background(), status)
+}
+
+func (c *Client) getSubscriptionsByStatus(ctx context.Context, status string) (subscriptions []Subscription, err error) {
+	if c.subscriptionCache.ttl > 0 {
+		return c.filterCachedSubscriptions(ctx, func(sub Subscription) bool {
+			return sub.Status == status
+		})
+	}
+	return c.fetchSubscriptions(ctx, SubscriptionQuery{Status: status})
+}
+
+// GetSubscriptionsForUser retrieves all subscriptions whose condition contains the user ID, eg: as broadcaster,
+// moderator, or raid target. Use it to find everything to remove when offboarding a broadcaster.
+// Automatically handles pagination.
+//
+// Returns subscriptions and an error (if any).
+func (c *Client) GetSubscriptionsForUser(userID string) (subscriptions []Subscription, err error) {
+	if c.subscriptionCache.ttl > 0 {
+		return c.filterCachedSubscriptions(context.Background(), func(sub Subscription) bool {
+			return sub.Condition.hasUser(userID)
+		})
+	}
+	return c.fetchSubscriptions(context.Background(), SubscriptionQuery{UserID: userID})
+}
+
+// MigrateSubscriptions recreates the enabled webhook subscriptions that match with the current webhook URL and secret,
+// eg: after SetWebhookURL or RotateWebhookSecret. If match is nil, the subscriptions owned by this client (see
+// OwnsSubscription) whose callback differs from the current webhook URL are migrated, so subscriptions of other
+// services sharing the Client-ID are left alone. A match given by the caller should check OwnsSubscription as well.
+//
+// Twitch doesn't allow two subscriptions with the same type and condition, so each subscription is removed before
+// it is recreated. Notifications sent in between are missed. A new subscription is assigned to the tenant of the one
+// it replaces (see Client.Tenant). Returns the IDs of the new subscriptions, and stops at the first error. Its Helix
+// requests have PriorityBackground.
+func (c *Client) MigrateSubscriptions(match func(Subscription) bool) (ids []string, err error) {
+	url := c.GetWebhookURL()
+	background := c.Background()
+	subs, err := background.GetSubscriptionsByStatus("enabled")
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		if sub.Transport.Method != "webhook" {
+			continue
+		}
+		if match == nil {
+			owned, err := c.OwnsSubscription(sub)
+			if err != nil {
+				return ids, err
+			}
+			if !owned || sub.Transport.Callback == url {
+				continue
+			}
+		} else if !match(sub) {
+			continue
+		}
+		c.logger.Info("Migrating subscription", "subscription_id", sub.ID, "type", sub.Type)
+		if err := background.RemoveSubscription(sub.ID); err != nil {
+			return ids, err
+		}
+		id, err := background.AddSubscription(sub.Type, sub.Version, sub.Condition)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+		if err := c.reassignTenant(sub.ID, id); err != nil {
+			return ids, err
+		}
+	}
+	return ids, nil
 }
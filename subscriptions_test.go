@@ -0,0 +1,307 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestSubscriptionCache(t *testing.T) {
+	c := newClient(ClientConfig{SubscriptionCacheTTL: time.Hour})
+	fetches := 0
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		switch r.Method {
+		case http.MethodGet:
+			fetches++
+			return jsonResponse(200, `{"data":[{"id":"1","type":"stream.online","status":"enabled"},`+
+				`{"id":"2","type":"stream.offline","status":"enabled"}],"pagination":{}}`), nil
+		case http.MethodDelete:
+			return jsonResponse(204, ""), nil
+		}
+		return jsonResponse(500, ""), nil
+	})}
+
+	if _, err := c.GetSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+	subs, err := c.GetSubscriptionsByType("stream.offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].ID != "2" {
+		t.Fatalf("Expected subscription 2, got %+v", subs)
+	}
+	if fetches != 1 {
+		t.Fatalf("Expected 1 fetch, got %d", fetches)
+	}
+
+	if err := c.RemoveSubscription("2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetSubscriptionsByStatus("enabled"); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Fatalf("Expected removing a subscription to invalidate the cache, got %d fetches", fetches)
+	}
+}
+
+func TestMigrateSubscriptions(t *testing.T) {
+	c := newClient(ClientConfig{WebhookURL: "https://old.example.com/eventsub"})
+	var removed, created []string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		switch r.Method {
+		case http.MethodGet:
+			return jsonResponse(200, `{"data":[`+
+				`{"id":"1","type":"stream.online","version":"1","status":"enabled","transport":{"method":"webhook","callback":"https://old.example.com/eventsub"}},`+
+				`{"id":"2","type":"stream.offline","version":"1","status":"enabled","transport":{"method":"webhook","callback":"https://new.example.com/eventsub"}},`+
+				// Another service sharing the Client-ID
+				`{"id":"4","type":"stream.online","version":"1","status":"enabled","transport":{"method":"webhook","callback":"https://other.example.com/eventsub"}}`+
+				`],"pagination":{}}`), nil
+		case http.MethodDelete:
+			removed = append(removed, r.URL.Query().Get("id"))
+			return jsonResponse(204, ""), nil
+		case http.MethodPost:
+			var req SubscriptionRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			created = append(created, req.Transport.Callback)
+			// Twitch verifies the subscription before the response arrives
+			c.verifications.verified("3")
+			return jsonResponse(202, `{"data":[{"id":"3","type":"stream.online","version":"1","status":"webhook_callback_verification_pending"}]}`), nil
+		}
+		return jsonResponse(500, ""), nil
+	})}
+
+	c.registry.Assign("1", "tenant")
+	c.SetWebhookURL("https://new.example.com/eventsub")
+	ids, err := c.MigrateSubscriptions(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != "3" {
+		t.Fatalf("Expected new subscription 3, got %v", ids)
+	}
+	if len(removed) != 1 || removed[0] != "1" {
+		t.Fatalf("Expected subscription 1 to be removed, got %v", removed)
+	}
+	if len(created) != 1 || created[0] != "https://new.example.com/eventsub" {
+		t.Fatalf("Expected subscription to be created with the new URL, got %v", created)
+	}
+	if subs, _ := c.Tenant("tenant").Subscriptions(); len(subs) != 1 || subs[0] != "3" {
+		t.Fatalf("Expected the tenant to own the new subscription, got %v", subs)
+	}
+}
+
+func TestEphemeralClose(t *testing.T) {
+	c := newClient(ClientConfig{WebhookURL: "https://example.com/eventsub", Ephemeral: true})
+	var removed []string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		switch r.Method {
+		case http.MethodPost:
+			c.verifications.verified("1")
+			return jsonResponse(202, `{"data":[{"id":"1","type":"stream.online","version":"1","status":"webhook_callback_verification_pending"}]}`), nil
+		case http.MethodDelete:
+			removed = append(removed, r.URL.Query().Get("id"))
+			return jsonResponse(204, ""), nil
+		}
+		return jsonResponse(500, ""), nil
+	})}
+
+	if _, err := c.AddSubscription("stream.online", "1", Condition{BroadcasterUserID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != "1" {
+		t.Fatalf("Expected subscription 1 to be removed, got %v", removed)
+	}
+	if err := c.Close(); err != nil || len(removed) != 1 {
+		t.Fatalf("Expected second Close to do nothing, removed %v (%v)", removed, err)
+	}
+}
+
+func TestGetSubscriptionsForUser(t *testing.T) {
+	c := newClient(ClientConfig{})
+	var queries []string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("after") == "" {
+			return jsonResponse(200, `{"data":[{"id":"1","condition":{"broadcaster_user_id":"1234"}}],"pagination":{"cursor":"abc"}}`), nil
+		}
+		return jsonResponse(200, `{"data":[{"id":"2","condition":{"to_broadcaster_user_id":"1234"}}],"pagination":{}}`), nil
+	})}
+
+	subs, err := c.GetSubscriptionsForUser("1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("Expected 2 subscriptions, got %+v", subs)
+	}
+	if len(queries) != 2 || queries[0] != "user_id=1234" || queries[1] != "user_id=1234&after=abc" {
+		t.Fatalf("Unexpected queries %v", queries)
+	}
+}
+
+func TestRemoveBroadcaster(t *testing.T) {
+	registry := NewMemoryRegistry()
+	registry.Assign("1", "tenant")
+	c := newClient(ClientConfig{Registry: registry})
+	var removed []string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodDelete {
+			id := r.URL.Query().Get("id")
+			if id == "3" {
+				return jsonResponse(404, ""), nil
+			}
+			removed = append(removed, id)
+			return jsonResponse(204, ""), nil
+		}
+		return jsonResponse(200, `{"data":[`+
+			`{"id":"1","condition":{"broadcaster_user_id":"1234"}},`+
+			`{"id":"2","condition":{"broadcaster_user_id":"1","moderator_user_id":"1234"}},`+
+			`{"id":"3","condition":{"to_broadcaster_user_id":"1234"}},`+
+			`{"id":"4","condition":{"broadcaster_user_id":"5678"}}`+
+			`],"pagination":{}}`), nil
+	})}
+
+	if err := c.RemoveBroadcaster("1234"); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 || removed[0] != "1" || removed[1] != "2" {
+		t.Fatalf("Expected subscriptions referencing the user to be removed, got %v", removed)
+	}
+	if _, ok, _ := registry.Owner("1"); ok {
+		t.Fatal("Expected removed subscription to be forgotten by the registry")
+	}
+}
+
+func TestConditionEqual(t *testing.T) {
+	var fromHelix Condition
+	if err := json.Unmarshal([]byte(`{"broadcaster_user_id":"1","reward_id":"9a3f0c1e-22b4-4b5e-8d6f-0e1a2b3c4d5e"}`), &fromHelix); err != nil {
+		t.Fatal(err)
+	}
+	for _, condition := range []Condition{
+		{BroadcasterUserID: "1", RewardID: "9a3f0c1e-22b4-4b5e-8d6f-0e1a2b3c4d5e"},
+		{BroadcasterUserID: "1", RewardID: "9A3F0C1E-22B4-4B5E-8D6F-0E1A2B3C4D5E"},
+	} {
+		if !condition.Equal(fromHelix) {
+			t.Errorf("Expected %+v to equal %+v", condition, fromHelix)
+		}
+	}
+	if (Condition{BroadcasterUserID: "1"}).Equal(fromHelix) {
+		t.Error("Expected conditions with different rewards to differ")
+	}
+	if (Condition{BroadcasterUserID: "1"}).Equal(Condition{BroadcasterUserID: "2"}) {
+		t.Error("Expected conditions of different broadcasters to differ")
+	}
+}
+
+func TestConditionEqualFor(t *testing.T) {
+	// Twitch doesn't return the moderator of a stream.online condition
+	requested := Condition{BroadcasterUserID: "1", ModeratorUserID: "2"}
+	returned := Condition{BroadcasterUserID: "1"}
+	if !requested.EqualFor(TypeStreamOnline, returned) {
+		t.Error("Expected conditions that differ in fields stream.online doesn't use to be equal")
+	}
+	if requested.EqualFor(TypeChannelFollow, returned) {
+		t.Error("Expected conditions of different moderators to differ for channel.follow")
+	}
+	if requested.EqualFor("unknown.type", returned) {
+		t.Error("Expected every field to be compared for unknown types")
+	}
+
+	raid := Condition{FromBroadcasterUserID: "1", BroadcasterUserID: "1"}
+	if !raid.EqualFor(TypeChannelRaid, Condition{FromBroadcasterUserID: "1"}) || raid.EqualFor(TypeChannelRaid, Condition{ToBroadcasterUserID: "1"}) {
+		t.Error("Expected channel.raid conditions to compare the raid source and target only")
+	}
+	reward := Condition{BroadcasterUserID: "1", RewardID: "9A3F0C1E-22B4-4B5E-8D6F-0E1A2B3C4D5E"}
+	if !reward.EqualFor(TypeChannelChannelPointsCustomRewardUpdate, Condition{BroadcasterUserID: "1", RewardID: "9a3f0c1e-22b4-4b5e-8d6f-0e1a2b3c4d5e"}) {
+		t.Error("Expected reward IDs to be compared in their normalized form")
+	}
+	if !reward.EqualFor(TypeChannelChannelPointsCustomRewardAdd, Condition{BroadcasterUserID: "1"}) {
+		t.Error("Expected the reward ID to be ignored for channel.channel_points_custom_reward.add")
+	}
+}
+
+func TestConditionFields(t *testing.T) {
+	for Type := range subscriptionVersions {
+		if len(conditionFields[Type]) == 0 {
+			t.Errorf("No condition fields for %s", Type)
+		}
+	}
+	tags := map[string]bool{}
+	conditionType := reflect.TypeOf(Condition{})
+	for i := 0; i < conditionType.NumField(); i++ {
+		tags[strings.TrimSuffix(conditionType.Field(i).Tag.Get("json"), ",omitempty")] = true
+	}
+	for Type, fields := range conditionFields {
+		for _, field := range fields {
+			if !tags[field] {
+				t.Errorf("Unknown condition field %s of %s", field, Type)
+			}
+		}
+	}
+}
+
+func TestRewardIDJSON(t *testing.T) {
+	for body, want := range map[string]RewardID{
+		`{"reward_id":"abc"}`: "abc",
+		`{"reward_id":123}`:   "123",
+		`{"reward_id":null}`:  "",
+		`{}`:                  "",
+	} {
+		var condition Condition
+		if err := json.Unmarshal([]byte(body), &condition); err != nil {
+			t.Fatalf("Unmarshal %s: %v", body, err)
+		}
+		if condition.RewardID != want {
+			t.Errorf("Unmarshal %s: expected %q, got %q", body, want, condition.RewardID)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"reward_id":true}`), &Condition{}); err == nil {
+		t.Error("Expected error for a boolean reward_id")
+	}
+
+	body, _ := json.Marshal(Condition{BroadcasterUserID: "1", RewardID: "123"})
+	if string(body) != `{"broadcaster_user_id":"1","reward_id":"123"}` {
+		t.Errorf("Unexpected JSON %s", body)
+	}
+	body, _ = json.Marshal(Condition{BroadcasterUserID: "1"})
+	if string(body) != `{"broadcaster_user_id":"1"}` {
+		t.Errorf("Expected empty reward ID to be omitted, got %s", body)
+	}
+}
+
+func TestAddSubscriptionRewardIDType(t *testing.T) {
+	c := newClient(ClientConfig{})
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("Expected no request for an invalid condition")
+		return nil, nil
+	})}
+	_, err := c.AddSubscription(TypeChannelFollow, "2", Condition{BroadcasterUserID: "1", ModeratorUserID: "1", RewardID: "abc"})
+	var conditionErr *InvalidConditionError
+	if !errors.As(err, &conditionErr) || conditionErr.Type != TypeChannelFollow || conditionErr.Field != "reward_id" {
+		t.Fatalf("Expected InvalidConditionError for a reward_id on channel.follow, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// Registry records which tenant owns each subscription, see Client.Tenant.
+// Implement this to persist ownership to a database, the default MemoryRegistry is lost on restart.
+type Registry interface {
+	// Assign records that a subscription belongs to a tenant.
+	Assign(subscriptionID, tenantID string) error
+	// Owner returns the tenant a subscription belongs to, or false if it was never assigned.
+	Owner(subscriptionID string) (tenantID string, ok bool, err error)
+	// Subscriptions returns the IDs of all subscriptions assigned to a tenant.
+	Subscriptions(tenantID string) ([]string, error)
+	// Forget removes a subscription from the registry.
+	Forget(subscriptionID string) error
+}
+
+// MemoryRegistry is an in-memory Registry. It is the default if ClientConfig.Registry is nil.
+// It also implements PendingJournal.
+type MemoryRegistry struct {
+	mu      sync.RWMutex
+	owners  map[string]string
+	pending map[string]PendingSubscription
+}
+
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		owners: make(map[string]string),
+	}
+}
+
+func (m *MemoryRegistry) Assign(subscriptionID, tenantID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.owners[subscriptionID] = tenantID
+	return nil
+}
+
+func (m *MemoryRegistry) Owner(subscriptionID string) (string, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tenantID, ok := m.owners[subscriptionID]
+	return tenantID, ok, nil
+}
+
+func (m *MemoryRegistry) Subscriptions(tenantID string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var ids []string
+	for subscriptionID, owner := range m.owners {
+		if owner == tenantID {
+			ids = append(ids, subscriptionID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *MemoryRegistry) Forget(subscriptionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.owners, subscriptionID)
+	return nil
+}
+
+// Tenant is a view of the client for a single tenant, eg: a broadcaster that connected their channel to your app.
+// Notifications of subscriptions created through a tenant are handled by the tenant's handlers, falling back to the
+// handlers registered on the client.
+type Tenant struct {
+	c  *Client
+	id string
+}
+
+// Tenant returns the tenant with the given ID. Tenants don't need to be created, any ID can be used.
+//
+//	tenant := client.Tenant(broadcasterID)
+//	tenant.On("channel.follow", func(event json.RawMessage) {
+//		notifyDashboard(broadcasterID, event)
+//	})
+//	_, err := tenant.AddSubscription("channel.follow", "2", twitchwh.Condition{
+//		BroadcasterUserID: broadcasterID,
+//		ModeratorUserID:   broadcasterID,
+//	})
+func (c *Client) Tenant(id string) *Tenant {
+	return &Tenant{c: c, id: id}
+}
+
+// ID returns the ID of the tenant.
+func (t *Tenant) ID() string {
+	return t.id
+}
+
+// On is like Client.On, but only for the tenant's subscriptions.
+func (t *Tenant) On(event string, handler func(json.RawMessage)) {
+	t.OnContext(event, func(_ context.Context, event json.RawMessage) error {
+		handler(event)
+		return nil
+	})
+}
+
+// OnContext is like Client.OnContext, but only for the tenant's subscriptions.
+func (t *Tenant) OnContext(event string, handler ContextHandler) {
+	t.c.handlersMu.Lock()
+	defer t.c.handlersMu.Unlock()
+	if t.c.tenantHandlers == nil {
+		t.c.tenantHandlers = make(map[string]map[string]ContextHandler)
+	}
+	if t.c.tenantHandlers[t.id] == nil {
+		t.c.tenantHandlers[t.id] = make(map[string]ContextHandler)
+	}
+	t.c.tenantHandlers[t.id][event] = handler
+}
+
+// AddSubscription is like Client.AddSubscription, and assigns the subscription to the tenant.
+func (t *Tenant) AddSubscription(Type string, version string, condition Condition) (string, error) {
+	id, err := t.c.AddSubscription(Type, version, condition)
+	if err != nil {
+		return "", err
+	}
+	if err := t.c.registry.Assign(id, t.id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// reassignTenant assigns a recreated subscription to the tenant of the subscription it replaced, if it had one.
+func (c *Client) reassignTenant(oldID, newID string) error {
+	tenantID, ok, err := c.registry.Owner(oldID)
+	if err != nil || !ok {
+		return err
+	}
+	if err := c.registry.Assign(newID, tenantID); err != nil {
+		return err
+	}
+	return c.registry.Forget(oldID)
+}
+
+// Subscriptions returns the IDs of the tenant's subscriptions.
+func (t *Tenant) Subscriptions() ([]string, error) {
+	return t.c.registry.Subscriptions(t.id)
+}
+
+// RemoveTenant deletes all subscriptions of a tenant and its handlers, eg: when a broadcaster disconnects their channel.
+// Subscriptions that no longer exist are skipped. Returns the errors of subscriptions that could not be removed,
+// they stay in the registry so RemoveTenant can be retried.
+func (c *Client) RemoveTenant(id string) error {
+	c.handlersMu.Lock()
+	delete(c.tenantHandlers, id)
+	c.handlersMu.Unlock()
+
+	subscriptionIDs, err := c.registry.Subscriptions(id)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, subscriptionID := range subscriptionIDs {
+		err := c.RemoveSubscription(subscriptionID)
+		var notFound *SubscriptionNotFoundError
+		if err != nil && !errors.As(err, &notFound) {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, c.registry.Forget(subscriptionID))
+	}
+	return errors.Join(errs...)
+}
+
+// notificationHandler returns the handler for a notification: the handler of the tenant owning the subscription
+// if it has one for the type, otherwise the client's handler.
+func (c *Client) notificationHandler(n Notification) (ContextHandler, bool) {
+	c.handlersMu.RLock()
+	hasTenants := len(c.tenantHandlers) > 0
+	c.handlersMu.RUnlock()
+	if hasTenants {
+		tenantID, ok, err := c.registry.Owner(n.Subscription.ID)
+		if err != nil {
+			c.reportError("Could not look up subscription owner", err, "subscription_id", n.Subscription.ID)
+		} else if ok {
+			c.handlersMu.RLock()
+			handler, ok := c.tenantHandlers[tenantID][n.Subscription.Type]
+			c.handlersMu.RUnlock()
+			if ok {
+				return handler, true
+			}
+		}
+	}
+	return c.handler(n.Subscription.Type)
+}
@@ -0,0 +1,59 @@
+package twitchwh
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTenantHandlers(t *testing.T) {
+	c := newClient(ClientConfig{})
+	var handled []string
+	c.On("stream.online", func(event json.RawMessage) {
+		handled = append(handled, "client")
+	})
+	tenant := c.Tenant("1971641")
+	tenant.On("stream.online", func(event json.RawMessage) {
+		handled = append(handled, "tenant")
+	})
+	c.registry.Assign("owned", tenant.ID())
+
+	for _, id := range []string{"owned", "other"} {
+		n := Notification{Subscription: Subscription{ID: id, Type: "stream.online"}}
+		if err := c.processNotification(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(handled) != 2 || handled[0] != "tenant" || handled[1] != "client" {
+		t.Fatalf("Expected [tenant client], got %v", handled)
+	}
+}
+
+func TestRemoveTenant(t *testing.T) {
+	c := newClient(ClientConfig{})
+	var removed []string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		id := r.URL.Query().Get("id")
+		removed = append(removed, id)
+		if id == "gone" {
+			return jsonResponse(404, ""), nil
+		}
+		return jsonResponse(204, ""), nil
+	})}
+	c.registry.Assign("a", "tenant")
+	c.registry.Assign("gone", "tenant")
+	c.registry.Assign("b", "other")
+
+	if err := c.RemoveTenant("tenant"); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 {
+		t.Fatalf("Expected 2 removals, got %v", removed)
+	}
+	if ids, _ := c.Tenant("tenant").Subscriptions(); len(ids) != 0 {
+		t.Fatalf("Expected tenant to have no subscriptions, got %v", ids)
+	}
+	if ids, _ := c.Tenant("other").Subscriptions(); len(ids) != 1 {
+		t.Fatalf("Expected other tenant to keep its subscription, got %v", ids)
+	}
+}
@@ -1,16 +1,16 @@
 package twitchwh
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 )
 
-const oauthURL = "https://id.twitch.tv/oauth2"
-const tokenURL = oauthURL + "/token"
-const validateURL = oauthURL + "/validate"
+const defaultOAuthURL = "https://id.twitch.tv/oauth2"
 
 func (c *Client) generateToken(clientID string, secret string) (token string, err error) {
 	values := url.Values{
@@ -19,7 +19,7 @@ func (c *Client) generateToken(clientID string, secret string) (token string, er
 		"grant_type":    {"client_credentials"},
 	}
 
-	res, err := c.httpClient.PostForm(tokenURL, values)
+	res, err := c.httpClient.PostForm(c.oauthURL+"/token", values)
 	if err != nil {
 		return "", &InternalError{"Could not send request", err}
 	}
@@ -52,8 +52,39 @@ func (c *Client) generateToken(clientID string, secret string) (token string, er
 	return jsonBody.AccessToken, nil
 }
 
+// refreshToken generates a new app access token and replaces the current one.
+func (c *Client) refreshToken() error {
+	c.logger.Info("Token invalid, generating a new one")
+	if err := c.RefreshSecrets(context.Background()); err != nil {
+		// The current client secret may still work
+		c.reportError("Could not refresh secrets", err)
+	}
+	token, err := c.generateToken(c.clientID, c.getClientSecret())
+	if err != nil {
+		c.health.setTokenValid(false)
+		return err
+	}
+	c.setToken(token)
+	c.metrics.TokenRefreshed()
+	return nil
+}
+
+func (c *Client) getToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.token
+}
+
+// setToken replaces the current token and marks it as valid.
+func (c *Client) setToken(token string) {
+	c.tokenMu.Lock()
+	c.token = token
+	c.tokenMu.Unlock()
+	c.health.setTokenValid(true)
+}
+
 func (c *Client) validateToken(token string) (bool, error) {
-	req, err := http.NewRequest("GET", validateURL, nil)
+	req, err := http.NewRequest("GET", c.oauthURL+"/validate", nil)
 	if err != nil {
 		return false, &InternalError{"Could not create request", err}
 	}
@@ -71,3 +102,38 @@ func (c *Client) validateToken(token string) (bool, error) {
 		return false, nil
 	}
 }
+
+// startToken generates the app access token and starts validating it every hour, refreshing it if Twitch no longer
+// accepts it.
+func (c *Client) startToken() error {
+	c.logger.Debug("Generating token")
+	token, err := c.generateToken(c.clientID, c.getClientSecret())
+	if err != nil {
+		return err
+	}
+	c.logger.Debug("Token generated")
+	c.setToken(token)
+	c.goBackground(func(ctx context.Context) {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			valid, err := c.validateToken(c.getToken())
+			if err != nil {
+				c.reportError("Could not validate token", err)
+				continue
+			}
+			c.health.setTokenValid(valid)
+			if !valid {
+				if err := c.refreshToken(); err != nil {
+					c.reportError("Could not generate token", err)
+				}
+			}
+		}
+	})
+	return nil
+}
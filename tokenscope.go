@@ -0,0 +1,89 @@
+package twitchwh
+
+import "context"
+
+// TokenProvider supplies the access token of the Helix requests made through Client.WithToken, eg: a user access
+// token kept current by your OAuth flow. The token must be issued to the client's Client-ID.
+type TokenProvider interface {
+	// Token returns the access token to send. refresh is true after Helix rejected the previous token with
+	// 401 Unauthorized, the provider should return a new token then.
+	Token(ctx context.Context, refresh bool) (string, error)
+}
+
+// StaticToken is a TokenProvider that always returns the same token. Requests fail with UnauthorizedError once
+// Twitch stops accepting it.
+type StaticToken string
+
+// Token implements TokenProvider.
+func (t StaticToken) Token(ctx context.Context, refresh bool) (string, error) {
+	if refresh {
+		return "", &UnauthorizedError{}
+	}
+	return string(t), nil
+}
+
+type tokenProviderKey struct{}
+
+// withTokenProvider returns a context whose Helix requests are sent with the provider's token instead of the app
+// access token.
+func withTokenProvider(ctx context.Context, provider TokenProvider) context.Context {
+	return context.WithValue(ctx, tokenProviderKey{}, provider)
+}
+
+func tokenProviderFromContext(ctx context.Context) TokenProvider {
+	provider, _ := ctx.Value(tokenProviderKey{}).(TokenProvider)
+	return provider
+}
+
+// TokenScope is a view of the client whose Helix requests are sent with the token of a TokenProvider instead of the
+// app access token. It shares everything else with the client: the webhook handler, handlers and sinks,
+// deduplication, and the registry. Use it to mix app token and user token subscriptions on one endpoint.
+//
+// Subscriptions are listed from Helix directly, bypassing ClientConfig.SubscriptionCacheTTL, since the cache holds
+// the subscriptions visible to the app access token.
+type TokenScope struct {
+	c        *Client
+	provider TokenProvider
+}
+
+// WithToken returns a view of the client that authenticates with the provider's token.
+//
+//	user := client.WithToken(twitchwh.StaticToken(userAccessToken))
+//	_, err := user.AddSubscription("channel.chat.message", "1", twitchwh.Condition{
+//		BroadcasterUserID: broadcasterID,
+//		UserID:            botID,
+//	})
+//
+// A HelixAPI set with ClientConfig.Helix is used as is and doesn't see the provider.
+func (c *Client) WithToken(provider TokenProvider) *TokenScope {
+	return &TokenScope{c: c, provider: provider}
+}
+
+func (s *TokenScope) ctx() context.Context {
+	return withTokenProvider(context.Background(), s.provider)
+}
+
+// AddSubscription is like Client.AddSubscription.
+func (s *TokenScope) AddSubscription(Type string, version string, condition Condition) (string, error) {
+	return s.c.createSubscription(s.ctx(), Type, version, condition)
+}
+
+// RemoveSubscription is like Client.RemoveSubscription.
+func (s *TokenScope) RemoveSubscription(id string) error {
+	return s.c.deleteSubscription(s.ctx(), id)
+}
+
+// GetSubscriptions is like Client.GetSubscriptions.
+func (s *TokenScope) GetSubscriptions() ([]Subscription, error) {
+	return s.c.fetchSubscriptions(s.ctx(), SubscriptionQuery{})
+}
+
+// GetSubscriptionsByType is like Client.GetSubscriptionsByType.
+func (s *TokenScope) GetSubscriptionsByType(Type string) ([]Subscription, error) {
+	return s.c.fetchSubscriptions(s.ctx(), SubscriptionQuery{Type: Type})
+}
+
+// GetSubscriptionsByStatus is like Client.GetSubscriptionsByStatus.
+func (s *TokenScope) GetSubscriptionsByStatus(status string) ([]Subscription, error) {
+	return s.c.fetchSubscriptions(s.ctx(), SubscriptionQuery{Status: status})
+}
@@ -0,0 +1,59 @@
+package twitchwh
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type refreshingToken struct {
+	refreshed int
+}
+
+func (p *refreshingToken) Token(ctx context.Context, refresh bool) (string, error) {
+	if refresh {
+		p.refreshed++
+		return "user-new", nil
+	}
+	return "user-old", nil
+}
+
+func TestWithToken(t *testing.T) {
+	c := newClient(ClientConfig{})
+	c.setToken("app")
+	var tokens []string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		token := r.Header.Get("Authorization")
+		tokens = append(tokens, token)
+		if token == "Bearer user-old" {
+			return jsonResponse(401, ""), nil
+		}
+		return jsonResponse(200, `{"data":[{"id":"1","type":"stream.online"}],"pagination":{}}`), nil
+	})}
+
+	provider := &refreshingToken{}
+	subs, err := c.WithToken(provider).GetSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || provider.refreshed != 1 {
+		t.Fatalf("Expected the provider to refresh its token once, got %+v, %d refreshes", subs, provider.refreshed)
+	}
+	if len(tokens) != 2 || tokens[1] != "Bearer user-new" {
+		t.Fatalf("Unexpected tokens %v", tokens)
+	}
+
+	tokens = nil
+	if _, err := c.GetSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0] != "Bearer app" {
+		t.Fatalf("Expected the client to keep using the app token, got %v", tokens)
+	}
+
+	var uErr *UnauthorizedError
+	if _, err := c.WithToken(StaticToken("user-old")).GetSubscriptions(); !errors.As(err, &uErr) {
+		t.Fatalf("Expected UnauthorizedError for a rejected static token, got %v", err)
+	}
+}
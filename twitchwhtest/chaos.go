@@ -0,0 +1,151 @@
+// Package twitchwhtest provides utilities for testing applications built on twitchwh.
+package twitchwhtest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Header names used by EventSub, see https://dev.twitch.tv/docs/eventsub/handling-webhook-events/#list-of-request-headers
+const (
+	HeaderMessageID        = "Twitch-Eventsub-Message-Id"
+	HeaderMessageTimestamp = "Twitch-Eventsub-Message-Timestamp"
+	HeaderMessageSignature = "Twitch-Eventsub-Message-Signature"
+	HeaderMessageType      = "Twitch-Eventsub-Message-Type"
+)
+
+// ChaosOptions configures Chaos.
+type ChaosOptions struct {
+	// Probability (0 to 1) that a notification is delivered a second time.
+	DuplicateRate float64
+	// Every delivery is delayed by a random duration up to MaxDelay, so notifications arrive out of order.
+	MaxDelay time.Duration
+	// If set, duplicates get a new message ID and are re-signed with this secret, so they pass the client's dedup
+	// and reach the handlers, like redeliveries after the dedup store lost its state. Otherwise duplicates keep
+	// their message ID and test the dedup store.
+	Secret string
+	// Seed of the random source, for reproducible runs. A random seed is used if zero.
+	Seed int64
+}
+
+// Chaos wraps the handler of a client (eg: client.Handler) and delivers notifications to it with injected duplicates,
+// delays, and reordering, to validate that handlers are idempotent and order tolerant before running in production.
+// Notifications are acknowledged with 204 immediately and delivered in the background. Other messages, like
+// verification challenges, are passed through unchanged.
+//
+// It is meant for tests and staging environments:
+//
+//	chaos := twitchwhtest.Chaos(http.HandlerFunc(client.Handler), twitchwhtest.ChaosOptions{
+//		DuplicateRate: 0.2,
+//		MaxDelay:      2 * time.Second,
+//		Secret:        webhookSecret,
+//	})
+//	http.Handle("/eventsub", chaos)
+//
+// Call Wait to wait for all scheduled deliveries.
+func Chaos(next http.Handler, options ChaosOptions) *ChaosHandler {
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &ChaosHandler{next: next, options: options, rand: mathrand.New(mathrand.NewSource(seed))}
+}
+
+// ChaosHandler is the handler returned by Chaos.
+type ChaosHandler struct {
+	next    http.Handler
+	options ChaosOptions
+	randMu  sync.Mutex
+	rand    *mathrand.Rand
+	wg      sync.WaitGroup
+
+	statsMu    sync.Mutex
+	delivered  int
+	duplicated int
+}
+
+func (h *ChaosHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(HeaderMessageType) != "notification" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	header := r.Header.Clone()
+	h.schedule(header, body)
+	if h.chance(h.options.DuplicateRate) {
+		duplicate := header.Clone()
+		if h.options.Secret != "" {
+			messageID := newMessageID()
+			duplicate.Set(HeaderMessageID, messageID)
+			duplicate.Set(HeaderMessageSignature, twitchwh.Signature(h.options.Secret, messageID, duplicate.Get(HeaderMessageTimestamp), body))
+		}
+		h.statsMu.Lock()
+		h.duplicated++
+		h.statsMu.Unlock()
+		h.schedule(duplicate, body)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// schedule delivers a request to the wrapped handler after a random delay.
+func (h *ChaosHandler) schedule(header http.Header, body []byte) {
+	delay := h.delay()
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		time.Sleep(delay)
+		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		r.Header = header
+		h.next.ServeHTTP(httptest.NewRecorder(), r)
+		h.statsMu.Lock()
+		h.delivered++
+		h.statsMu.Unlock()
+	}()
+}
+
+func (h *ChaosHandler) chance(p float64) bool {
+	h.randMu.Lock()
+	defer h.randMu.Unlock()
+	return h.rand.Float64() < p
+}
+
+func (h *ChaosHandler) delay() time.Duration {
+	if h.options.MaxDelay <= 0 {
+		return 0
+	}
+	h.randMu.Lock()
+	defer h.randMu.Unlock()
+	return time.Duration(h.rand.Int63n(int64(h.options.MaxDelay)))
+}
+
+// Wait blocks until all scheduled deliveries were made.
+func (h *ChaosHandler) Wait() {
+	h.wg.Wait()
+}
+
+// Stats returns the number of deliveries made to the wrapped handler, and how many of the scheduled deliveries
+// were injected duplicates.
+func (h *ChaosHandler) Stats() (delivered int, duplicated int) {
+	h.statsMu.Lock()
+	defer h.statsMu.Unlock()
+	return h.delivered, h.duplicated
+}
+
+func newMessageID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,47 @@
+package twitchwhtest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestChaos(t *testing.T) {
+	const secret = "0123456789abcdef"
+	var mu sync.Mutex
+	var received []string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r.Header.Get(HeaderMessageID))
+	})
+	chaos := Chaos(next, ChaosOptions{DuplicateRate: 1, MaxDelay: 10 * time.Millisecond, Secret: secret, Seed: 1})
+
+	body := []byte(`{"event":{}}`)
+	for i := 0; i < 10; i++ {
+		messageID := strconv.Itoa(i)
+		timestamp := time.Now().UTC().Format(time.RFC3339)
+		r := httptest.NewRequest(http.MethodPost, "/eventsub", bytes.NewReader(body))
+		r.Header.Set(HeaderMessageType, "notification")
+		r.Header.Set(HeaderMessageID, messageID)
+		r.Header.Set(HeaderMessageTimestamp, timestamp)
+		r.Header.Set(HeaderMessageSignature, twitchwh.Signature(secret, messageID, timestamp, body))
+		w := httptest.NewRecorder()
+		chaos.ServeHTTP(w, r)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+	}
+	chaos.Wait()
+
+	delivered, duplicated := chaos.Stats()
+	if delivered != 20 || duplicated != 10 || len(received) != 20 {
+		t.Fatalf("Expected 20 deliveries with 10 duplicates, got %d with %d", delivered, duplicated)
+	}
+}
@@ -0,0 +1,42 @@
+package twitchwhtest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Headers the Twitch CLI sends in addition to the ones Twitch documents.
+const (
+	HeaderMessageRetry        = "Twitch-Eventsub-Message-Retry"
+	HeaderSubscriptionType    = "Twitch-Eventsub-Subscription-Type"
+	HeaderSubscriptionVersion = "Twitch-Eventsub-Subscription-Version"
+)
+
+// CLIRequest builds a notification request for callback the way `twitch event trigger -F callback -s secret` of the
+// Twitch CLI sends it, to test that a handler accepts CLI events without installing the CLI. Defaults of sub are
+// filled in like in Notify, except the transport callback, which the CLI sets to "null".
+//
+//	client, _ := twitchwh.NewReceiver("local-test-secret", twitchwh.WithTwitchCLI())
+//	req, _ := twitchwhtest.CLIRequest("/eventsub", "local-test-secret",
+//		twitchwh.Subscription{Type: twitchwh.TypeChannelCheer}, twitchwhtest.Sample(twitchwh.TypeChannelCheer))
+//	client.Handler(httptest.NewRecorder(), req)
+func CLIRequest(callback string, secret string, sub twitchwh.Subscription, event any) (*http.Request, error) {
+	sub, err := completeSubscription(sub, "null")
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]any{"subscription": sub, "event": event})
+	if err != nil {
+		return nil, err
+	}
+	req, err := signedRequest(callback, "notification", secret, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(HeaderMessageRetry, "0")
+	req.Header.Set(HeaderSubscriptionType, sub.Type)
+	req.Header.Set(HeaderSubscriptionVersion, sub.Version)
+	return req, nil
+}
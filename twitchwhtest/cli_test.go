@@ -0,0 +1,51 @@
+package twitchwhtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+	"github.com/macluxHD/twitchwh/events"
+)
+
+func TestCLIRequest(t *testing.T) {
+	client, err := twitchwh.NewReceiver("local-test-secret", twitchwh.WithTwitchCLI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan events.ChannelChatMessageEvent, 1)
+	twitchwh.OnEvent(client, twitchwh.TypeChannelChatMessage, func(event events.ChannelChatMessageEvent) {
+		received <- event
+	})
+	server := httptest.NewServer(http.HandlerFunc(client.Handler))
+	defer server.Close()
+
+	req, err := CLIRequest(server.URL+"/eventsub", "local-test-secret", twitchwh.Subscription{
+		Type:      twitchwh.TypeChannelChatMessage,
+		Condition: twitchwh.Condition{BroadcasterUserID: "1337", UserID: "1337"},
+	}, Sample(twitchwh.TypeChannelChatMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get(HeaderSubscriptionType) != twitchwh.TypeChannelChatMessage || req.Header.Get(HeaderSubscriptionVersion) != "1" {
+		t.Fatalf("Unexpected headers %v", req.Header)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", resp.StatusCode)
+	}
+	select {
+	case event := <-received:
+		if event.Message.Bits() != 100 {
+			t.Fatalf("Unexpected event %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handler was not called")
+	}
+}
@@ -0,0 +1,98 @@
+package twitchwhtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// HarnessSecret is the webhook secret of the client created by NewHarness.
+const HarnessSecret = "twitchwhtest-secret"
+
+// Harness wires a Client, an httptest server hosting its Handler, and a mock Helix server together, so the whole
+// flow from AddSubscription through the verification challenge to notifications reaching handlers runs in-process:
+//
+//	h := twitchwhtest.NewHarness(t)
+//	h.Client.On("stream.online", func(event json.RawMessage) { ... })
+//	id, err := h.Client.AddSubscription("stream.online", "1", twitchwh.Condition{BroadcasterUserID: "1234"})
+//	...
+//	status, err := h.Notify(id, map[string]any{"broadcaster_user_id": "1234", "type": "live"})
+type Harness struct {
+	Helix  *Helix
+	Server *httptest.Server
+	Client *twitchwh.Client
+}
+
+// NewHarness starts the servers and creates the client. opts are applied before the options pointing the client at
+// the servers, so they can configure everything else. Everything is shut down when the test ends.
+func NewHarness(t testing.TB, opts ...twitchwh.Option) *Harness {
+	t.Helper()
+	h := &Harness{Helix: NewHelix()}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.Client.Handler(w, r)
+	}))
+
+	opts = append(opts,
+		twitchwh.WithHelixURL(h.Helix.HelixURL()),
+		twitchwh.WithAuthURL(h.Helix.AuthURL()),
+		twitchwh.WithWebhook(h.Server.URL, HarnessSecret),
+	)
+	client, err := twitchwh.NewClient("twitchwhtest-client-id", "twitchwhtest-client-secret", opts...)
+	if err != nil {
+		h.Helix.Close()
+		h.Server.Close()
+		t.Fatalf("twitchwhtest: could not create client: %v", err)
+	}
+	h.Client = client
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		h.Client.Shutdown(ctx)
+		h.Helix.Close()
+		h.Server.Close()
+	})
+	return h
+}
+
+// Notify sends a signed notification for the subscription with the given ID and event, as Twitch would.
+// Returns the status code of the client's response.
+func (h *Harness) Notify(subscriptionID string, event any) (int, error) {
+	return h.deliver(subscriptionID, "notification", "event", event)
+}
+
+// Revoke marks the subscription as revoked with status (eg: authorization_revoked) and sends the revocation to
+// the client. Returns the status code of the client's response.
+func (h *Harness) Revoke(subscriptionID string, status string) (int, error) {
+	h.Helix.setStatus(subscriptionID, status)
+	return h.deliver(subscriptionID, "revocation", "", nil)
+}
+
+func (h *Harness) deliver(subscriptionID string, messageType string, key string, value any) (int, error) {
+	sub, ok := h.Helix.Subscription(subscriptionID)
+	if !ok {
+		return 0, fmt.Errorf("twitchwhtest: subscription %s not found", subscriptionID)
+	}
+	payload := map[string]any{"subscription": sub}
+	if key != "" {
+		payload[key] = value
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	res, err := send(sub.Transport.Callback, messageType, h.Helix.Secret(subscriptionID), body)
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	return res.StatusCode, nil
+}
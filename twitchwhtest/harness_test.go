@@ -0,0 +1,62 @@
+package twitchwhtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestHarness(t *testing.T) {
+	h := NewHarness(t)
+	received := make(chan string, 1)
+	h.Client.On("stream.online", func(event json.RawMessage) {
+		var online struct {
+			BroadcasterUserID string `json:"broadcaster_user_id"`
+		}
+		json.Unmarshal(event, &online)
+		received <- online.BroadcasterUserID
+	})
+
+	id, err := h.Client.AddSubscription("stream.online", "1", twitchwh.Condition{BroadcasterUserID: "1234"})
+	if err != nil {
+		t.Fatalf("AddSubscription failed: %v", err)
+	}
+	// Helix updates the status after the callback responded
+	deadline := time.Now().Add(time.Second)
+	for {
+		sub, _ := h.Helix.Subscription(id)
+		if sub.Status == StatusEnabled {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected subscription to be enabled, got %s", sub.Status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	status, err := h.Notify(id, map[string]any{"broadcaster_user_id": "1234", "type": "live"})
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d (%v)", status, err)
+	}
+	select {
+	case broadcaster := <-received:
+		if broadcaster != "1234" {
+			t.Errorf("Expected broadcaster 1234, got %s", broadcaster)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Handler was not called")
+	}
+
+	if _, err := h.Client.AddSubscription("stream.online", "1", twitchwh.Condition{BroadcasterUserID: "1234"}); err == nil {
+		t.Error("Expected duplicate subscription to fail")
+	}
+	if err := h.Client.RemoveSubscription(id); err != nil {
+		t.Fatalf("RemoveSubscription failed: %v", err)
+	}
+	if len(h.Helix.Subscriptions()) != 0 {
+		t.Error("Expected subscription to be deleted")
+	}
+}
@@ -0,0 +1,240 @@
+package twitchwhtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Subscription statuses set by Helix.
+const (
+	StatusEnabled             = "enabled"
+	StatusVerificationPending = "webhook_callback_verification_pending"
+	StatusVerificationFailed  = "webhook_callback_verification_failed"
+)
+
+// Helix is an in-process mock of the Helix EventSub and OAuth APIs. Created subscriptions are verified by sending
+// a challenge to their callback, like Twitch does, and become enabled if the callback echoes it.
+//
+// Point a client at it with twitchwh.WithHelixURL(helix.HelixURL()) and twitchwh.WithAuthURL(helix.AuthURL()).
+// Any client ID and secret are accepted.
+type Helix struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	subscriptions []twitchwh.Subscription
+	secrets       map[string]string
+	nextID        int
+	verifying     sync.WaitGroup
+}
+
+// NewHelix starts a mock Helix server. Call Close when done.
+func NewHelix() *Helix {
+	h := &Helix{secrets: make(map[string]string)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth2/token", h.token)
+	mux.HandleFunc("GET /oauth2/validate", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /helix/eventsub/subscriptions", h.getSubscriptions)
+	mux.HandleFunc("POST /helix/eventsub/subscriptions", h.createSubscription)
+	mux.HandleFunc("DELETE /helix/eventsub/subscriptions", h.deleteSubscription)
+	h.Server = httptest.NewServer(mux)
+	return h
+}
+
+// HelixURL returns the URL to pass to twitchwh.WithHelixURL.
+func (h *Helix) HelixURL() string {
+	return h.URL + "/helix"
+}
+
+// AuthURL returns the URL to pass to twitchwh.WithAuthURL.
+func (h *Helix) AuthURL() string {
+	return h.URL + "/oauth2"
+}
+
+// Close waits for pending verification requests and shuts down the server.
+func (h *Helix) Close() {
+	h.verifying.Wait()
+	h.Server.Close()
+}
+
+// Subscriptions returns every subscription currently known to the server.
+func (h *Helix) Subscriptions() []twitchwh.Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]twitchwh.Subscription(nil), h.subscriptions...)
+}
+
+// Subscription returns the subscription with the given ID.
+func (h *Helix) Subscription(id string) (twitchwh.Subscription, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sub := range h.subscriptions {
+		if sub.ID == id {
+			return sub, true
+		}
+	}
+	return twitchwh.Subscription{}, false
+}
+
+// Secret returns the secret the subscription was created with.
+func (h *Helix) Secret(id string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.secrets[id]
+}
+
+func (h *Helix) token(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "mock-token",
+		"expires_in":   3600,
+		"token_type":   "bearer",
+	})
+}
+
+func (h *Helix) getSubscriptions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	data := []twitchwh.Subscription{}
+	for _, sub := range h.Subscriptions() {
+		if status := query.Get("status"); status != "" && sub.Status != status {
+			continue
+		}
+		if Type := query.Get("type"); Type != "" && sub.Type != Type {
+			continue
+		}
+		data = append(data, sub)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data":       data,
+		"total":      len(data),
+		"pagination": struct{}{},
+	})
+}
+
+func (h *Helix) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Type      string             `json:"type"`
+		Version   string             `json:"version"`
+		Condition twitchwh.Condition `json:"condition"`
+		Transport struct {
+			Method   string `json:"method"`
+			Callback string `json:"callback"`
+			Secret   string `json:"secret"`
+		} `json:"transport"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Bad Request", "message": err.Error()})
+		return
+	}
+
+	h.mu.Lock()
+	for _, sub := range h.subscriptions {
+		if sub.Type == request.Type && sub.Version == request.Version && sub.Condition.EqualFor(request.Type, request.Condition) && sub.Status != StatusVerificationFailed {
+			h.mu.Unlock()
+			writeJSON(w, http.StatusConflict, map[string]any{"error": "Conflict", "message": "subscription already exists"})
+			return
+		}
+	}
+	h.nextID++
+	sub := twitchwh.Subscription{
+		ID:        "mock-subscription-" + strconv.Itoa(h.nextID),
+		Status:    StatusVerificationPending,
+		Type:      request.Type,
+		Version:   request.Version,
+		Cost:      1,
+		Condition: request.Condition,
+		CreatedAt: time.Now().UTC(),
+	}
+	sub.Transport.Method = request.Transport.Method
+	sub.Transport.Callback = request.Transport.Callback
+	h.subscriptions = append(h.subscriptions, sub)
+	h.secrets[sub.ID] = request.Transport.Secret
+	h.verifying.Add(1)
+	h.mu.Unlock()
+
+	writeJSON(w, http.StatusAccepted, map[string]any{"data": []twitchwh.Subscription{sub}})
+	go func() {
+		defer h.verifying.Done()
+		h.verify(sub, request.Transport.Secret)
+	}()
+}
+
+// verify sends the verification challenge to the callback of sub and updates its status.
+func (h *Helix) verify(sub twitchwh.Subscription, secret string) {
+	challenge := newMessageID()
+	body, _ := json.Marshal(map[string]any{"challenge": challenge, "subscription": sub})
+	status := StatusVerificationFailed
+	res, err := send(sub.Transport.Callback, "webhook_callback_verification", secret, body)
+	if err == nil {
+		response, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode == http.StatusOK && string(response) == challenge {
+			status = StatusEnabled
+		}
+	}
+	h.setStatus(sub.ID, status)
+}
+
+func (h *Helix) setStatus(id string, status string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.subscriptions {
+		if h.subscriptions[i].ID == id {
+			h.subscriptions[i].Status = status
+		}
+	}
+}
+
+func (h *Helix) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, sub := range h.subscriptions {
+		if sub.ID == id {
+			h.subscriptions = append(h.subscriptions[:i], h.subscriptions[i+1:]...)
+			delete(h.secrets, id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]any{"error": "Not Found", "message": "subscription not found"})
+}
+
+// send delivers a signed EventSub message to callback.
+func send(callback string, messageType string, secret string, body []byte) (*http.Response, error) {
+	req, err := signedRequest(callback, messageType, secret, body)
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// signedRequest builds an EventSub request with a new message ID, signed with secret.
+func signedRequest(callback string, messageType string, secret string, body []byte) (*http.Request, error) {
+	messageID := newMessageID()
+	timestamp := time.Now().UTC().Format(time.RFC3339Nano)
+	req, err := http.NewRequest(http.MethodPost, callback, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderMessageID, messageID)
+	req.Header.Set(HeaderMessageTimestamp, timestamp)
+	req.Header.Set(HeaderMessageSignature, twitchwh.Signature(secret, messageID, timestamp, body))
+	req.Header.Set(HeaderMessageType, messageType)
+	return req, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
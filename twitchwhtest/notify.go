@@ -0,0 +1,69 @@
+package twitchwhtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// Notify builds a notification for sub with event, eg: a struct of the events package, signs it with the client's
+// webhook secret, and passes it to the client's Handler. Returns the status code of the response.
+//
+// Only sub.Type is required. An empty ID is generated, an empty Version is the latest known to twitchwh, and the
+// status, transport, and creation time are filled in as Twitch would.
+//
+//	status, err := twitchwhtest.Notify(client, twitchwh.Subscription{
+//		Type:      twitchwh.TypeStreamOnline,
+//		Condition: twitchwh.Condition{BroadcasterUserID: "1337"},
+//	}, events.StreamOnlineEvent{ID: "9001", BroadcasterUserID: "1337", Type: "live", StartedAt: time.Now()})
+//
+// Like notifications from Twitch, handlers run after the response unless their ResponsePolicy has
+// HandleBeforeResponse set.
+func Notify(client *twitchwh.Client, sub twitchwh.Subscription, event any) (int, error) {
+	sub, err := completeSubscription(sub, client.GetWebhookURL())
+	if err != nil {
+		return 0, err
+	}
+	body, err := json.Marshal(map[string]any{"subscription": sub, "event": event})
+	if err != nil {
+		return 0, err
+	}
+	req, err := signedRequest("/", "notification", client.GetWebhookSecret(), body)
+	if err != nil {
+		return 0, err
+	}
+	w := httptest.NewRecorder()
+	client.Handler(w, req)
+	return w.Code, nil
+}
+
+// completeSubscription fills in the defaults of sub described at Notify, with callback as the transport callback.
+func completeSubscription(sub twitchwh.Subscription, callback string) (twitchwh.Subscription, error) {
+	if sub.Type == "" {
+		return sub, errors.New("twitchwhtest: subscription type required")
+	}
+	if sub.ID == "" {
+		sub.ID = newMessageID()
+	}
+	if sub.Version == "" {
+		if versions := twitchwh.SubscriptionVersions(sub.Type); len(versions) > 0 {
+			sub.Version = versions[len(versions)-1]
+		} else {
+			sub.Version = "1"
+		}
+	}
+	if sub.Status == "" {
+		sub.Status = "enabled"
+	}
+	if sub.Transport.Method == "" {
+		sub.Transport.Method = "webhook"
+		sub.Transport.Callback = callback
+	}
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+	return sub, nil
+}
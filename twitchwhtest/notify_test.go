@@ -0,0 +1,45 @@
+package twitchwhtest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/macluxHD/twitchwh"
+	"github.com/macluxHD/twitchwh/events"
+)
+
+func TestNotify(t *testing.T) {
+	client, err := twitchwh.NewReceiver("0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan events.StreamOnlineEvent, 1)
+	twitchwh.OnEvent(client, twitchwh.TypeStreamOnline, func(event events.StreamOnlineEvent) {
+		received <- event
+	})
+
+	startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	status, err := Notify(client, twitchwh.Subscription{
+		Type:      twitchwh.TypeStreamOnline,
+		Condition: twitchwh.Condition{BroadcasterUserID: "1337"},
+	}, events.StreamOnlineEvent{ID: "9001", BroadcasterUserID: "1337", Type: "live", StartedAt: startedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", status)
+	}
+	select {
+	case event := <-received:
+		if event.ID != "9001" || !event.StartedAt.Equal(startedAt) {
+			t.Fatalf("Unexpected event %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handler was not called")
+	}
+
+	if _, err := Notify(client, twitchwh.Subscription{}, nil); err == nil {
+		t.Fatal("Expected error without a subscription type")
+	}
+}
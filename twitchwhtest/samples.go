@@ -0,0 +1,73 @@
+package twitchwhtest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+// samples holds the event payloads of the examples in the Twitch documentation, one file per subscription type and
+// version: samples/<type>/<version>.json.
+//
+//go:embed samples
+var samples embed.FS
+
+// Sample returns the sample event payload of the latest version of a subscription type that has one, as documented
+// by Twitch. Pass it to Notify, or decode it to test your handlers:
+//
+//	status, err := twitchwhtest.Notify(client, twitchwh.Subscription{Type: twitchwh.TypeChannelCheer},
+//		twitchwhtest.Sample(twitchwh.TypeChannelCheer))
+//
+// Panics if there is no sample for the type, see SampleTypes.
+func Sample(Type string) json.RawMessage {
+	versions := SampleVersions(Type)
+	if len(versions) == 0 {
+		panic(fmt.Sprintf("twitchwhtest: no sample for %s", Type))
+	}
+	return SampleVersion(Type, versions[len(versions)-1])
+}
+
+// SampleVersion returns the sample event payload of a version of a subscription type. Panics if there is no sample
+// for the version.
+func SampleVersion(Type string, version string) json.RawMessage {
+	data, err := samples.ReadFile(path.Join("samples", Type, version+".json"))
+	if err != nil {
+		panic(fmt.Sprintf("twitchwhtest: no sample for %s version %s", Type, version))
+	}
+	return data
+}
+
+// SampleTypes returns the subscription types that have a sample, sorted.
+func SampleTypes() []string {
+	entries, _ := samples.ReadDir("samples")
+	var types []string
+	for _, entry := range entries {
+		types = append(types, entry.Name())
+	}
+	sort.Strings(types)
+	return types
+}
+
+// SampleVersions returns the versions of a subscription type that have a sample, oldest first.
+func SampleVersions(Type string) []string {
+	entries, err := fs.ReadDir(samples, path.Join("samples", Type))
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, entry := range entries {
+		versions = append(versions, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	known := twitchwh.SubscriptionVersions(Type)
+	sort.Slice(versions, func(i, j int) bool {
+		return slices.Index(known, versions[i]) < slices.Index(known, versions[j])
+	})
+	return versions
+}
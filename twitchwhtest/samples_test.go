@@ -0,0 +1,61 @@
+package twitchwhtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/macluxHD/twitchwh"
+)
+
+func TestSamples(t *testing.T) {
+	types := SampleTypes()
+	if !slices.Contains(types, twitchwh.TypeChannelCheer) {
+		t.Fatalf("Expected a channel.cheer sample, got %v", types)
+	}
+	for _, Type := range types {
+		known := twitchwh.SubscriptionVersions(Type)
+		if len(known) == 0 {
+			t.Errorf("Sample of unknown type %s", Type)
+			continue
+		}
+		for _, version := range SampleVersions(Type) {
+			if !slices.Contains(known, version) {
+				t.Errorf("Sample of unknown version %s of %s", version, Type)
+			}
+			if !json.Valid(SampleVersion(Type, version)) {
+				t.Errorf("Invalid sample of %s version %s", Type, version)
+			}
+		}
+		if versions := SampleVersions(Type); versions[len(versions)-1] != known[len(known)-1] {
+			t.Errorf("Expected a sample of the latest version of %s, got %v", Type, versions)
+		}
+	}
+
+	if versions := SampleVersions(twitchwh.TypeChannelHypeTrainBegin); !slices.Equal(versions, []string{"1", "2"}) {
+		t.Fatalf("Expected versions oldest first, got %v", versions)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("Expected panic for a type without a sample")
+			}
+		}()
+		Sample("unknown.type")
+	}()
+}
+
+func TestNotifySample(t *testing.T) {
+	client, err := twitchwh.NewReceiver("0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := Notify(client, twitchwh.Subscription{Type: twitchwh.TypeChannelCheer}, Sample(twitchwh.TypeChannelCheer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", status)
+	}
+}
@@ -0,0 +1,34 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// OnEvent is like Client.On, but decodes the event into T first, eg: a struct of the events package. Events that
+// can't be decoded are passed to Client.OnDecodeError instead of the handler.
+//
+//	twitchwh.OnEvent(client, twitchwh.TypeStreamOnline, func(event events.StreamOnlineEvent) {
+//		log.Println(event.BroadcasterUserLogin, "went live")
+//	})
+func OnEvent[T any](c *Client, eventType string, handler func(T)) {
+	OnEventContext(c, eventType, func(_ context.Context, event T) error {
+		handler(event)
+		return nil
+	})
+}
+
+// OnEventContext is like Client.OnContext, but decodes the event into T first. Events that can't be decoded are
+// passed to Client.OnDecodeError instead of the handler, and reported as an EventDecodeError.
+func OnEventContext[T any](c *Client, eventType string, handler func(ctx context.Context, event T) error) {
+	c.OnContext(eventType, func(ctx context.Context, raw json.RawMessage) error {
+		var event T
+		if err := json.Unmarshal(raw, &event); err != nil {
+			if c.OnDecodeError != nil {
+				c.OnDecodeError(eventType, raw, err)
+			}
+			return &EventDecodeError{Type: eventType, Err: err}
+		}
+		return handler(ctx, event)
+	})
+}
@@ -0,0 +1,55 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestOnEvent(t *testing.T) {
+	c := newClient(ClientConfig{})
+	type chatMessage struct {
+		BroadcasterUserID string `json:"broadcaster_user_id"`
+	}
+	var received []chatMessage
+	OnEvent(c, "channel.chat.message", func(event chatMessage) {
+		received = append(received, event)
+	})
+	var decodeErrors []error
+	c.OnDecodeError = func(eventType string, event json.RawMessage, err error) {
+		decodeErrors = append(decodeErrors, err)
+	}
+
+	n := Notification{Subscription: Subscription{Type: "channel.chat.message"}, Event: json.RawMessage(`{"broadcaster_user_id":"1"}`)}
+	if err := c.processNotification(n); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[0].BroadcasterUserID != "1" {
+		t.Fatalf("Unexpected events %+v", received)
+	}
+
+	n.Event = json.RawMessage(`{"broadcaster_user_id":1}`)
+	var decodeErr *EventDecodeError
+	if err := c.processNotification(n); !errors.As(err, &decodeErr) || decodeErr.Type != "channel.chat.message" {
+		t.Fatalf("Expected EventDecodeError, got %v", err)
+	}
+	if len(received) != 1 || len(decodeErrors) != 1 {
+		t.Fatalf("Expected the handler to be skipped and OnDecodeError to be fired, got %d events, %d errors", len(received), len(decodeErrors))
+	}
+}
+
+func TestOnEventContext(t *testing.T) {
+	c := newClient(ClientConfig{})
+	failure := errors.New("failed")
+	OnEventContext(c, "stream.online", func(ctx context.Context, event map[string]string) error {
+		if SubscriptionIDFromContext(ctx) != "1" || event["id"] != "9" {
+			t.Errorf("Unexpected context or event %v", event)
+		}
+		return failure
+	})
+	n := Notification{Subscription: Subscription{ID: "1", Type: "stream.online"}, Event: json.RawMessage(`{"id":"9"}`)}
+	if err := c.processNotification(n); !errors.Is(err, failure) {
+		t.Fatalf("Expected the handler error, got %v", err)
+	}
+}
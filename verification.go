@@ -0,0 +1,140 @@
+package twitchwh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// How long AddSubscription waits for Twitch to verify a new subscription.
+const verificationTimeout = 10 * time.Second
+
+// Twitch challenges are short random strings and subscription IDs are UUIDs, anything much longer is not from Twitch.
+const (
+	maxChallengeLength      = 1024
+	maxSubscriptionIDLength = 64
+)
+
+// validateChallenge checks a verification payload before its challenge is echoed back.
+// The challenge must be non-empty printable ASCII so it can't smuggle anything into the response.
+func validateChallenge(payload webhookPayload) error {
+	if payload.Challenge == "" {
+		return errors.New("empty challenge")
+	}
+	if len(payload.Challenge) > maxChallengeLength {
+		return errors.New("challenge too long")
+	}
+	for i := 0; i < len(payload.Challenge); i++ {
+		if b := payload.Challenge[i]; b < 0x21 || b > 0x7e {
+			return errors.New("challenge contains invalid characters")
+		}
+	}
+	id := payload.Subscription.ID
+	if id == "" || len(id) > maxSubscriptionIDLength {
+		return errors.New("invalid subscription ID")
+	}
+	for i := 0; i < len(id); i++ {
+		b := id[i]
+		if !(b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '-' || b == '_') {
+			return errors.New("invalid subscription ID")
+		}
+	}
+	return nil
+}
+
+// verifications signals verified subscription IDs to AddSubscription calls waiting for them.
+// Twitch may verify a subscription before the Helix response creating it arrives, so verifications nobody is waiting
+// for yet are kept until verificationTimeout. Signaling never blocks, so stray challenges can't pile up goroutines.
+type verifications struct {
+	mu      sync.Mutex
+	waiters map[string]chan struct{}
+	early   map[string]time.Time
+	// Number of challenges answered, for diagnosing VerificationTimeoutErrors
+	answered atomic.Int64
+}
+
+// verified signals that a subscription was verified by this client.
+func (v *verifications) verified(id string) {
+	v.answered.Add(1)
+	v.signal(id)
+}
+
+// signal signals that a subscription was verified, by this client or another instance, see VerificationRelay.
+func (v *verifications) signal(id string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if waiter, ok := v.waiters[id]; ok {
+		close(waiter)
+		delete(v.waiters, id)
+		return
+	}
+	if v.early == nil {
+		v.early = make(map[string]time.Time)
+	}
+	now := time.Now()
+	for earlyID, at := range v.early {
+		if now.Sub(at) >= verificationTimeout {
+			delete(v.early, earlyID)
+		}
+	}
+	v.early[id] = now
+}
+
+// wait returns a channel that is closed once the subscription is verified. Call cancel when no longer waiting.
+func (v *verifications) wait(id string) <-chan struct{} {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	waiter := make(chan struct{})
+	if at, ok := v.early[id]; ok {
+		delete(v.early, id)
+		if time.Since(at) < verificationTimeout {
+			close(waiter)
+			return waiter
+		}
+	}
+	if v.waiters == nil {
+		v.waiters = make(map[string]chan struct{})
+	}
+	v.waiters[id] = waiter
+	return waiter
+}
+
+// cancel stops waiting for a subscription.
+func (v *verifications) cancel(id string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.waiters, id)
+}
+
+// verificationTimeoutError builds the error for a subscription that was not verified in time. answeredBefore is the
+// number of challenges answered when AddSubscription started waiting.
+func (c *Client) verificationTimeoutError(ctx context.Context, sub Subscription, answeredBefore int64) *VerificationTimeoutError {
+	answered := c.verifications.answered.Load()
+	err := &VerificationTimeoutError{
+		Subscription:           sub,
+		OtherChallengeReceived: answered > answeredBefore,
+		// In ModeAPI the receiver answers the challenges, this client never does
+		NoChallengesAnswered: answered == 0 && c.mode != ModeAPI,
+	}
+	subs, fetchErr := c.fetchSubscriptions(ctx, SubscriptionQuery{SubscriptionID: sub.ID})
+	if fetchErr != nil {
+		c.reportError("Could not fetch status of unverified subscription", fetchErr, "subscription_id", sub.ID)
+	}
+	for _, remote := range subs {
+		if remote.ID == sub.ID {
+			err.RemoteStatus = remote.Status
+		}
+	}
+	if c.deleteUnverified {
+		removeErr := c.removeSubscription(ctx, sub.ID)
+		var nfErr *SubscriptionNotFoundError
+		if removeErr != nil && !errors.As(removeErr, &nfErr) {
+			c.reportError("Could not delete unverified subscription", removeErr, "subscription_id", sub.ID)
+		} else {
+			err.Deleted = true
+		}
+	}
+	return err
+}
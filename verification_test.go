@@ -0,0 +1,148 @@
+package twitchwh
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifications(t *testing.T) {
+	var v verifications
+
+	// Verified before AddSubscription starts waiting
+	v.verified("a")
+	select {
+	case <-v.wait("a"):
+	default:
+		t.Fatal("Expected early verification to be kept")
+	}
+
+	waiter := v.wait("b")
+	v.verified("b")
+	select {
+	case <-waiter:
+	case <-time.After(time.Second):
+		t.Fatal("Expected waiter to be signaled")
+	}
+}
+
+func TestStrayChallengesDoNotLeakGoroutines(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	body := `{"challenge":"pogchamp-kappa-360noscope-vohiyo","subscription":{"id":"f1c2a387-161a-49f9-a165-0f21d7a4e1c4",` +
+		`"status":"webhook_callback_verification_pending","type":"channel.follow","version":"2"}}`
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		w := httptest.NewRecorder()
+		c.Handler(w, signedRequest("challenge", messageTypeVerification, body))
+		if w.Code != http.StatusOK || w.Body.String() != "pogchamp-kappa-360noscope-vohiyo" {
+			t.Fatalf("Unexpected response %d %q", w.Code, w.Body.String())
+		}
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("Expected no new goroutines, went from %d to %d", before, after)
+	}
+}
+
+func TestInvalidChallenges(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	var answered []string
+	c.OnChallenge = func(sub Subscription, challenge string) {
+		answered = append(answered, sub.ID)
+	}
+	for _, body := range []string{
+		`{"challenge":"","subscription":{"id":"f1c2a387-161a-49f9-a165-0f21d7a4e1c4"}}`,
+		`{"challenge":"abc\r\nX-Injected: 1","subscription":{"id":"f1c2a387-161a-49f9-a165-0f21d7a4e1c4"}}`,
+		`{"challenge":"` + strings.Repeat("a", maxChallengeLength+1) + `","subscription":{"id":"f1c2a387-161a-49f9-a165-0f21d7a4e1c4"}}`,
+		`{"challenge":"abc","subscription":{"id":""}}`,
+		`{"challenge":"abc","subscription":{"id":"../../admin"}}`,
+	} {
+		w := httptest.NewRecorder()
+		c.Handler(w, signedRequest("challenge", messageTypeVerification, body))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("challenge", messageTypeVerification, `{"challenge":"abc","subscription":{"id":"f1c2a387-161a-49f9-a165-0f21d7a4e1c4"}}`))
+	if w.Code != http.StatusOK || w.Body.String() != "abc" || w.Header().Get("Content-Type") != "text/plain" {
+		t.Fatalf("Unexpected response %d %q %q", w.Code, w.Body.String(), w.Header().Get("Content-Type"))
+	}
+	if len(answered) != 1 {
+		t.Fatalf("Expected OnChallenge to be called once, got %v", answered)
+	}
+}
+
+func TestPendingVerifications(t *testing.T) {
+	c := newClient(ClientConfig{})
+	c.pending.add(Subscription{ID: "a", Type: "stream.online"})
+	time.Sleep(time.Millisecond)
+	c.pending.add(Subscription{ID: "b", Type: "stream.offline"})
+
+	pending := c.PendingVerifications()
+	if len(pending) != 2 || pending[0].SubscriptionID != "a" || pending[1].Type != "stream.offline" {
+		t.Fatalf("Unexpected pending verifications %+v", pending)
+	}
+	if pending[0].Pending <= pending[1].Pending {
+		t.Errorf("Expected a to be pending longer than b")
+	}
+
+	c.pending.remove("a")
+	if pending := c.PendingVerifications(); len(pending) != 1 {
+		t.Fatalf("Expected 1 pending verification, got %+v", pending)
+	}
+}
+
+func TestVerificationTimeoutError(t *testing.T) {
+	c := newClient(ClientConfig{})
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Query().Get("subscription_id") != "a" {
+			t.Errorf("Expected subscription to be fetched by ID, got %s", r.URL.RawQuery)
+		}
+		return jsonResponse(200, `{"data":[{"id":"a","status":"webhook_callback_verification_failed"}],"pagination":{}}`), nil
+	})}
+
+	err := c.verificationTimeoutError(context.Background(), Subscription{ID: "a"}, 0)
+	if !err.NoChallengesAnswered || err.OtherChallengeReceived || err.RemoteStatus != "webhook_callback_verification_failed" {
+		t.Fatalf("Unexpected error %+v", err)
+	}
+
+	c.verifications.verified("b")
+	err = c.verificationTimeoutError(context.Background(), Subscription{ID: "a"}, 0)
+	if err.NoChallengesAnswered || !err.OtherChallengeReceived {
+		t.Fatalf("Unexpected error %+v", err)
+	}
+
+	// The receiver answers the challenges of a client in ModeAPI
+	c.mode = ModeAPI
+	c.verifications.answered.Store(0)
+	err = c.verificationTimeoutError(context.Background(), Subscription{ID: "a"}, 0)
+	if err.NoChallengesAnswered {
+		t.Fatalf("Expected no challenges answered not to be reported in API mode, got %+v", err)
+	}
+}
+
+func TestDeleteUnverifiedSubscriptions(t *testing.T) {
+	c := newClient(ClientConfig{DeleteUnverifiedSubscriptions: true})
+	var removed []string
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodDelete {
+			removed = append(removed, r.URL.Query().Get("id"))
+			return jsonResponse(204, ""), nil
+		}
+		return jsonResponse(200, `{"data":[{"id":"a","status":"webhook_callback_verification_pending"}],"pagination":{}}`), nil
+	})}
+
+	err := c.verificationTimeoutError(context.Background(), Subscription{ID: "a"}, 0)
+	if !err.Deleted || err.RemoteStatus != "webhook_callback_verification_pending" {
+		t.Fatalf("Unexpected error %+v", err)
+	}
+	if len(removed) != 1 || removed[0] != "a" {
+		t.Fatalf("Expected subscription a to be removed, got %v", removed)
+	}
+}
@@ -4,9 +4,15 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"hash"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const signaturePrefix = "sha256="
+
 func generateHmac(secret, message string) string {
 	hash := hmac.New(sha256.New, []byte(secret))
 	hash.Write([]byte(message))
@@ -14,13 +20,112 @@ func generateHmac(secret, message string) string {
 	return hex.EncodeToString(signature)
 }
 
+// Signature returns the value of the Twitch-Eventsub-Message-Signature header for a message, signed with secret.
+// Useful for forwarding notifications to other EventSub consumers, or for sending test events to Client.Handler.
+func Signature(secret, messageID, timestamp string, body []byte) string {
+	return signaturePrefix + generateHmac(secret, messageID+timestamp+string(body))
+}
+
 func verifyHmac(hmac1, hmac2 string) bool {
 	return hmac.Equal([]byte(hmac1), []byte(hmac2))
 }
 
-func isMessageTooOld(timestamp string) bool {
-	t, err := time.Parse(time.RFC3339, timestamp)
-	if err != nil {
+// hmacPool reuses HMAC hashes for a webhook secret, so the key isn't hashed again for every request.
+type hmacPool struct {
+	secret string
+	pool   sync.Pool
+}
+
+func newHmacPool(secret string) *hmacPool {
+	p := &hmacPool{secret: secret}
+	p.pool.New = func() any {
+		return hmac.New(sha256.New, []byte(secret))
+	}
+	return p
+}
+
+// signatureHashes are the HMAC hashes a request is verified with: one for the webhook secret and, during a
+// RotateWebhookSecret window, one for the previous secret.
+type signatureHashes struct {
+	macs  [2]hash.Hash
+	pools [2]*hmacPool
+	n     int
+}
+
+// Pooled as well, it escapes to the heap when used as an io.Writer
+var signatureHashesPool = sync.Pool{
+	New: func() any {
+		return new(signatureHashes)
+	},
+}
+
+func (c *Client) signatureHashes() *signatureHashes {
+	h := signatureHashesPool.Get().(*signatureHashes)
+	h.add(&c.hmacs, c.GetWebhookSecret())
+	if previous, ok := c.previousSecret(); ok {
+		h.add(&c.previousHmacs, previous)
+	}
+	return h
+}
+
+// add takes a reset HMAC hash for secret from the pool in p, replacing the pool if the secret changed.
+func (h *signatureHashes) add(p *atomic.Pointer[hmacPool], secret string) {
+	pool := p.Load()
+	if pool == nil || pool.secret != secret {
+		pool = newHmacPool(secret)
+		p.Store(pool)
+	}
+	mac := pool.pool.Get().(hash.Hash)
+	mac.Reset()
+	h.macs[h.n] = mac
+	h.pools[h.n] = pool
+	h.n++
+}
+
+func (h *signatureHashes) Write(p []byte) (int, error) {
+	for _, mac := range h.macs[:h.n] {
+		mac.Write(p)
+	}
+	return len(p), nil
+}
+
+// check reports whether signature matches any of the hashes.
+func (h *signatureHashes) check(signature string) bool {
+	for _, mac := range h.macs[:h.n] {
+		if checkSignature(mac, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// release returns the hashes to their pools.
+func (h *signatureHashes) release() {
+	for i := range h.n {
+		h.pools[i].pool.Put(h.macs[i])
+	}
+	*h = signatureHashes{}
+	signatureHashesPool.Put(h)
+}
+
+// checkSignature reports whether signature matches the message written to mac.
+// It decodes the signature instead of encoding the computed one, so checking doesn't allocate.
+func checkSignature(mac hash.Hash, signature string) bool {
+	hexSignature, ok := strings.CutPrefix(signature, signaturePrefix)
+	if !ok || hex.DecodedLen(len(hexSignature)) != sha256.Size {
+		return false
+	}
+	var expected [sha256.Size]byte
+	if _, err := hex.Decode(expected[:], []byte(hexSignature)); err != nil {
+		return false
+	}
+	var actual [sha256.Size]byte
+	return hmac.Equal(mac.Sum(actual[:0]), expected[:])
+}
+
+// isTimestampTooOld reports whether a message with the given timestamp should be ignored. The zero time is never too old.
+func isTimestampTooOld(t time.Time) bool {
+	if t.IsZero() {
 		return false
 	}
 	return time.Since(t) > 10*time.Minute
@@ -1,7 +1,10 @@
 package twitchwh
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGenerateHmac(t *testing.T) {
@@ -13,3 +16,28 @@ func TestGenerateHmac(t *testing.T) {
 		t.Fatal("HMAC verification failed")
 	}
 }
+
+func TestSignature(t *testing.T) {
+	expected := "sha256=" + generateHmac("supersecretstring", "idtimestamp{}")
+	if signature := Signature("supersecretstring", "id", "timestamp", []byte("{}")); signature != expected {
+		t.Fatalf("Expected %s, got %s", expected, signature)
+	}
+}
+
+func TestRotateWebhookSecret(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	c.RotateWebhookSecret("new-secret-0123", time.Minute)
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected the previous secret to be accepted, got %d", w.Code)
+	}
+
+	c.SetWebhookSecret("new-secret-0123")
+	w = httptest.NewRecorder()
+	c.Handler(w, signedRequest("b", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected the previous secret to be rejected, got %d", w.Code)
+	}
+}
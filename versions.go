@@ -0,0 +1,147 @@
+package twitchwh
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// MapEventVersion registers a transform for notifications of a subscription type and version, run before they are
+// passed to handlers and sinks. Use it to keep handlers written for an older version working after a
+// VersionMigrator moved their subscriptions to a newer one:
+//
+//	client.MapEventVersion("channel.moderate", "2", func(n twitchwh.Notification) (twitchwh.Notification, error) {
+//		n.Event = dropWarnings(n.Event) // v2 added warn actions the v1 handler doesn't know
+//		n.Subscription.Version = "1"
+//		return n, nil
+//	})
+//
+// A notification the transform returns an error for is answered with 500, so Twitch redelivers it. Passing a nil
+// transform removes the mapping.
+func (c *Client) MapEventVersion(Type string, version string, transform Transform) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	key := [2]string{Type, version}
+	if transform == nil {
+		delete(c.eventMappers, key)
+		return
+	}
+	if c.eventMappers == nil {
+		c.eventMappers = make(map[[2]string]Transform)
+	}
+	c.eventMappers[key] = transform
+}
+
+// mapEventVersion applies the transform registered with MapEventVersion for the type and version of n, if any.
+func (c *Client) mapEventVersion(n Notification) (Notification, error) {
+	c.handlersMu.RLock()
+	transform, ok := c.eventMappers[[2]string{n.Subscription.Type, n.Subscription.Version}]
+	c.handlersMu.RUnlock()
+	if !ok {
+		return n, nil
+	}
+	return transform(n)
+}
+
+// VersionMigrator recreates subscriptions whose version Twitch no longer supports with the latest version known to
+// the catalog, see SubscriptionVersions. Beta types in particular lose versions as they evolve, and Twitch revokes
+// their subscriptions when it removes one. Register a transform with Client.MapEventVersion if the payload of the
+// new version differs from what the handlers expect.
+//
+//	migrator := &twitchwh.VersionMigrator{Client: client}
+//	go migrator.Run(ctx, time.Hour)
+//
+// Subscriptions of types unknown to the catalog are left alone.
+type VersionMigrator struct {
+	Client *Client
+	// Selects the subscriptions to migrate. Defaults to the subscriptions owned by the client, see
+	// Client.OwnsSubscription.
+	Match func(Subscription) bool
+	// Also migrate subscriptions whose version is supported but not the latest one.
+	Latest bool
+}
+
+// Outdated returns the enabled subscriptions that would be migrated, and the version each would be migrated to.
+func (m *VersionMigrator) Outdated(ctx context.Context) (subs []Subscription, versions []string, err error) {
+	all, err := m.Client.getSubscriptionsByStatus(withPriority(ctx, PriorityBackground), "enabled")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, sub := range all {
+		known := subscriptionVersions[sub.Type]
+		if len(known) == 0 {
+			continue
+		}
+		latest := known[len(known)-1]
+		if sub.Version == latest || (!m.Latest && slices.Contains(known, sub.Version)) {
+			continue
+		}
+		if !m.match(sub) {
+			continue
+		}
+		subs = append(subs, sub)
+		versions = append(versions, latest)
+	}
+	return subs, versions, nil
+}
+
+func (m *VersionMigrator) match(sub Subscription) bool {
+	if m.Match != nil {
+		return m.Match(sub)
+	}
+	owned, err := m.Client.OwnsSubscription(sub)
+	if err != nil {
+		m.Client.reportError("Could not check subscription owner", err, "subscription_id", sub.ID)
+	}
+	return owned
+}
+
+// Migrate recreates the outdated subscriptions with the latest version. Twitch doesn't allow two subscriptions with
+// the same type and condition, so each subscription is removed before it is recreated. Returns the IDs of the new
+// subscriptions, and stops at the first error. Returns NotLeaderError on replicas that are not the leader.
+// Its Helix requests have PriorityBackground.
+func (m *VersionMigrator) Migrate(ctx context.Context) (ids []string, err error) {
+	if err := m.Client.checkLeader(); err != nil {
+		return nil, err
+	}
+	subs, versions, err := m.Outdated(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withPriority(ctx, PriorityBackground)
+	for i, sub := range subs {
+		m.Client.logger.Info("Migrating subscription to a newer version", "subscription_id", sub.ID, "type", sub.Type,
+			"version", sub.Version, "new_version", versions[i])
+		if err := m.Client.removeSubscription(ctx, sub.ID); err != nil {
+			var nfErr *SubscriptionNotFoundError
+			if !errors.As(err, &nfErr) {
+				return ids, err
+			}
+		}
+		id, err := m.Client.createSubscription(ctx, sub.Type, versions[i], sub.Condition)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Run calls Migrate every interval until ctx is done. Errors are reported to the ErrorHandler, except NotLeaderError.
+func (m *VersionMigrator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_, err := m.Migrate(ctx)
+		var notLeader *NotLeaderError
+		if err != nil && !errors.As(err, &notLeader) {
+			m.Client.reportError("Could not migrate subscription versions", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
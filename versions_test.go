@@ -0,0 +1,113 @@
+package twitchwh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVersionMigrator(t *testing.T) {
+	c := newClient(ClientConfig{WebhookURL: "https://example.com/eventsub"})
+	var deleted []string
+	var created []SubscriptionRequest
+	c.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		switch r.Method {
+		case http.MethodDelete:
+			deleted = append(deleted, r.URL.Query().Get("id"))
+			return jsonResponse(204, ""), nil
+		case http.MethodPost:
+			var req SubscriptionRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			created = append(created, req)
+			c.verifications.verified("new-" + req.Type)
+			return jsonResponse(202, `{"data":[{"id":"new-`+req.Type+`","status":"webhook_callback_verification_pending"}]}`), nil
+		}
+		return jsonResponse(200, `{"data":[`+
+			`{"id":"a","type":"channel.follow","version":"1","status":"enabled","condition":{"broadcaster_user_id":"1"}},`+
+			`{"id":"b","type":"channel.moderate","version":"1","status":"enabled","condition":{"broadcaster_user_id":"1"}},`+
+			`{"id":"c","type":"stream.online","version":"1","status":"enabled","condition":{"broadcaster_user_id":"1"}},`+
+			`{"id":"d","type":"unknown.type","version":"1","status":"enabled","condition":{"broadcaster_user_id":"1"}}`+
+			`],"pagination":{}}`), nil
+	})}
+
+	migrator := &VersionMigrator{Client: c, Match: func(Subscription) bool { return true }}
+	subs, versions, err := migrator.Outdated(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].ID != "a" || versions[0] != "2" {
+		t.Fatalf("Expected only the removed channel.follow version to be outdated, got %+v %v", subs, versions)
+	}
+
+	migrator.Latest = true
+	ids, err := migrator.Migrate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || len(deleted) != 2 || deleted[0] != "a" || deleted[1] != "b" {
+		t.Fatalf("Expected channel.follow and channel.moderate to be migrated, got %v, deleted %v", ids, deleted)
+	}
+	if created[0].Version != "2" || created[1].Version != "2" || created[1].Condition.BroadcasterUserID != "1" {
+		t.Fatalf("Unexpected created subscriptions %+v", created)
+	}
+
+	migrator.Match = nil
+	deleted = nil
+	if _, err := migrator.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("Expected subscriptions not owned by the client to be left alone, deleted %v", deleted)
+	}
+}
+
+func TestMapEventVersion(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	received := make(chan Notification, 1)
+	c.AddSink(funcSink(func(ctx context.Context, n Notification) error {
+		received <- n
+		return nil
+	}))
+	c.MapEventVersion("channel.chat.message", "1", func(n Notification) (Notification, error) {
+		n.Event = json.RawMessage(`{"mapped":true}`)
+		return n, nil
+	})
+
+	w := httptest.NewRecorder()
+	c.Handler(w, signedRequest("a", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", w.Code)
+	}
+	if n := <-received; string(n.Event) != `{"mapped":true}` {
+		t.Fatalf("Expected mapped event, got %s", n.Event)
+	}
+
+	c.MapEventVersion("channel.chat.message", "1", func(n Notification) (Notification, error) {
+		return n, errors.New("unknown payload")
+	})
+	w = httptest.NewRecorder()
+	c.Handler(w, signedRequest("b", messageTypeNotification, chatMessageBody))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500 for a failed mapping, got %d", w.Code)
+	}
+}
+
+func TestMapEventVersionHandlersOnly(t *testing.T) {
+	c := newClient(ClientConfig{WebhookSecret: testWebhookSecret})
+	received := make(chan json.RawMessage, 1)
+	c.On("channel.chat.message", func(event json.RawMessage) {
+		received <- event
+	})
+	c.MapEventVersion("channel.chat.message", "1", func(n Notification) (Notification, error) {
+		n.Event = json.RawMessage(`{"mapped":true}`)
+		return n, nil
+	})
+
+	c.Handler(httptest.NewRecorder(), signedRequest("a", messageTypeNotification, chatMessageBody))
+	if event := <-received; string(event) != `{"mapped":true}` {
+		t.Fatalf("Expected mapped event without sinks, got %s", event)
+	}
+}
@@ -0,0 +1,139 @@
+package twitchwh
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// How often the watchdog checks for silent subscriptions.
+const watchdogInterval = 1 * time.Second
+
+type watchEntry struct {
+	// Last known subscription, only the ID (or Type for type watches) is set until a notification is received.
+	sub       Subscription
+	threshold time.Duration
+	lastSeen  time.Time
+	lastFired time.Time
+}
+
+// watchdog tracks the time since the last notification of watched subscriptions and types.
+type watchdog struct {
+	mu      sync.Mutex
+	byID    map[string]*watchEntry
+	byType  map[string]*watchEntry
+	started bool
+}
+
+// seen records a notification for the subscription.
+func (w *watchdog) seen(sub Subscription) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	if entry, ok := w.byID[sub.ID]; ok {
+		entry.sub = sub
+		entry.lastSeen = now
+	}
+	if entry, ok := w.byType[sub.Type]; ok {
+		entry.sub = sub
+		entry.lastSeen = now
+	}
+}
+
+// watching reports whether any subscription or type is watched.
+func (w *watchdog) watching() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.byID) > 0 || len(w.byType) > 0
+}
+
+// silent returns the entries that exceeded their threshold and marks them as fired.
+func (w *watchdog) silent() []watchEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	var silent []watchEntry
+	for _, entries := range []map[string]*watchEntry{w.byID, w.byType} {
+		for _, entry := range entries {
+			if now.Sub(entry.lastSeen) >= entry.threshold && now.Sub(entry.lastFired) >= entry.threshold {
+				entry.lastFired = now
+				silent = append(silent, *entry)
+			}
+		}
+	}
+	return silent
+}
+
+// Watch marks a subscription as expected to be active.
+// If no notification is received for it within threshold, OnSilence is fired,
+// and then again every threshold until a notification arrives or Unwatch is called.
+//
+// The subscription is unwatched automatically when Twitch revokes it.
+func (c *Client) Watch(subscriptionID string, threshold time.Duration) {
+	c.watch(func(w *watchdog) {
+		w.byID[subscriptionID] = &watchEntry{
+			sub:       Subscription{ID: subscriptionID},
+			threshold: threshold,
+			lastSeen:  time.Now(),
+		}
+	})
+}
+
+// WatchType is like Watch, but tracks notifications of any subscription with the given type (eg: "channel.chat.message").
+func (c *Client) WatchType(eventType string, threshold time.Duration) {
+	c.watch(func(w *watchdog) {
+		w.byType[eventType] = &watchEntry{
+			sub:       Subscription{Type: eventType},
+			threshold: threshold,
+			lastSeen:  time.Now(),
+		}
+	})
+}
+
+// Unwatch stops watching a subscription.
+func (c *Client) Unwatch(subscriptionID string) {
+	c.watchdog.mu.Lock()
+	defer c.watchdog.mu.Unlock()
+	delete(c.watchdog.byID, subscriptionID)
+}
+
+// UnwatchType stops watching an event type.
+func (c *Client) UnwatchType(eventType string) {
+	c.watchdog.mu.Lock()
+	defer c.watchdog.mu.Unlock()
+	delete(c.watchdog.byType, eventType)
+}
+
+// watch applies fn to the watchdog and starts it if it isn't running yet.
+func (c *Client) watch(fn func(w *watchdog)) {
+	c.watchdog.mu.Lock()
+	defer c.watchdog.mu.Unlock()
+	if c.watchdog.byID == nil {
+		c.watchdog.byID = make(map[string]*watchEntry)
+		c.watchdog.byType = make(map[string]*watchEntry)
+	}
+	fn(&c.watchdog)
+	if !c.watchdog.started {
+		c.watchdog.started = true
+		c.goBackground(c.runWatchdog)
+	}
+}
+
+func (c *Client) runWatchdog(ctx context.Context) {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, entry := range c.watchdog.silent() {
+			since := time.Since(entry.lastSeen)
+			c.logger.Warn("No notifications received", "subscription_id", entry.sub.ID, "type", entry.sub.Type, "since", since)
+			if c.OnSilence != nil {
+				c.OnSilence(entry.sub, since)
+			}
+		}
+	}
+}
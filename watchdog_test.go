@@ -0,0 +1,25 @@
+package twitchwh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchdogSilent(t *testing.T) {
+	w := watchdog{
+		byID: map[string]*watchEntry{
+			"quiet":  {sub: Subscription{ID: "quiet"}, threshold: time.Minute, lastSeen: time.Now().Add(-2 * time.Minute)},
+			"active": {sub: Subscription{ID: "active"}, threshold: time.Minute, lastSeen: time.Now().Add(-2 * time.Minute)},
+		},
+		byType: map[string]*watchEntry{},
+	}
+	w.seen(Subscription{ID: "active", Type: "stream.online"})
+
+	silent := w.silent()
+	if len(silent) != 1 || silent[0].sub.ID != "quiet" {
+		t.Fatalf("Expected only quiet to be silent, got %+v", silent)
+	}
+	if len(w.silent()) != 0 {
+		t.Fatal("Silent subscription fired twice within threshold")
+	}
+}
@@ -0,0 +1,105 @@
+package twitchwh
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// DispatchMode controls how notifications are handed to handlers, see ClientConfig.DispatchMode.
+type DispatchMode int
+
+const (
+	// Every notification is handled in a new goroutine. Handlers of the same type run concurrently.
+	DispatchGoroutine DispatchMode = iota
+	// Notifications are queued per event type and handled by one long-lived worker per type, in the order they
+	// were received. This avoids goroutine churn under high volume, and lets handlers buffer events of their type
+	// without locking, eg: to batch database writes.
+	DispatchWorkers
+)
+
+const defaultDispatchQueueSize = 1000
+
+// typeQueues are the per-type queues used by DispatchWorkers.
+type typeQueues struct {
+	mu     sync.Mutex
+	queues map[string]chan Notification
+	closed bool
+}
+
+// enqueue adds a notification to the queue of its event type, starting the worker on first use.
+// Returns false without blocking if the queue is full or was closed by Shutdown.
+func (c *Client) enqueue(n Notification) bool {
+	c.typeQueues.mu.Lock()
+	defer c.typeQueues.mu.Unlock()
+	if c.typeQueues.closed {
+		return false
+	}
+	if c.typeQueues.queues == nil {
+		c.typeQueues.queues = make(map[string]chan Notification)
+	}
+	queue, ok := c.typeQueues.queues[n.Subscription.Type]
+	if !ok {
+		queue = make(chan Notification, c.dispatchQueueSize)
+		c.typeQueues.queues[n.Subscription.Type] = queue
+		c.goBackground(func(ctx context.Context) {
+			c.runTypeWorker(ctx, queue)
+		})
+	}
+	select {
+	case queue <- n:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeTypeQueues closes the queues, so their workers return once they handled the remaining notifications.
+func (c *Client) closeTypeQueues() {
+	c.typeQueues.mu.Lock()
+	defer c.typeQueues.mu.Unlock()
+	if c.typeQueues.closed {
+		return
+	}
+	c.typeQueues.closed = true
+	for _, queue := range c.typeQueues.queues {
+		close(queue)
+	}
+}
+
+// queueFull reports whether the queue of an event type is full. Always false for DispatchGoroutine.
+func (c *Client) queueFull(eventType string) bool {
+	if c.dispatchMode != DispatchWorkers {
+		return false
+	}
+	c.typeQueues.mu.Lock()
+	queue, ok := c.typeQueues.queues[eventType]
+	c.typeQueues.mu.Unlock()
+	return ok && len(queue) >= cap(queue)
+}
+
+// runTypeWorker handles the notifications of a single event type, one at a time, until the queue is closed or ctx is
+// done.
+func (c *Client) runTypeWorker(ctx context.Context, queue chan Notification) {
+	for {
+		var n Notification
+		select {
+		case <-ctx.Done():
+			return
+		case queued, ok := <-queue:
+			if !ok {
+				return
+			}
+			n = queued
+		}
+		// Looked up again, the handler may have been replaced since the notification was queued
+		handler, ok := c.notificationHandler(n)
+		if !ok {
+			c.sampledLogger.log("no-handler:"+n.Subscription.Type, slog.LevelDebug, "No handler for event", "type", n.Subscription.Type)
+			continue
+		}
+		c.runningHandlers.Add(1)
+		c.dispatch(n, handler)
+		c.runningHandlers.Add(-1)
+	}
+}